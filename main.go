@@ -22,9 +22,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/deweb-services/gateway-st/internal/wizard"
+	"github.com/deweb-services/gateway-st/miniogw"
 	"storj.io/common/base58"
 	"storj.io/common/fpath"
-	"storj.io/gateway/miniogw"
 	minio "storj.io/minio/cmd"
 	"storj.io/private/cfgstruct"
 	"storj.io/private/process"
@@ -45,7 +45,8 @@ type GatewayFlags struct {
 
 	Config
 
-	Website bool `help:"serve content as a static website" default:"false" basic-help:"true"`
+	PublicAccess miniogw.PublicAccessConfig
+	Website      miniogw.WebsiteConfig
 }
 
 var (
@@ -201,11 +202,17 @@ func checkCfg(ctx context.Context) (err error) {
 
 // Run starts a Minio Gateway given proper config.
 func (flags GatewayFlags) Run(ctx context.Context) (err error) {
+	// minio listens at an internal address behind the server.
+	server, err := miniogw.NewServer(zap.L(), flags.Server, filepath.Join(flags.Minio.Dir, "certs"))
+	if err != nil {
+		return err
+	}
+
 	err = minio.RegisterGatewayCommand(cli.Command{
 		Name:  "storj",
 		Usage: "Storj",
 		Action: func(cliCtx *cli.Context) error {
-			return flags.action(ctx, cliCtx)
+			return flags.action(ctx, cliCtx, server)
 		},
 		HideHelpCommand: true,
 	})
@@ -224,12 +231,12 @@ func (flags GatewayFlags) Run(ctx context.Context) (err error) {
 	}
 
 	minio.Main([]string{"nodeshift", "gateway", "nodeshift",
-		"--address", flags.Server.Address, "--config-dir", flags.Minio.Dir, "--quiet",
+		"--address", server.MinioAddress(), "--config-dir", flags.Minio.Dir, "--quiet",
 		"--compat"})
 	return errs.New("unexpected nodeshift exit")
 }
 
-func (flags GatewayFlags) action(ctx context.Context, cliCtx *cli.Context) (err error) {
+func (flags GatewayFlags) action(ctx context.Context, cliCtx *cli.Context, server *miniogw.Server) (err error) {
 	access, err := flags.GetAccess()
 	if err != nil {
 		return Error.Wrap(err)
@@ -242,14 +249,26 @@ func (flags GatewayFlags) action(ctx context.Context, cliCtx *cli.Context) (err
 		return err
 	}
 
-	minio.StartGateway(cliCtx, miniogw.NewSingleTenantGateway(zap.L(), access, config, gw, flags.Website))
+	gateway := miniogw.NewSingleTenantGateway(zap.L(), access, config, gw, flags.PublicAccess, flags.Website)
+
+	miniogw.RegisterHandlers(gateway)
+	go func() {
+		if err := server.Serve(gateway); err != nil {
+			zap.S().Fatal("Failed to serve requests: ", err)
+		}
+	}()
+
+	if err := server.ReleaseMinioAddress(); err != nil {
+		return err
+	}
+	minio.StartGateway(cliCtx, gateway)
 
 	return errs.New("unexpected minio exit")
 }
 
 // NewGateway creates a new minio Gateway.
 func (flags GatewayFlags) NewGateway(ctx context.Context) (gw minio.Gateway, err error) {
-	return miniogw.NewStorjGateway(flags.S3), nil
+	return miniogw.NewStorjGateway(flags.S3).WithLogger(zap.S()), nil
 }
 
 func (flags *GatewayFlags) newUplinkConfig(ctx context.Context) uplink.Config {
@@ -0,0 +1,352 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	minio "storj.io/minio/cmd"
+	objectlock "storj.io/minio/pkg/bucket/object/lock"
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+// maxConfigurationSize is the maximum size of configurations sent to the
+// APIs served by APIHandler.
+const maxConfigurationSize = 1 << 20
+
+// APIServer is implemented by gateways that serve parts of the S3 API minio
+// rejects.
+type APIServer interface {
+	APIHandler(next http.Handler) http.Handler
+}
+
+var _ APIServer = (*singleTenantGateway)(nil)
+
+// s3API is a part of the S3 API served by APIHandler.
+type s3API struct {
+	name   string
+	method string
+	// query is the query parameter selecting the API.
+	query string
+	// object is whether the API is for objects rather than buckets.
+	object bool
+	// action is the policy action requests need to be allowed.
+	action policy.Action
+	serve  func(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error
+}
+
+// s3APIs are the APIs served by APIHandler.
+var s3APIs = []s3API{
+	{name: "GetBucketLifecycle", method: http.MethodGet, query: "lifecycle", action: policy.GetBucketLifecycleAction, serve: serveGetBucketLifecycle},
+	{name: "PutBucketLifecycle", method: http.MethodPut, query: "lifecycle", action: policy.PutBucketLifecycleAction, serve: servePutBucketLifecycle},
+	{name: "DeleteBucketLifecycle", method: http.MethodDelete, query: "lifecycle", action: policy.PutBucketLifecycleAction, serve: serveDeleteBucketLifecycle},
+	// There are no CORS or website actions, so the bucket policy ones are
+	// used like minio does for its dummy GetBucketCors and GetBucketWebsite.
+	{name: "GetBucketCors", method: http.MethodGet, query: "cors", action: policy.GetBucketPolicyAction, serve: serveGetBucketCORS},
+	{name: "PutBucketCors", method: http.MethodPut, query: "cors", action: policy.PutBucketPolicyAction, serve: servePutBucketCORS},
+	{name: "DeleteBucketCors", method: http.MethodDelete, query: "cors", action: policy.PutBucketPolicyAction, serve: serveDeleteBucketCORS},
+	{name: "GetBucketWebsite", method: http.MethodGet, query: "website", action: policy.GetBucketPolicyAction, serve: serveGetBucketWebsite},
+	{name: "PutBucketWebsite", method: http.MethodPut, query: "website", action: policy.PutBucketPolicyAction, serve: servePutBucketWebsite},
+	{name: "DeleteBucketWebsite", method: http.MethodDelete, query: "website", action: policy.PutBucketPolicyAction, serve: serveDeleteBucketWebsite},
+	// An empty configuration removes notifications, as there's no delete
+	// API.
+	{name: "GetBucketNotification", method: http.MethodGet, query: "notification", action: policy.GetBucketNotificationAction, serve: serveGetBucketNotification},
+	{name: "PutBucketNotification", method: http.MethodPut, query: "notification", action: policy.PutBucketNotificationAction, serve: servePutBucketNotification},
+	{name: "GetObjectLockConfiguration", method: http.MethodGet, query: "object-lock", action: policy.GetBucketObjectLockConfigurationAction, serve: serveGetObjectLockConfig},
+	{name: "PutObjectLockConfiguration", method: http.MethodPut, query: "object-lock", action: policy.PutBucketObjectLockConfigurationAction, serve: servePutObjectLockConfig},
+	{name: "GetObjectRetention", method: http.MethodGet, query: "retention", object: true, action: policy.GetObjectRetentionAction, serve: serveGetObjectRetention},
+	{name: "PutObjectRetention", method: http.MethodPut, query: "retention", object: true, action: policy.PutObjectRetentionAction, serve: servePutObjectRetention},
+	{name: "GetObjectLegalHold", method: http.MethodGet, query: "legal-hold", object: true, action: policy.GetObjectLegalHoldAction, serve: serveGetObjectLegalHold},
+	{name: "PutObjectLegalHold", method: http.MethodPut, query: "legal-hold", object: true, action: policy.PutObjectLegalHoldAction, serve: servePutObjectLegalHold},
+	{name: "GetObjectAttributes", method: http.MethodGet, query: "attributes", object: true, action: policy.GetObjectAction, serve: serveGetObjectAttributes},
+}
+
+// findS3API returns the API r is made to if it's served by APIHandler.
+func findS3API(r *http.Request, object string) (s3API, bool) {
+	query := r.URL.Query()
+	for _, api := range s3APIs {
+		if _, ok := query[api.query]; ok && api.method == r.Method && api.object == (object != "") {
+			return api, true
+		}
+	}
+	return s3API{}, false
+}
+
+// authorizeRequest returns whether r is allowed to perform action on bucket
+// and object, like minio checks its own APIs.
+func authorizeRequest(ctx context.Context, r *http.Request, action policy.Action, bucket, object string) minio.APIErrorCode {
+	_, _, code := minio.CheckRequestAuthTypeCredential(ctx, r, action, bucket, object)
+	return code
+}
+
+// APIHandler is a middleware that serves the parts of the S3 API minio
+// rejects using the optional interfaces of the object layer. Other requests
+// are passed on.
+func (g *singleTenantGateway) APIHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		bucket := vars["bucket"]
+		object, err := url.PathUnescape(vars["object"])
+		if bucket == "" || err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		api, ok := findS3API(r, object)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := minio.NewContext(r, w, api.name)
+
+		layer := g.layer.Load()
+		if layer == nil {
+			minio.WriteErrorResponse(ctx, w, minio.GetAPIError(minio.ErrServerNotInitialized), r.URL, false)
+			return
+		}
+
+		if code := g.authorize(ctx, r, api.action, bucket, object); code != minio.ErrNone {
+			minio.WriteErrorResponse(ctx, w, minio.GetAPIError(code), r.URL, false)
+			return
+		}
+
+		if err := api.serve(ctx, layer, w, r, bucket, object); err != nil {
+			minio.WriteErrorResponse(ctx, w, minio.ToAPIError(ctx, err), r.URL, false)
+		}
+	})
+}
+
+// configurationBody returns the body of r, which sends a configuration.
+func configurationBody(r *http.Request) io.Reader {
+	return io.LimitReader(r.Body, maxConfigurationSize)
+}
+
+// writeXMLResponse writes response as the XML body of a successful response.
+func writeXMLResponse(w http.ResponseWriter, response interface{}) {
+	minio.WriteSuccessResponseXML(w, minio.EncodeResponse(response))
+}
+
+func serveGetBucketLifecycle(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := layer.GetBucketLifecycle(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, config)
+	return nil
+}
+
+func servePutBucketLifecycle(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := ParseBucketLifecycle(configurationBody(r))
+	if err != nil {
+		return err
+	}
+	if err := layer.SetBucketLifecycle(ctx, bucket, config); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveDeleteBucketLifecycle(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	if err := layer.DeleteBucketLifecycle(ctx, bucket); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func serveGetBucketCORS(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := layer.GetBucketCORS(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, config)
+	return nil
+}
+
+func servePutBucketCORS(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := ParseBucketCORS(configurationBody(r))
+	if err != nil {
+		return err
+	}
+	if err := layer.SetBucketCORS(ctx, bucket, config); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveDeleteBucketCORS(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	if err := layer.DeleteBucketCORS(ctx, bucket); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func serveGetBucketWebsite(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := layer.GetBucketWebsite(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, config)
+	return nil
+}
+
+func servePutBucketWebsite(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := ParseBucketWebsite(configurationBody(r))
+	if err != nil {
+		return err
+	}
+	if err := layer.SetBucketWebsite(ctx, bucket, config); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveDeleteBucketWebsite(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	if err := layer.DeleteBucketWebsite(ctx, bucket); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func serveGetBucketNotification(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := layer.GetBucketNotification(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, config)
+	return nil
+}
+
+func servePutBucketNotification(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := ParseBucketNotification(configurationBody(r))
+	if err != nil {
+		return err
+	}
+	if err := layer.SetBucketNotification(ctx, bucket, config); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// lockConfigurationError returns the error to respond with if parsing an
+// Object Lock configuration failed with err.
+func lockConfigurationError(err error) error {
+	switch err {
+	case objectlock.ErrInvalidRetentionDate, objectlock.ErrPastObjectLockRetainDate, objectlock.ErrUnknownWORMModeDirective:
+		return err
+	}
+	return objectlock.ErrMalformedXML
+}
+
+func serveGetObjectLockConfig(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := layer.GetObjectLockConfig(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, config)
+	return nil
+}
+
+func servePutObjectLockConfig(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	config, err := objectlock.ParseObjectLockConfig(configurationBody(r))
+	if err != nil {
+		return lockConfigurationError(err)
+	}
+	if err := layer.SetObjectLockConfig(ctx, bucket, config); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveGetObjectRetention(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	retention, err := layer.GetObjectRetention(ctx, bucket, object, r.URL.Query().Get("versionId"))
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, retention)
+	return nil
+}
+
+func servePutObjectRetention(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	retention, err := objectlock.ParseObjectRetention(configurationBody(r))
+	if err != nil {
+		return lockConfigurationError(err)
+	}
+	if err := layer.SetObjectRetention(ctx, bucket, object, r.URL.Query().Get("versionId"), retention); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveGetObjectLegalHold(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	legalHold, err := layer.GetObjectLegalHold(ctx, bucket, object, r.URL.Query().Get("versionId"))
+	if err != nil {
+		return err
+	}
+	writeXMLResponse(w, legalHold)
+	return nil
+}
+
+func servePutObjectLegalHold(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	legalHold, err := objectlock.ParseObjectLegalHold(configurationBody(r))
+	if err != nil {
+		return lockConfigurationError(err)
+	}
+	if err := layer.SetObjectLegalHold(ctx, bucket, object, r.URL.Query().Get("versionId"), legalHold); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func serveGetObjectAttributes(ctx context.Context, layer *singleTenancyLayer, w http.ResponseWriter, r *http.Request, bucket, object string) error {
+	opts := ObjectAttributesOptions{VersionID: r.URL.Query().Get("versionId")}
+	for header, value := range map[string]*int{
+		"X-Amz-Max-Parts":          &opts.MaxParts,
+		"X-Amz-Part-Number-Marker": &opts.PartNumberMarker,
+	} {
+		if r.Header.Get(header) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(r.Header.Get(header))
+		if err != nil {
+			return minio.InvalidArgument{Bucket: bucket, Object: object, Err: err}
+		}
+		*value = n
+	}
+
+	attributes, err := layer.GetObjectAttributes(ctx, bucket, object, opts)
+	if err != nil {
+		return err
+	}
+
+	response, err := selectObjectAttributes(attributes, r.Header.Values("X-Amz-Object-Attributes"))
+	if err != nil {
+		return minio.InvalidArgument{Bucket: bucket, Object: object, Err: err}
+	}
+
+	if !attributes.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", attributes.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if attributes.VersionID != "" {
+		w.Header().Set("X-Amz-Version-Id", attributes.VersionID)
+	}
+	writeXMLResponse(w, response)
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+// ErrMalformedPolicy is a custom error for bucket policies that are invalid.
+var ErrMalformedPolicy = miniogo.ErrorResponse{
+	Code:       "MalformedPolicy",
+	StatusCode: http.StatusBadRequest,
+	Message:    "Policy has invalid resource.",
+}
+
+// ErrUnenforceablePolicy is a custom error for bucket policies with
+// statements the gateway doesn't enforce.
+var ErrUnenforceablePolicy = miniogo.ErrorResponse{
+	Code:       "MalformedPolicy",
+	StatusCode: http.StatusBadRequest,
+	Message:    "Policy has statements that can't be enforced: only statements allowing anonymous access are supported.",
+}
+
+// storePolicyKey is the metadata store key of the policy of a bucket.
+var storePolicyKey = []byte("policy")
+
+// SetBucketPolicy sets the policy of bucket. Policies are kept in the
+// metadata store.
+//
+// minio evaluates policies of anonymous requests without credentials, so
+// the existence of bucket is only checked if ctx has a project.
+//
+// Requests with credentials are allowed by their access grant and never
+// evaluated against bucket policies, so policies that deny access or allow
+// it to anyone but anonymous principals are rejected instead of being kept
+// without effect.
+func (layer *gatewayLayer) SetBucketPolicy(ctx context.Context, bucketName string, bucketPolicy *policy.Policy) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "PutBucketPolicy"}
+	}
+
+	if err := bucketPolicy.Validate(bucketName); err != nil {
+		return ErrMalformedPolicy
+	}
+
+	if !enforceablePolicy(bucketPolicy) {
+		return ErrUnenforceablePolicy
+	}
+
+	if err := checkBucketExists(ctx, bucketName); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(bucketPolicy)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	return ConvertError(layer.metadataStore.put(storeBucketConfig, storeBucket(ctx, bucketName), storePolicyKey, value), bucketName, "")
+}
+
+// GetBucketPolicy returns the policy of bucket.
+func (layer *gatewayLayer) GetBucketPolicy(ctx context.Context, bucketName string) (_ *policy.Policy, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return nil, minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if layer.metadataStore == nil {
+		return nil, minio.BucketPolicyNotFound{Bucket: bucketName}
+	}
+
+	value, found, err := layer.metadataStore.get(storeBucketConfig, storeBucket(ctx, bucketName), storePolicyKey)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if !found {
+		return nil, minio.BucketPolicyNotFound{Bucket: bucketName}
+	}
+
+	return policy.ParseConfig(bytes.NewReader(value), bucketName)
+}
+
+// DeleteBucketPolicy removes the policy of bucket.
+func (layer *gatewayLayer) DeleteBucketPolicy(ctx context.Context, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if err := checkBucketExists(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storePolicyKey), bucketName, "")
+}
+
+// enforceablePolicy returns whether all statements of bucketPolicy allow
+// anonymous principals, the only ones it's evaluated for.
+func enforceablePolicy(bucketPolicy *policy.Policy) bool {
+	for _, statement := range bucketPolicy.Statements {
+		if statement.Effect != policy.Allow || !statement.Principal.AWS.Contains("*") {
+			return false
+		}
+	}
+	return true
+}
+
+// checkBucketExists returns an error if bucket doesn't exist in the project
+// in ctx. It returns nil if ctx has no project.
+func checkBucketExists(ctx context.Context, bucketName string) error {
+	project, ok := GetUplinkProject(ctx)
+	if !ok || project == nil {
+		return nil
+	}
+
+	_, err := project.StatBucket(ctx, bucketName)
+	return ConvertError(err, bucketName, "")
+}
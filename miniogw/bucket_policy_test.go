@@ -0,0 +1,88 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+func TestBucketPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	_, err = layer.GetBucketPolicy(ctx, "public")
+	require.ErrorAs(t, err, &minio.BucketPolicyNotFound{})
+
+	publicRead, err := policy.ParseConfig(strings.NewReader(`{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Effect": "Allow",
+			"Principal": {"AWS": ["*"]},
+			"Action": ["s3:GetObject"],
+			"Resource": ["arn:aws:s3:::public/*"]
+		}]
+	}`), "public")
+	require.NoError(t, err)
+
+	require.NoError(t, layer.SetBucketPolicy(ctx, "public", publicRead))
+	require.ErrorIs(t, layer.SetBucketPolicy(ctx, "private", publicRead), ErrMalformedPolicy)
+
+	// Statements that aren't evaluated are rejected.
+	for _, statement := range []string{
+		`{"Effect": "Deny", "Principal": {"AWS": ["*"]}, "Action": ["s3:GetObject"], "Resource": ["arn:aws:s3:::public/secret/*"]}`,
+		`{"Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::123456789012:user/reader"]}, "Action": ["s3:GetObject"], "Resource": ["arn:aws:s3:::public/*"]}`,
+	} {
+		unenforceable, err := policy.ParseConfig(strings.NewReader(`{"Version": "2012-10-17", "Statement": [`+statement+`]}`), "public")
+		require.NoError(t, err)
+		require.ErrorIs(t, layer.SetBucketPolicy(ctx, "public", unenforceable), ErrUnenforceablePolicy, statement)
+	}
+
+	stored, err := layer.GetBucketPolicy(ctx, "public")
+	require.NoError(t, err)
+	assert.True(t, stored.IsAllowed(policy.Args{Action: policy.GetObjectAction, BucketName: "public", ObjectName: "a"}))
+	assert.False(t, stored.IsAllowed(policy.Args{Action: policy.PutObjectAction, BucketName: "public", ObjectName: "a"}))
+
+	_, err = layer.GetBucketPolicy(ctx, "private")
+	require.ErrorAs(t, err, &minio.BucketPolicyNotFound{})
+
+	require.NoError(t, layer.DeleteBucketPolicy(ctx, "public"))
+	_, err = layer.GetBucketPolicy(ctx, "public")
+	require.ErrorAs(t, err, &minio.BucketPolicyNotFound{})
+
+	// Without a metadata store, there are no policies.
+	_, err = (&gatewayLayer{}).GetBucketPolicy(ctx, "public")
+	require.ErrorAs(t, err, &minio.BucketPolicyNotFound{})
+	require.ErrorAs(t, (&gatewayLayer{}).SetBucketPolicy(ctx, "public", publicRead), &minio.NotImplemented{})
+
+	// Public buckets are readable unless they have policies of their own.
+	single := &singleTenancyLayer{
+		logger: zap.NewNop(),
+		layer:  layer,
+		public: newPublicAccess(PublicAccessConfig{Buckets: []string{"public"}, Listing: true}),
+	}
+
+	fallback, err := single.GetBucketPolicy(ctx, "public")
+	require.NoError(t, err)
+	assert.True(t, fallback.IsAllowed(policy.Args{Action: policy.ListBucketAction, BucketName: "public"}))
+
+	require.NoError(t, single.SetBucketPolicy(ctx, "public", publicRead))
+	stored, err = single.GetBucketPolicy(ctx, "public")
+	require.NoError(t, err)
+	assert.False(t, stored.IsAllowed(policy.Args{Action: policy.ListBucketAction, BucketName: "public"}))
+}
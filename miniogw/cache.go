@@ -0,0 +1,358 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+
+	minio "storj.io/minio/cmd"
+)
+
+// objectCache caches list pages, object stats and bucket existence for a
+// limited time. Every write to a bucket made through the gateway bumps the
+// generation of the bucket, which invalidates all list pages of the bucket
+// and keeps results of reads that raced with the write from being cached.
+// Object stats are invalidated per key. Bucket names are only unique within a
+// project, so buckets of different projects are cached separately.
+//
+// Cached object infos are copied in and out, so callers can't modify cached
+// entries. All methods are safe to call on a nil *objectCache, which caches
+// nothing.
+type objectCache struct {
+	now func() time.Time
+
+	mu          sync.Mutex
+	generations map[projectBucket]uint64
+	lists       *lruCache[string, cachedList]
+	stats       *lruCache[statCacheKey, minio.ObjectInfo]
+	buckets     *lruCache[projectBucket, minio.BucketInfo]
+}
+
+type cachedList struct {
+	generation uint64
+	info       minio.ListObjectsV2Info
+	next       *listingToken
+}
+
+type statCacheKey struct {
+	bucket projectBucket
+	key    string
+}
+
+// newObjectCache returns a new objectCache configured by config or nil if
+// caching is disabled.
+func newObjectCache(config CacheConfig) *objectCache {
+	if !config.Enabled {
+		return nil
+	}
+
+	return &objectCache{
+		now:         time.Now,
+		generations: make(map[projectBucket]uint64),
+		lists:       newLRUCache[string, cachedList](config.MaxListPages, config.ListTTL),
+		stats:       newLRUCache[statCacheKey, minio.ObjectInfo](config.MaxStats, config.StatTTL),
+		buckets:     newLRUCache[projectBucket, minio.BucketInfo](config.MaxBuckets, config.BucketTTL),
+	}
+}
+
+// generation returns the current generation of bucket. Callers pass it back
+// when caching results of reads started at this generation.
+func (cache *objectCache) generation(bucket projectBucket) uint64 {
+	if cache == nil {
+		return 0
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	return cache.generations[bucket]
+}
+
+// getList returns the cached list page of bucket identified by key.
+func (cache *objectCache) getList(bucket projectBucket, key string) (minio.ListObjectsV2Info, *listingToken, bool) {
+	if cache == nil {
+		return minio.ListObjectsV2Info{}, nil, false
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.lists.get(key, cache.now())
+	if ok && entry.generation != cache.generations[bucket] {
+		cache.lists.remove(key)
+		ok = false
+	}
+	countCacheLookup("list", ok)
+	if !ok {
+		return minio.ListObjectsV2Info{}, nil, false
+	}
+
+	return cloneListInfo(entry.info), entry.next, true
+}
+
+// putList caches the list page of bucket identified by key if bucket hasn't
+// been written to since generation.
+func (cache *objectCache) putList(bucket projectBucket, key string, generation uint64, info minio.ListObjectsV2Info, next *listingToken) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if generation != cache.generations[bucket] {
+		return
+	}
+
+	cache.lists.put(key, cachedList{
+		generation: generation,
+		info:       cloneListInfo(info),
+		next:       next,
+	}, cache.now())
+}
+
+// getStat returns the cached stat of the latest version of key in bucket.
+func (cache *objectCache) getStat(bucket projectBucket, key string) (minio.ObjectInfo, bool) {
+	if cache == nil {
+		return minio.ObjectInfo{}, false
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	info, ok := cache.stats.get(statCacheKey{bucket: bucket, key: key}, cache.now())
+	countCacheLookup("stat", ok)
+
+	return cloneObjectInfo(info), ok
+}
+
+// putStat caches the stat of the latest version of key in bucket if bucket
+// hasn't been written to since generation.
+func (cache *objectCache) putStat(bucket projectBucket, key string, generation uint64, info minio.ObjectInfo) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if generation != cache.generations[bucket] {
+		return
+	}
+
+	cache.stats.put(statCacheKey{bucket: bucket, key: key}, cloneObjectInfo(info), cache.now())
+}
+
+// getBucket returns the cached info of bucket.
+func (cache *objectCache) getBucket(bucket projectBucket) (minio.BucketInfo, bool) {
+	if cache == nil {
+		return minio.BucketInfo{}, false
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	info, ok := cache.buckets.get(bucket, cache.now())
+	countCacheLookup("bucket", ok)
+
+	return info, ok
+}
+
+// putBucket caches the info of bucket if bucket hasn't been created or
+// deleted since generation.
+func (cache *objectCache) putBucket(bucket projectBucket, generation uint64, info minio.BucketInfo) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if generation != cache.generations[bucket] {
+		return
+	}
+
+	cache.buckets.put(bucket, info, cache.now())
+}
+
+// invalidateObject invalidates the stat of key and all list pages of bucket.
+func (cache *objectCache) invalidateObject(bucket projectBucket, key string) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.generations[bucket]++
+	cache.stats.remove(statCacheKey{bucket: bucket, key: key})
+}
+
+// invalidateBucket invalidates everything cached about bucket.
+func (cache *objectCache) invalidateBucket(bucket projectBucket) {
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.generations[bucket]++
+	cache.buckets.remove(bucket)
+	cache.stats.removeIf(func(key statCacheKey) bool {
+		return key.bucket == bucket
+	})
+}
+
+// listCacheKey returns the key of the list page of bucket described by state
+// and maxKeys.
+func listCacheKey(bucket projectBucket, state listingToken, maxKeys int) string {
+	data := appendTokenString(nil, bucket.project)
+	data = appendTokenString(data, bucket.name)
+	data = append(data, byte(state.Strategy))
+	data = appendTokenString(data, state.Prefix)
+	data = appendTokenString(data, state.Delimiter)
+	data = appendTokenString(data, state.Cursor)
+	data = binary.AppendUvarint(data, uint64(len(state.Skip)))
+	for _, key := range state.Skip {
+		data = appendTokenString(data, key)
+	}
+	data = binary.AppendVarint(data, int64(maxKeys))
+	return string(data)
+}
+
+// bucketCache returns the cache and bucket of the project in ctx to cache
+// bucket under. The cache is nil, so nothing is cached, if caching is disabled
+// or ctx doesn't identify the project.
+func (layer *gatewayLayer) bucketCache(ctx context.Context, bucket string) (*objectCache, projectBucket) {
+	if layer.cache == nil {
+		return nil, projectBucket{}
+	}
+	cacheBucket, ok := projectBucketOf(ctx, bucket)
+	if !ok {
+		return nil, projectBucket{}
+	}
+	return layer.cache, cacheBucket
+}
+
+// cloneListInfo returns a copy of info that doesn't share anything mutable
+// with it.
+func cloneListInfo(info minio.ListObjectsV2Info) minio.ListObjectsV2Info {
+	info.Prefixes = slices.Clone(info.Prefixes)
+	if info.Objects != nil {
+		objects := make([]minio.ObjectInfo, len(info.Objects))
+		for i, object := range info.Objects {
+			objects[i] = cloneObjectInfo(object)
+		}
+		info.Objects = objects
+	}
+	return info
+}
+
+// cloneObjectInfo returns a copy of info that doesn't share its metadata or
+// parts with it.
+func cloneObjectInfo(info minio.ObjectInfo) minio.ObjectInfo {
+	info.UserDefined = maps.Clone(info.UserDefined)
+	info.Parts = slices.Clone(info.Parts)
+	return info
+}
+
+func countCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	mon.Counter("gateway_cache_"+result, monkit.NewSeriesTag("cache", cache)).Inc(1)
+}
+
+// lruCache is a size-limited cache with expiring entries that evicts the
+// least recently used entry when full. It's not safe for concurrent use.
+type lruCache[K comparable, V any] struct {
+	capacity int
+	ttl      time.Duration
+
+	entries map[K]*list.Element
+	order   *list.List
+}
+
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// newLRUCache returns a cache holding at most capacity entries for ttl. If
+// either capacity or ttl is not positive, the cache holds nothing.
+func newLRUCache[K comparable, V any](capacity int, ttl time.Duration) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (cache *lruCache[K, V]) get(key K, now time.Time) (value V, ok bool) {
+	element, ok := cache.entries[key]
+	if !ok {
+		return value, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	if !now.Before(entry.expires) {
+		cache.removeElement(element)
+		return value, false
+	}
+
+	cache.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (cache *lruCache[K, V]) put(key K, value V, now time.Time) {
+	if cache.capacity <= 0 || cache.ttl <= 0 {
+		return
+	}
+
+	entry := &lruEntry[K, V]{key: key, value: value, expires: now.Add(cache.ttl)}
+
+	if element, ok := cache.entries[key]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.order.PushFront(entry)
+
+	for cache.order.Len() > cache.capacity {
+		cache.removeElement(cache.order.Back())
+	}
+}
+
+func (cache *lruCache[K, V]) remove(key K) {
+	if element, ok := cache.entries[key]; ok {
+		cache.removeElement(element)
+	}
+}
+
+func (cache *lruCache[K, V]) removeIf(match func(K) bool) {
+	for key, element := range cache.entries {
+		if match(key) {
+			cache.removeElement(element)
+		}
+	}
+}
+
+func (cache *lruCache[K, V]) removeElement(element *list.Element) {
+	delete(cache.entries, element.Value.(*lruEntry[K, V]).key)
+	cache.order.Remove(element)
+}
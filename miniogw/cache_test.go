@@ -0,0 +1,171 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+)
+
+func TestObjectCacheDisabled(t *testing.T) {
+	cache := newObjectCache(CacheConfig{})
+	require.Nil(t, cache)
+
+	bucket := projectBucket{project: "project", name: "bucket"}
+
+	cache.putStat(bucket, "key", cache.generation(bucket), minio.ObjectInfo{Name: "key"})
+	_, ok := cache.getStat(bucket, "key")
+	assert.False(t, ok)
+
+	cache.invalidateObject(bucket, "key")
+	cache.invalidateBucket(bucket)
+}
+
+func TestObjectCache(t *testing.T) {
+	now := time.Now()
+
+	cache := newObjectCache(CacheConfig{
+		Enabled:      true,
+		ListTTL:      time.Second,
+		StatTTL:      time.Minute,
+		BucketTTL:    time.Minute,
+		MaxListPages: 10,
+		MaxStats:     2,
+		MaxBuckets:   10,
+	})
+	cache.now = func() time.Time { return now }
+
+	bucket := projectBucket{project: "project", name: "bucket"}
+	other := projectBucket{project: "project", name: "other"}
+	// Bucket names are only unique within a project.
+	otherProject := projectBucket{project: "other", name: "bucket"}
+
+	t.Run("stats", func(t *testing.T) {
+		gen := cache.generation(bucket)
+		cache.putStat(bucket, "a", gen, minio.ObjectInfo{Name: "a"})
+		cache.putStat(bucket, "b", gen, minio.ObjectInfo{Name: "b"})
+
+		_, ok := cache.getStat(otherProject, "a")
+		assert.False(t, ok)
+
+		info, ok := cache.getStat(bucket, "a")
+		require.True(t, ok)
+		assert.Equal(t, "a", info.Name)
+
+		// a was used more recently than b, so b is evicted.
+		cache.putStat(bucket, "c", gen, minio.ObjectInfo{Name: "c"})
+		_, ok = cache.getStat(bucket, "b")
+		assert.False(t, ok)
+		_, ok = cache.getStat(bucket, "a")
+		assert.True(t, ok)
+
+		cache.invalidateObject(bucket, "a")
+		_, ok = cache.getStat(bucket, "a")
+		assert.False(t, ok)
+		_, ok = cache.getStat(bucket, "c")
+		assert.True(t, ok)
+
+		// Results of reads that started before a write aren't cached.
+		cache.putStat(bucket, "a", gen, minio.ObjectInfo{Name: "a"})
+		_, ok = cache.getStat(bucket, "a")
+		assert.False(t, ok)
+
+		cache.invalidateBucket(bucket)
+		_, ok = cache.getStat(bucket, "c")
+		assert.False(t, ok)
+	})
+
+	t.Run("lists", func(t *testing.T) {
+		state := listingToken{Prefix: "p/", Delimiter: "/"}
+		key := listCacheKey(bucket, state, 1000)
+
+		assert.NotEqual(t, key, listCacheKey(bucket, state, 999))
+		assert.NotEqual(t, key, listCacheKey(other, state, 1000))
+		assert.NotEqual(t, key, listCacheKey(otherProject, state, 1000))
+		assert.NotEqual(t, key, listCacheKey(bucket, listingToken{Prefix: "p/", Delimiter: "/", Skip: []string{"p/a"}}, 1000))
+
+		next := &listingToken{Strategy: listingStrategyFast, Prefix: "p/", Delimiter: "/", Cursor: "p/a"}
+		cache.putList(bucket, key, cache.generation(bucket), minio.ListObjectsV2Info{
+			IsTruncated: true,
+			Prefixes:    []string{"p/a/"},
+		}, next)
+
+		info, cachedNext, ok := cache.getList(bucket, key)
+		require.True(t, ok)
+		assert.Equal(t, []string{"p/a/"}, info.Prefixes)
+		assert.Equal(t, next, cachedNext)
+
+		// Writes to other buckets don't matter.
+		cache.invalidateObject(other, "p/b")
+		cache.invalidateObject(otherProject, "p/b")
+		_, _, ok = cache.getList(bucket, key)
+		assert.True(t, ok)
+
+		cache.invalidateObject(bucket, "p/b")
+		_, _, ok = cache.getList(bucket, key)
+		assert.False(t, ok)
+
+		cache.putList(bucket, key, cache.generation(bucket), minio.ListObjectsV2Info{}, nil)
+		_, _, ok = cache.getList(bucket, key)
+		assert.True(t, ok)
+
+		now = now.Add(time.Second)
+		_, _, ok = cache.getList(bucket, key)
+		assert.False(t, ok)
+	})
+
+	t.Run("buckets", func(t *testing.T) {
+		cache.putBucket(bucket, cache.generation(bucket), minio.BucketInfo{Name: "bucket"})
+		_, ok := cache.getBucket(otherProject)
+		assert.False(t, ok)
+
+		info, ok := cache.getBucket(bucket)
+		require.True(t, ok)
+		assert.Equal(t, "bucket", info.Name)
+
+		// Object writes don't affect bucket existence.
+		cache.invalidateObject(bucket, "key")
+		_, ok = cache.getBucket(bucket)
+		assert.True(t, ok)
+
+		cache.invalidateBucket(bucket)
+		_, ok = cache.getBucket(bucket)
+		assert.False(t, ok)
+	})
+
+	t.Run("copies", func(t *testing.T) {
+		object := minio.ObjectInfo{Name: "a", UserDefined: map[string]string{"k": "v"}}
+		key := listCacheKey(bucket, listingToken{}, 1000)
+
+		cache.putStat(bucket, "a", cache.generation(bucket), object)
+		cache.putList(bucket, key, cache.generation(bucket), minio.ListObjectsV2Info{Objects: []minio.ObjectInfo{object}}, nil)
+
+		// Neither the cached nor returned metadata is shared.
+		object.UserDefined["k"] = "changed"
+
+		info, ok := cache.getStat(bucket, "a")
+		require.True(t, ok)
+		assert.Equal(t, "v", info.UserDefined["k"])
+		info.UserDefined["k"] = "changed"
+
+		list, _, ok := cache.getList(bucket, key)
+		require.True(t, ok)
+		require.Len(t, list.Objects, 1)
+		assert.Equal(t, "v", list.Objects[0].UserDefined["k"])
+		list.Objects[0].UserDefined["k"] = "changed"
+
+		info, ok = cache.getStat(bucket, "a")
+		require.True(t, ok)
+		assert.Equal(t, "v", info.UserDefined["k"])
+
+		list, _, ok = cache.getList(bucket, key)
+		require.True(t, ok)
+		assert.Equal(t, "v", list.Objects[0].UserDefined["k"])
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"hash"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+)
+
+// Names of the additional checksum algorithms as used in headers.
+const (
+	checksumCRC32  = "CRC32"
+	checksumCRC32C = "CRC32C"
+	checksumSHA1   = "SHA1"
+	checksumSHA256 = "SHA256"
+)
+
+// checksumAlgorithms are all supported checksum algorithms.
+var checksumAlgorithms = []string{checksumCRC32, checksumCRC32C, checksumSHA1, checksumSHA256}
+
+const (
+	checksumHeaderPrefix       = "x-amz-checksum-"
+	checksumAlgorithmHeader    = "x-amz-checksum-algorithm"
+	sdkChecksumAlgorithmHeader = "x-amz-sdk-checksum-algorithm"
+	checksumModeHeader         = "x-amz-checksum-mode"
+
+	// checksumAlgorithmMetadataKey is the key of the checksum algorithm of a
+	// multipart upload in its metadata.
+	checksumAlgorithmMetadataKey = "s3:checksum-algorithm"
+)
+
+var (
+	// ErrChecksumMismatch is a custom error for when the checksum of uploaded
+	// data doesn't match the one the client sent.
+	ErrChecksumMismatch = miniogo.ErrorResponse{
+		Code:       "BadDigest",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The checksum you specified did not match the calculated checksum.",
+	}
+
+	// ErrInvalidChecksum is a custom error for when the client sent a checksum
+	// that can't be parsed or is for an unsupported algorithm.
+	ErrInvalidChecksum = miniogo.ErrorResponse{
+		Code:       "InvalidRequest",
+		StatusCode: http.StatusBadRequest,
+		Message:    "The checksum or checksum algorithm you specified is invalid.",
+	}
+)
+
+// newChecksumHash returns a new hash computing checksums using algorithm or
+// nil if the algorithm isn't supported.
+func newChecksumHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case checksumCRC32:
+		return crc32.NewIEEE()
+	case checksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	case checksumSHA1:
+		return sha1.New()
+	case checksumSHA256:
+		return sha256.New()
+	}
+	return nil
+}
+
+// checksumMetadataKey returns the key of the checksum computed using
+// algorithm in object metadata.
+func checksumMetadataKey(algorithm string) string {
+	return "s3:checksum-" + strings.ToLower(algorithm)
+}
+
+// checksumRequest describes the checksums a request asks for.
+type checksumRequest struct {
+	// algorithm is the algorithm of the checksum of uploaded data.
+	algorithm string
+	// expected is the base64-encoded checksum of uploaded data sent by the
+	// client, if any.
+	expected string
+	// mode is set if the client asks for checksums of downloaded objects.
+	mode bool
+}
+
+type checksumRequestKey struct{}
+
+// WithChecksums injects the checksums that a request with header asks for
+// into ctx.
+func WithChecksums(ctx context.Context, header http.Header) context.Context {
+	var request checksumRequest
+
+	for _, algorithm := range checksumAlgorithms {
+		if expected := header.Get(checksumHeaderPrefix + strings.ToLower(algorithm)); expected != "" {
+			request.algorithm, request.expected = algorithm, expected
+			break
+		}
+	}
+
+	if request.algorithm == "" {
+		request.algorithm = strings.ToUpper(header.Get(checksumAlgorithmHeader))
+	}
+	if request.algorithm == "" {
+		request.algorithm = strings.ToUpper(header.Get(sdkChecksumAlgorithmHeader))
+	}
+
+	request.mode = strings.EqualFold(header.Get(checksumModeHeader), "ENABLED")
+
+	if request == (checksumRequest{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, checksumRequestKey{}, request)
+}
+
+// ChecksumHandler is a middleware that passes the checksums that requests ask
+// for down to the object layer. minio doesn't support additional checksums.
+// Trailing checksums are verified by TrailerHandler in front of minio.
+func ChecksumHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Copies don't have data of their own.
+		if r.Header.Get(xhttp.AmzCopySource) == "" {
+			r = r.WithContext(WithChecksums(r.Context(), r.Header))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func getChecksumRequest(ctx context.Context) (checksumRequest, bool) {
+	request, ok := ctx.Value(checksumRequestKey{}).(checksumRequest)
+	return request, ok
+}
+
+// newUploadChecksum returns a checksum of the data of an upload if the
+// request in ctx asks for one.
+func newUploadChecksum(ctx context.Context) (*uploadChecksum, error) {
+	request, ok := getChecksumRequest(ctx)
+	if !ok || request.algorithm == "" {
+		return nil, nil
+	}
+
+	hash := newChecksumHash(request.algorithm)
+	if hash == nil {
+		return nil, ErrInvalidChecksum
+	}
+
+	checksum := &uploadChecksum{
+		algorithm: request.algorithm,
+		hash:      hash,
+	}
+
+	if request.expected != "" {
+		expected, err := base64.StdEncoding.DecodeString(request.expected)
+		if err != nil || len(expected) != hash.Size() {
+			return nil, ErrInvalidChecksum
+		}
+		checksum.expected = expected
+	}
+
+	return checksum, nil
+}
+
+// newPartChecksum returns a checksum of the data of a part of a multipart
+// upload with checksums computed using algorithm, if any. Parts of such
+// uploads always get one, even if the request in ctx doesn't ask for it.
+func newPartChecksum(ctx context.Context, algorithm string) (*uploadChecksum, error) {
+	checksum, err := newUploadChecksum(ctx)
+	if err != nil || algorithm == "" {
+		return checksum, err
+	}
+
+	if checksum == nil {
+		hash := newChecksumHash(algorithm)
+		if hash == nil {
+			return nil, ErrInvalidChecksum
+		}
+		return &uploadChecksum{algorithm: algorithm, hash: hash}, nil
+	}
+
+	if checksum.algorithm != algorithm {
+		return nil, ErrInvalidChecksum
+	}
+	return checksum, nil
+}
+
+// uploadChecksum computes the checksum of uploaded data written to it and
+// verifies it against the expected one.
+type uploadChecksum struct {
+	algorithm string
+	hash      hash.Hash
+	expected  []byte
+}
+
+// Write adds p to the checksum.
+func (checksum *uploadChecksum) Write(p []byte) (int, error) {
+	return checksum.hash.Write(p)
+}
+
+// sum returns the checksum of the written data or ErrChecksumMismatch if it
+// doesn't match the expected one.
+func (checksum *uploadChecksum) sum() ([]byte, error) {
+	sum := checksum.hash.Sum(nil)
+	if checksum.expected != nil && !bytes.Equal(sum, checksum.expected) {
+		return nil, ErrChecksumMismatch
+	}
+	return sum, nil
+}
+
+// compositeChecksum returns the checksum of a multipart upload with parts
+// with partChecksums, i.e., the checksum of concatenated checksums of its
+// parts followed by the number of parts.
+func compositeChecksum(algorithm string, partChecksums [][]byte) (string, error) {
+	hash := newChecksumHash(algorithm)
+	if hash == nil {
+		return "", ErrInvalidChecksum
+	}
+
+	for _, sum := range partChecksums {
+		_, _ = hash.Write(sum)
+	}
+
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)) + "-" + strconv.Itoa(len(partChecksums)), nil
+}
+
+// encodePartChecksum returns the metadata store value of the checksum sum of
+// a part computed using algorithm.
+func encodePartChecksum(algorithm string, sum []byte) []byte {
+	return append([]byte(algorithm+":"), sum...)
+}
+
+// decodePartChecksum returns the checksum of a part if it was computed using
+// algorithm.
+func decodePartChecksum(algorithm string, value []byte) ([]byte, bool) {
+	return bytes.CutPrefix(value, []byte(algorithm+":"))
+}
+
+// withChecksumHeaders returns info with the checksums of the object among
+// metadata returned as headers if the request in ctx asks for them.
+func withChecksumHeaders(ctx context.Context, info minio.ObjectInfo) minio.ObjectInfo {
+	if request, ok := getChecksumRequest(ctx); !ok || !request.mode {
+		return info
+	}
+
+	var userDefined map[string]string
+	for _, algorithm := range checksumAlgorithms {
+		value, ok := info.UserDefined[checksumMetadataKey(algorithm)]
+		if !ok {
+			continue
+		}
+		if userDefined == nil {
+			userDefined = make(map[string]string, len(info.UserDefined)+1)
+			for k, v := range info.UserDefined {
+				userDefined[k] = v
+			}
+		}
+		userDefined[checksumHeaderPrefix+strings.ToLower(algorithm)] = value
+	}
+
+	if userDefined != nil {
+		info.UserDefined = userDefined
+	}
+
+	return info
+}
@@ -0,0 +1,212 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+)
+
+func TestChecksumHandler(t *testing.T) {
+	var (
+		request checksumRequest
+		ok      bool
+	)
+	handler := ChecksumHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request, ok = getChecksumRequest(r.Context())
+	}))
+
+	serve := func(method string, header map[string]string) {
+		r := httptest.NewRequest(method, "/bucket/key", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	serve(http.MethodPut, map[string]string{"X-Amz-Checksum-Crc32": "DUoRhQ=="})
+	require.True(t, ok)
+	assert.Equal(t, checksumRequest{algorithm: checksumCRC32, expected: "DUoRhQ=="}, request)
+
+	serve(http.MethodPost, map[string]string{"X-Amz-Checksum-Algorithm": "sha256"})
+	require.True(t, ok)
+	assert.Equal(t, checksumRequest{algorithm: checksumSHA256}, request)
+
+	serve(http.MethodPut, map[string]string{"X-Amz-Sdk-Checksum-Algorithm": "CRC32C"})
+	require.True(t, ok)
+	assert.Equal(t, checksumRequest{algorithm: checksumCRC32C}, request)
+
+	serve(http.MethodGet, map[string]string{"X-Amz-Checksum-Mode": "ENABLED"})
+	require.True(t, ok)
+	assert.Equal(t, checksumRequest{mode: true}, request)
+
+	serve(http.MethodGet, nil)
+	assert.False(t, ok)
+
+	serve(http.MethodPut, map[string]string{"X-Amz-Checksum-Crc32": "DUoRhQ==", "X-Amz-Copy-Source": "bucket/src"})
+	assert.False(t, ok)
+}
+
+func TestUploadChecksum(t *testing.T) {
+	upload := func(request checksumRequest, data string) ([]byte, error) {
+		checksum, err := newUploadChecksum(context.WithValue(context.Background(), checksumRequestKey{}, request))
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(io.Discard, io.TeeReader(strings.NewReader(data), checksum))
+		require.NoError(t, err)
+		return checksum.sum()
+	}
+
+	checksum, err := newUploadChecksum(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, checksum)
+
+	_, err = upload(checksumRequest{algorithm: checksumCRC32, expected: "DUoRhQ=="}, "hello world")
+	require.NoError(t, err)
+
+	_, err = upload(checksumRequest{algorithm: checksumSHA256, expected: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="}, "hello world")
+	require.NoError(t, err)
+
+	_, err = upload(checksumRequest{algorithm: checksumCRC32, expected: "DUoRhQ=="}, "hello world!")
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Checksums are computed even if the client didn't send one.
+	sum, err := upload(checksumRequest{algorithm: checksumSHA1}, "hello world")
+	require.NoError(t, err)
+	assert.Len(t, sum, 20)
+
+	_, err = upload(checksumRequest{algorithm: "MD4"}, "hello world")
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+
+	_, err = upload(checksumRequest{algorithm: checksumCRC32, expected: "not base64"}, "hello world")
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+
+	_, err = upload(checksumRequest{algorithm: checksumSHA256, expected: "DUoRhQ=="}, "hello world")
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+}
+
+func TestPartChecksum(t *testing.T) {
+	ctx := context.WithValue(context.Background(), checksumRequestKey{}, checksumRequest{algorithm: checksumCRC32, expected: "DUoRhQ=="})
+
+	// Parts of uploads without an algorithm only get checksums they ask for.
+	checksum, err := newPartChecksum(context.Background(), "")
+	require.NoError(t, err)
+	assert.Nil(t, checksum)
+
+	checksum, err = newPartChecksum(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, checksumCRC32, checksum.algorithm)
+
+	// Parts of uploads with one always get them.
+	checksum, err = newPartChecksum(context.Background(), checksumSHA1)
+	require.NoError(t, err)
+	_, err = io.WriteString(checksum, "hello world")
+	require.NoError(t, err)
+	sum, err := checksum.sum()
+	require.NoError(t, err)
+	assert.Len(t, sum, 20)
+
+	checksum, err = newPartChecksum(ctx, checksumCRC32)
+	require.NoError(t, err)
+	_, err = io.WriteString(checksum, "hello world!")
+	require.NoError(t, err)
+	_, err = checksum.sum()
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	_, err = newPartChecksum(ctx, checksumCRC32C)
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+}
+
+func TestCompositeChecksum(t *testing.T) {
+	a, b := newChecksumHash(checksumCRC32), newChecksumHash(checksumCRC32)
+	_, _ = a.Write([]byte("a"))
+	_, _ = b.Write([]byte("b"))
+
+	checksum, err := compositeChecksum(checksumCRC32, [][]byte{a.Sum(nil), b.Sum(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, "7pJxdw==-2", checksum)
+
+	_, err = compositeChecksum("MD4", nil)
+	require.ErrorIs(t, err, ErrInvalidChecksum)
+}
+
+func TestPartChecksums(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	for _, part := range []int{1, 2} {
+		require.NoError(t, store.put(storePartChecksums, projectBucket{name: "bucket"}, uploadPartKey("upload", part), encodePartChecksum(checksumCRC32C, []byte{byte(part)})))
+	}
+	require.NoError(t, store.put(storePartChecksums, projectBucket{name: "bucket"}, uploadPartKey("upload2", 1), encodePartChecksum(checksumCRC32, []byte{1})))
+
+	require.NoError(t, store.put(storePartChecksums, projectBucket{name: "bucket"}, uploadKey("upload"), []byte(checksumCRC32C)))
+
+	algorithm, err := layer.uploadChecksumAlgorithm(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.Equal(t, checksumCRC32C, algorithm)
+
+	algorithm, err = layer.uploadChecksumAlgorithm(ctx, "bucket", "upload2")
+	require.NoError(t, err)
+	assert.Empty(t, algorithm)
+
+	parts := []minio.CompletePart{{PartNumber: 1}, {PartNumber: 2}}
+
+	partChecksums, err := layer.partChecksums(ctx, "bucket", "upload", checksumCRC32C, parts)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{{1}, {2}}, partChecksums)
+
+	// Parts must have checksums computed using the same algorithm.
+	_, err = layer.partChecksums(ctx, "bucket", "upload", checksumCRC32, parts)
+	require.ErrorAs(t, err, &minio.InvalidPart{})
+	_, err = layer.partChecksums(ctx, "bucket", "upload", checksumCRC32C, append(parts, minio.CompletePart{PartNumber: 3}))
+	require.ErrorAs(t, err, &minio.InvalidPart{})
+
+	layer.forgetUploadParts(ctx, "bucket", "upload")
+
+	_, found, err := store.get(storePartChecksums, projectBucket{name: "bucket"}, uploadPartKey("upload", 1))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	algorithm, err = layer.uploadChecksumAlgorithm(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.Empty(t, algorithm)
+
+	// Checksums of other uploads are kept.
+	_, found, err = store.get(storePartChecksums, projectBucket{name: "bucket"}, uploadPartKey("upload2", 1))
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestWithChecksumHeaders(t *testing.T) {
+	info := minio.ObjectInfo{UserDefined: map[string]string{
+		"s3:etag":                           "abc",
+		checksumMetadataKey(checksumSHA256): "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=",
+	}}
+
+	assert.Equal(t, info, withChecksumHeaders(context.Background(), info))
+
+	ctx := context.WithValue(context.Background(), checksumRequestKey{}, checksumRequest{mode: true})
+	withHeaders := withChecksumHeaders(ctx, info)
+	assert.Equal(t, "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek=", withHeaders.UserDefined["x-amz-checksum-sha256"])
+	assert.Equal(t, "abc", withHeaders.UserDefined["s3:etag"])
+
+	// The original metadata isn't modified.
+	assert.NotContains(t, info.UserDefined, "x-amz-checksum-sha256")
+}
@@ -0,0 +1,485 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/zeebo/errs"
+
+	"storj.io/uplink"
+	versioned "storj.io/uplink/private/object"
+)
+
+// compressionError is the error class for transparent compression.
+var compressionError = errs.Class("compression")
+
+const (
+	// compressionMetadataKey is the key of the compression algorithm of a
+	// compressed object or multipart upload in its metadata.
+	compressionMetadataKey = "s3:compression"
+	// uncompressedSizeMetadataKey is the key of the size of a compressed
+	// object before compression in its metadata.
+	uncompressedSizeMetadataKey = "s3:uncompressed-size"
+	// compressionFramesMetadataKey is the key of the frame index of a
+	// compressed object in its metadata.
+	compressionFramesMetadataKey = "s3:compression-frames"
+
+	compressionZstd = "zstd"
+
+	// compressionFrameSize is the size of data before compression of each
+	// frame of compressed data. Frames are decompressed independently, so
+	// ranges are decompressed from the frame containing their start.
+	compressionFrameSize = 1 << 20
+	// maxCompressionFrames is the maximum number of frames in the frame index
+	// of an object, which is kept in its metadata. Objects with more frames
+	// only have some of them indexed.
+	maxCompressionFrames = 64
+)
+
+// compressionMetadataKeys are the keys of metadata describing how an object
+// is compressed.
+var compressionMetadataKeys = []string{compressionMetadataKey, uncompressedSizeMetadataKey, compressionFramesMetadataKey}
+
+// shouldCompress returns whether an object uploaded to bucket with metadata
+// should be stored compressed.
+func (config CompressionConfig) shouldCompress(bucket string, metadata map[string]string) bool {
+	for _, b := range config.Buckets {
+		if b == bucket {
+			return true
+		}
+	}
+
+	if len(config.ContentTypes) == 0 {
+		return false
+	}
+
+	var contentType string
+	for k, v := range metadata {
+		if strings.EqualFold(k, "content-type") {
+			contentType = v
+			break
+		}
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+
+	for _, pattern := range config.ContentTypes {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		} else if pattern == contentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCompressed returns whether the object with metadata is stored compressed.
+func isCompressed(metadata map[string]string) bool {
+	return metadata[compressionMetadataKey] != ""
+}
+
+// objectSize returns the size of object as seen by clients, i.e., before
+// compression.
+func objectSize(object *uplink.Object) int64 {
+	if isCompressed(object.Custom) {
+		if size, err := strconv.ParseInt(object.Custom[uncompressedSizeMetadataKey], 10, 64); err == nil {
+			return size
+		}
+	}
+	return object.System.ContentLength
+}
+
+// compressionFrame is the start of a frame of compressed data.
+type compressionFrame struct {
+	offset           int64 // before compression
+	compressedOffset int64
+}
+
+// compressionFrames is an index of frames of compressed data, in order, not
+// including the first one, which starts at the start of the data.
+type compressionFrames []compressionFrame
+
+// shift returns frames of data that starts at start of other data, plus a
+// frame at start.
+func (frames compressionFrames) shift(start compressionFrame) compressionFrames {
+	shifted := make(compressionFrames, 0, len(frames)+1)
+	if start.offset > 0 {
+		shifted = append(shifted, start)
+	}
+	for _, frame := range frames {
+		shifted = append(shifted, compressionFrame{
+			offset:           start.offset + frame.offset,
+			compressedOffset: start.compressedOffset + frame.compressedOffset,
+		})
+	}
+	return shifted
+}
+
+// thin returns frames with every other frame removed as many times as it
+// takes to index at most maxCompressionFrames frames.
+func (frames compressionFrames) thin() compressionFrames {
+	for len(frames) > maxCompressionFrames {
+		thinned := frames[:0:0]
+		for i := 1; i < len(frames); i += 2 {
+			thinned = append(thinned, frames[i])
+		}
+		frames = thinned
+	}
+	return frames
+}
+
+// locate returns the start of the indexed frame containing offset and the
+// compressed offset of the start of the first indexed frame starting at or
+// after end, or -1 if there's none.
+func (frames compressionFrames) locate(offset, end int64) (start compressionFrame, compressedEnd int64) {
+	compressedEnd = -1
+	for _, frame := range frames {
+		if frame.offset <= offset {
+			start = frame
+		}
+		if frame.offset >= end {
+			compressedEnd = frame.compressedOffset
+			break
+		}
+	}
+	return start, compressedEnd
+}
+
+// appendBinary appends frames encoded as differences between successive
+// offsets.
+func (frames compressionFrames) appendBinary(b []byte) []byte {
+	var previous compressionFrame
+	for _, frame := range frames {
+		b = binary.AppendUvarint(b, uint64(frame.offset-previous.offset))
+		b = binary.AppendUvarint(b, uint64(frame.compressedOffset-previous.compressedOffset))
+		previous = frame
+	}
+	return b
+}
+
+// parseCompressionFrames parses frames encoded by appendBinary. Frames that
+// can't be parsed aren't used, so ranges are decompressed from the start.
+func parseCompressionFrames(b []byte) compressionFrames {
+	var (
+		frames   compressionFrames
+		previous compressionFrame
+	)
+	for len(b) > 0 {
+		offset, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil
+		}
+		compressedOffset, m := binary.Uvarint(b[n:])
+		if m <= 0 {
+			return nil
+		}
+		b = b[n+m:]
+
+		previous = compressionFrame{
+			offset:           previous.offset + int64(offset),
+			compressedOffset: previous.compressedOffset + int64(compressedOffset),
+		}
+		frames = append(frames, previous)
+	}
+	return frames
+}
+
+// encodeCompressionFrames returns the metadata value of the frame index of
+// an object with frames, or "" if it has a single indexed frame.
+func encodeCompressionFrames(frames compressionFrames) string {
+	return base64.RawStdEncoding.EncodeToString(frames.thin().appendBinary(nil))
+}
+
+// decodeCompressionFrames returns the frame index of an object from its
+// metadata value.
+func decodeCompressionFrames(value string) compressionFrames {
+	b, err := base64.RawStdEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	return parseCompressionFrames(b)
+}
+
+// countingWriter counts bytes written to the underlying writer.
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+// Write writes p to the underlying writer.
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// compressingWriter compresses data written to it in frames of
+// compressionFrameSize bytes and counts its size before compression.
+type compressingWriter struct {
+	encoder *zstd.Encoder
+	dst     *countingWriter
+	written int64
+	frames  compressionFrames
+}
+
+func newCompressingWriter(w io.Writer) (*compressingWriter, error) {
+	dst := &countingWriter{writer: w}
+	encoder, err := zstd.NewWriter(dst, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, compressionError.Wrap(err)
+	}
+	return &compressingWriter{encoder: encoder, dst: dst}, nil
+}
+
+// Write compresses p, starting a new frame whenever the current one is full.
+func (w *compressingWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		if w.written > 0 && w.written%compressionFrameSize == 0 {
+			if err := w.encoder.Close(); err != nil {
+				return written, compressionError.Wrap(err)
+			}
+			w.encoder.Reset(w.dst)
+			w.frames = append(w.frames, compressionFrame{offset: w.written, compressedOffset: w.dst.written})
+		}
+
+		chunk := p[:min(int64(len(p)), compressionFrameSize-w.written%compressionFrameSize)]
+		n, err := w.encoder.Write(chunk)
+		w.written += int64(n)
+		written += n
+		if err != nil {
+			return written, compressionError.Wrap(err)
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Close flushes compressed data. It doesn't close the underlying writer.
+func (w *compressingWriter) Close() error {
+	return compressionError.Wrap(w.encoder.Close())
+}
+
+// copyCompressed copies src compressed to dst and returns the number of
+// bytes read from src and the frames of the compressed data.
+func copyCompressed(dst io.Writer, src io.Reader) (int64, compressionFrames, error) {
+	w, err := newCompressingWriter(dst)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = io.Copy(w, src)
+	err = errs.Combine(err, w.Close())
+	return w.written, w.frames, err
+}
+
+// decompressingReader reads a range of a compressed download.
+type decompressingReader struct {
+	decoder  *zstd.Decoder
+	reader   io.Reader
+	download io.Closer
+}
+
+// newDecompressingReader returns a reader of length bytes starting at offset
+// of data decompressed from download, which starts at the start of a frame.
+// Frames can't be seeked, so everything in download before offset is
+// decompressed and discarded.
+func newDecompressingReader(download io.ReadCloser, offset, length int64) (_ *decompressingReader, err error) {
+	decoder, err := zstd.NewReader(download, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, compressionError.Wrap(errs.Combine(err, download.Close()))
+	}
+
+	if _, err := io.CopyN(io.Discard, decoder, offset); err != nil {
+		decoder.Close()
+		return nil, compressionError.Wrap(errs.Combine(err, download.Close()))
+	}
+
+	return &decompressingReader{
+		decoder:  decoder,
+		reader:   io.LimitReader(decoder, length),
+		download: download,
+	}, nil
+}
+
+// Read reads decompressed data.
+func (r *decompressingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && !errs.Is(err, io.EOF) {
+		err = compressionError.Wrap(err)
+	}
+	return n, err
+}
+
+// Close closes the download.
+func (r *decompressingReader) Close() error {
+	r.decoder.Close()
+	return r.download.Close()
+}
+
+// resolveRange returns the offset and length of the range of an object of
+// size described by opts, which follow the conventions of
+// uplink.DownloadOptions. Ranges past the end are truncated.
+func resolveRange(size int64, opts *uplink.DownloadOptions) (offset, length int64) {
+	if opts == nil {
+		return 0, size
+	}
+
+	if opts.Offset < 0 {
+		offset = size + opts.Offset
+		if offset < 0 {
+			offset = 0
+		}
+		return offset, size - offset
+	}
+
+	offset = opts.Offset
+	if offset > size {
+		offset = size
+	}
+	length = size - offset
+	if opts.Length >= 0 && opts.Length < length {
+		length = opts.Length
+	}
+	return offset, length
+}
+
+// downloadObject downloads the range described by opts of version of the
+// object at key. Ranges of compressed objects refer to the data before
+// compression, so these objects are downloaded from the start of the indexed
+// frame containing the start of the range (or from the start of the object
+// if it has no frame index) and decompressed.
+func downloadObject(ctx context.Context, project *uplink.Project, bucketName, key string, version []byte, opts *uplink.DownloadOptions) (_ io.ReadCloser, _ *versioned.VersionedObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var object *versioned.VersionedObject
+
+	download, err := versioned.DownloadObject(ctx, project, bucketName, key, version, opts)
+	switch {
+	case err == nil:
+		object = download.Info()
+		if !isCompressed(object.Custom) {
+			return download, object, nil
+		}
+		if opts == nil {
+			reader, err := newDecompressingReader(download, 0, objectSize(&object.Object))
+			return reader, object, err
+		}
+		_ = download.Close()
+	case opts == nil:
+		return nil, nil, err
+	default:
+		// The range might be valid only before compression.
+		var statErr error
+		object, statErr = versioned.StatObject(ctx, project, bucketName, key, version)
+		if statErr != nil || !isCompressed(object.Custom) {
+			return nil, nil, err
+		}
+	}
+
+	offset, length := resolveRange(objectSize(&object.Object), opts)
+
+	start, compressedEnd := decodeCompressionFrames(object.Custom[compressionFramesMetadataKey]).locate(offset, offset+length)
+
+	compressedLength := int64(-1)
+	if compressedEnd >= 0 {
+		compressedLength = compressedEnd - start.compressedOffset
+	}
+
+	frames, err := versioned.DownloadObject(ctx, project, bucketName, key, object.Version, &uplink.DownloadOptions{
+		Offset: start.compressedOffset,
+		Length: compressedLength,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := newDecompressingReader(frames, offset-start.offset, length)
+	return reader, object, err
+}
+
+// encodePartSize returns the metadata store value of the size of a part
+// before compression and of its frames.
+func encodePartSize(size int64, frames compressionFrames) []byte {
+	return frames.appendBinary(binary.BigEndian.AppendUint64(nil, uint64(size)))
+}
+
+// decodePartSize returns the size of a part before compression and its
+// frames.
+func decodePartSize(value []byte) (int64, compressionFrames, bool) {
+	if len(value) < 8 {
+		return 0, nil, false
+	}
+	return int64(binary.BigEndian.Uint64(value)), parseCompressionFrames(value[8:]), true
+}
+
+// compressesUpload returns whether parts of the multipart upload uploadID
+// are compressed.
+func (layer *gatewayLayer) compressesUpload(ctx context.Context, bucketName, uploadID string) (bool, error) {
+	if layer.metadataStore == nil {
+		return false, nil
+	}
+	_, found, err := layer.metadataStore.get(storeCompressedParts, storeBucket(ctx, bucketName), uploadKey(uploadID))
+	return found, err
+}
+
+// uncompressedPartSizes returns the sizes before compression of parts of
+// the multipart upload uploadID by their numbers, or nil if its parts aren't
+// compressed.
+func (layer *gatewayLayer) uncompressedPartSizes(ctx context.Context, bucketName, uploadID string) (map[int]int64, error) {
+	sizes := make(map[int]int64)
+	compressed, err := layer.forEachCompressedPart(ctx, bucketName, uploadID, func(partNumber int, size int64, _ compressionFrames) {
+		sizes[partNumber] = size
+	})
+	if !compressed {
+		return nil, err
+	}
+	return sizes, err
+}
+
+// compressedPartFrames returns the frames of parts of the multipart upload
+// uploadID by their numbers, or nil if its parts aren't compressed.
+func (layer *gatewayLayer) compressedPartFrames(ctx context.Context, bucketName, uploadID string) (map[int]compressionFrames, error) {
+	frames := make(map[int]compressionFrames)
+	compressed, err := layer.forEachCompressedPart(ctx, bucketName, uploadID, func(partNumber int, _ int64, partFrames compressionFrames) {
+		frames[partNumber] = partFrames
+	})
+	if !compressed {
+		return nil, err
+	}
+	return frames, err
+}
+
+// forEachCompressedPart calls fn with the number, the size before
+// compression and the frames of each part of the multipart upload uploadID
+// and returns whether its parts are compressed.
+func (layer *gatewayLayer) forEachCompressedPart(ctx context.Context, bucketName, uploadID string, fn func(partNumber int, size int64, frames compressionFrames)) (bool, error) {
+	compressed, err := layer.compressesUpload(ctx, bucketName, uploadID)
+	if err != nil || !compressed {
+		return false, err
+	}
+
+	return true, layer.metadataStore.forEachPrefix(storeCompressedParts, storeBucket(ctx, bucketName), uploadKey(uploadID), func(key, value []byte) error {
+		suffix := key[len(uploadKey(uploadID)):]
+		if len(suffix) != 4 {
+			return nil
+		}
+		if size, frames, ok := decodePartSize(value); ok {
+			fn(int(binary.BigEndian.Uint32(suffix)), size, frames)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,302 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/hash"
+	"storj.io/uplink"
+)
+
+func TestShouldCompress(t *testing.T) {
+	config := CompressionConfig{
+		Buckets:      []string{"logs"},
+		ContentTypes: []string{"application/json", "text/*"},
+	}
+
+	assert.True(t, config.shouldCompress("logs", nil))
+	assert.False(t, config.shouldCompress("other", nil))
+	assert.True(t, config.shouldCompress("other", map[string]string{"content-type": "application/json"}))
+	assert.True(t, config.shouldCompress("other", map[string]string{"Content-Type": "Application/JSON; charset=utf-8"}))
+	assert.True(t, config.shouldCompress("other", map[string]string{"content-type": "text/csv"}))
+	assert.False(t, config.shouldCompress("other", map[string]string{"content-type": "textual/csv"}))
+	assert.False(t, config.shouldCompress("other", map[string]string{"content-type": "image/png"}))
+	assert.False(t, CompressionConfig{}.shouldCompress("logs", map[string]string{"content-type": "text/csv"}))
+}
+
+func TestCopyUploadOptions(t *testing.T) {
+	config := CompressionConfig{Buckets: []string{"logs"}}
+
+	// A compressed object copied with a TTL is uploaded again.
+	srcInfo := minio.ObjectInfo{UserDefined: map[string]string{
+		"content-type":              "text/plain",
+		"X-Amz-Meta-Object-Expires": "+1h",
+		"s3:etag":                   "abc",
+		compressionMetadataKey:      compressionZstd,
+		uncompressedSizeMetadataKey: "10",
+	}}
+
+	opts := copyUploadOptions(srcInfo, minio.ObjectOptions{Versioned: true})
+	assert.Equal(t, map[string]string{
+		"content-type":              "text/plain",
+		"X-Amz-Meta-Object-Expires": "+1h",
+	}, opts.UserDefined)
+	assert.True(t, opts.Versioned)
+	assert.True(t, opts.NoLock)
+	assert.Len(t, srcInfo.UserDefined, 5)
+
+	// The copy isn't marked compressed in a bucket without compression, and
+	// is compressed again in one with it.
+	assert.False(t, isCompressed(opts.UserDefined))
+	assert.False(t, config.shouldCompress("other", opts.UserDefined))
+	assert.True(t, config.shouldCompress("logs", opts.UserDefined))
+}
+
+func TestObjectSize(t *testing.T) {
+	object := &uplink.Object{System: uplink.SystemMetadata{ContentLength: 10}}
+	assert.EqualValues(t, 10, objectSize(object))
+
+	object.Custom = uplink.CustomMetadata{compressionMetadataKey: compressionZstd, uncompressedSizeMetadataKey: "100"}
+	assert.EqualValues(t, 100, objectSize(object))
+}
+
+func TestResolveRange(t *testing.T) {
+	for _, tt := range []struct {
+		opts           *uplink.DownloadOptions
+		offset, length int64
+	}{
+		{opts: nil, offset: 0, length: 100},
+		{opts: &uplink.DownloadOptions{Offset: 10, Length: 20}, offset: 10, length: 20},
+		{opts: &uplink.DownloadOptions{Offset: 10, Length: -1}, offset: 10, length: 90},
+		{opts: &uplink.DownloadOptions{Offset: 90, Length: 20}, offset: 90, length: 10},
+		{opts: &uplink.DownloadOptions{Offset: 200, Length: 20}, offset: 100, length: 0},
+		{opts: &uplink.DownloadOptions{Offset: -30, Length: -1}, offset: 70, length: 30},
+		{opts: &uplink.DownloadOptions{Offset: -300, Length: -1}, offset: 0, length: 100},
+	} {
+		offset, length := resolveRange(100, tt.opts)
+		assert.Equal(t, tt.offset, offset, "%+v", tt.opts)
+		assert.Equal(t, tt.length, length, "%+v", tt.opts)
+	}
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	part1 := strings.Repeat(`{"level":"info","msg":"hello"}`, 1000)
+	part2 := strings.Repeat(`{"level":"warn","msg":"world"}`, 1000)
+
+	// Parts are compressed independently, and their concatenation is a
+	// valid compressed object.
+	var compressed bytes.Buffer
+	for _, part := range []string{part1, part2} {
+		size, frames, err := copyCompressed(&compressed, strings.NewReader(part))
+		require.NoError(t, err)
+		assert.EqualValues(t, len(part), size)
+		assert.Empty(t, frames)
+	}
+	assert.Less(t, compressed.Len(), len(part1+part2)/10)
+
+	all := part1 + part2
+	for _, tt := range []struct{ offset, length int64 }{
+		{0, int64(len(all))},
+		{5, 100},
+		{int64(len(part1)) - 10, 20},
+		{int64(len(all)) - 1, 1},
+		{int64(len(all)), 0},
+	} {
+		reader, err := newDecompressingReader(io.NopCloser(bytes.NewReader(compressed.Bytes())), tt.offset, tt.length)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, all[tt.offset:tt.offset+tt.length], string(data))
+		require.NoError(t, reader.Close())
+	}
+
+	_, err := newDecompressingReader(io.NopCloser(strings.NewReader("not compressed")), 5, 5)
+	require.Error(t, err)
+}
+
+func TestUncompressedPartSizes(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	sizes, err := layer.uncompressedPartSizes(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.Nil(t, sizes)
+
+	require.NoError(t, store.put(storeCompressedParts, projectBucket{name: "bucket"}, uploadKey("upload"), nil))
+	require.NoError(t, store.put(storeCompressedParts, projectBucket{name: "bucket"}, uploadPartKey("upload", 1), encodePartSize(5<<20, compressionFrames{{offset: 1 << 20, compressedOffset: 10}})))
+	require.NoError(t, store.put(storeCompressedParts, projectBucket{name: "bucket"}, uploadPartKey("upload", 2), encodePartSize(1, nil)))
+	require.NoError(t, store.put(storeCompressedParts, projectBucket{name: "bucket"}, uploadPartKey("upload2", 1), encodePartSize(7, nil)))
+
+	sizes, err = layer.uncompressedPartSizes(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int64{1: 5 << 20, 2: 1}, sizes)
+
+	frames, err := layer.compressedPartFrames(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]compressionFrames{1: {{offset: 1 << 20, compressedOffset: 10}}, 2: nil}, frames)
+
+	layer.forgetUploadParts(ctx, "bucket", "upload")
+
+	compressed, err := layer.compressesUpload(ctx, "bucket", "upload")
+	require.NoError(t, err)
+	assert.False(t, compressed)
+
+	sizes, err = layer.uncompressedPartSizes(ctx, "bucket", "upload2")
+	require.NoError(t, err)
+	assert.Nil(t, sizes)
+}
+
+func TestCompressionFrames(t *testing.T) {
+	// Each frame has a different byte, so data decompressed from a wrong
+	// frame differs.
+	data := make([]byte, 3*compressionFrameSize+100)
+	for i := range data {
+		data[i] = byte('a' + (i%26+i/compressionFrameSize)%26)
+	}
+
+	var compressed bytes.Buffer
+	size, frames, err := copyCompressed(&compressed, bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.EqualValues(t, len(data), size)
+	require.Len(t, frames, 3)
+	for i, frame := range frames {
+		assert.EqualValues(t, (i+1)*compressionFrameSize, frame.offset)
+	}
+
+	index := decodeCompressionFrames(encodeCompressionFrames(frames))
+	assert.Equal(t, frames, index)
+
+	for _, tt := range []struct{ offset, length int64 }{
+		{0, 10},
+		{compressionFrameSize - 5, 10},
+		{compressionFrameSize, 1},
+		{2*compressionFrameSize + 7, compressionFrameSize},
+		{int64(len(data)) - 1, 1},
+		{int64(len(data)), 0},
+	} {
+		start, compressedEnd := index.locate(tt.offset, tt.offset+tt.length)
+		assert.LessOrEqual(t, start.offset, tt.offset)
+		assert.Greater(t, start.offset+compressionFrameSize, tt.offset, "the range starts in the frame")
+
+		end := int64(compressed.Len())
+		if compressedEnd >= 0 {
+			end = compressedEnd
+		}
+
+		// Only the frames containing the range are decompressed.
+		download := io.NopCloser(bytes.NewReader(compressed.Bytes()[start.compressedOffset:end]))
+		reader, err := newDecompressingReader(download, tt.offset-start.offset, tt.length)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, data[tt.offset:tt.offset+tt.length], got, "%+v", tt)
+		require.NoError(t, reader.Close())
+	}
+
+	// Objects without an index are decompressed from the start.
+	start, compressedEnd := compressionFrames(nil).locate(10, 20)
+	assert.Equal(t, compressionFrame{}, start)
+	assert.EqualValues(t, -1, compressedEnd)
+	assert.Empty(t, encodeCompressionFrames(nil))
+	assert.Nil(t, decodeCompressionFrames("not an index"))
+}
+
+func TestCompressionFramesThin(t *testing.T) {
+	var frames compressionFrames
+	for i := 1; i <= 1000; i++ {
+		frames = append(frames, compressionFrame{offset: int64(i) * compressionFrameSize, compressedOffset: int64(i) * 100})
+	}
+
+	// Parts start frames at their own offsets.
+	shifted := compressionFrames{{offset: 10, compressedOffset: 1}}.shift(compressionFrame{offset: 100, compressedOffset: 5})
+	assert.Equal(t, compressionFrames{{offset: 100, compressedOffset: 5}, {offset: 110, compressedOffset: 6}}, shifted)
+
+	thinned := frames.thin()
+	assert.LessOrEqual(t, len(thinned), maxCompressionFrames)
+	assert.Greater(t, len(thinned), maxCompressionFrames/2)
+	assert.Less(t, len(encodeCompressionFrames(frames)), 1024)
+
+	// The indexed frames are still evenly spread.
+	for i := 1; i < len(thinned); i++ {
+		assert.Equal(t, thinned[1].offset-thinned[0].offset, thinned[i].offset-thinned[i-1].offset)
+	}
+}
+
+func TestCompressedRangedDownload(t *testing.T) {
+	satellite, project := newTestProject(t, "bucket")
+	layer, ctx := newTestLayerWithConfig(t, project, S3CompatibilityConfig{
+		Compression: CompressionConfig{Buckets: []string{"bucket"}},
+	})
+
+	data := make([]byte, 3*compressionFrameSize+100)
+	for i := range data {
+		data[i] = byte('a' + (i%26+i/compressionFrameSize)%26)
+	}
+
+	putTestObject(ctx, t, layer, "bucket", "object", data, map[string]string{})
+
+	uploadID, err := layer.NewMultipartUpload(ctx, "bucket", "multipart", minio.ObjectOptions{UserDefined: map[string]string{}})
+	require.NoError(t, err)
+
+	var parts []minio.CompletePart
+	for i, part := range [][]byte{data[:compressionFrameSize+10], data[compressionFrameSize+10:]} {
+		reader, err := hash.NewReader(bytes.NewReader(part), int64(len(part)), "", "", int64(len(part)))
+		require.NoError(t, err)
+
+		info, err := layer.PutObjectPart(ctx, "bucket", "multipart", uploadID, i+1, minio.NewPutObjReader(reader), minio.ObjectOptions{})
+		require.NoError(t, err)
+		parts = append(parts, minio.CompletePart{PartNumber: i + 1, ETag: info.ETag})
+	}
+	_, err = layer.CompleteMultipartUpload(ctx, "bucket", "multipart", uploadID, parts, minio.ObjectOptions{})
+	require.NoError(t, err)
+
+	for _, key := range []string{"object", "multipart"} {
+		info, err := layer.GetObjectInfo(ctx, "bucket", key, minio.ObjectOptions{})
+		require.NoError(t, err)
+		assert.EqualValues(t, len(data), info.Size)
+		assert.NotEmpty(t, info.UserDefined[compressionFramesMetadataKey], key)
+
+		for _, rs := range []*minio.HTTPRangeSpec{
+			{Start: 5, End: 15},
+			{Start: compressionFrameSize + 5, End: compressionFrameSize + 15},
+			{Start: 2*compressionFrameSize + 7, End: 3*compressionFrameSize + 7},
+			{IsSuffixLength: true, Start: -50, End: -1},
+		} {
+			reader, err := layer.GetObjectNInfo(ctx, "bucket", key, rs, nil, 0, minio.ObjectOptions{})
+			require.NoError(t, err)
+
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.NoError(t, reader.Close())
+
+			offset, length, err := rs.GetOffsetLength(int64(len(data)))
+			require.NoError(t, err)
+			assert.Equal(t, data[offset:offset+length], got, "%s %+v", key, rs)
+
+			// Ranges past the first frame aren't downloaded from the start.
+			if offset >= compressionFrameSize {
+				assert.Positive(t, satellite.lastDownloadStart(), "%s %+v", key, rs)
+			} else {
+				assert.Zero(t, satellite.lastDownloadStart(), "%s %+v", key, rs)
+			}
+		}
+	}
+}
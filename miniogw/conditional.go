@@ -0,0 +1,188 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/uplink"
+	versioned "storj.io/uplink/private/object"
+)
+
+// writeConditions are the preconditions of a write that are evaluated
+// against the latest version of the object.
+type writeConditions struct {
+	// ifMatch is the value of If-Match: a list of ETags or "*".
+	ifMatch string
+	// ifNoneMatch is the value of If-None-Match. Only "*" is supported.
+	ifNoneMatch string
+}
+
+type writeConditionsKey struct{}
+
+// WithWriteConditions injects the If-Match and If-None-Match preconditions
+// of a PutObject or CompleteMultipartUpload request in header into ctx.
+func WithWriteConditions(ctx context.Context, header http.Header) context.Context {
+	conditions := writeConditions{
+		ifMatch:     header.Get(xhttp.IfMatch),
+		ifNoneMatch: header.Get(xhttp.IfNoneMatch),
+	}
+	if conditions == (writeConditions{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, writeConditionsKey{}, conditions)
+}
+
+// WriteConditionsHandler is a middleware that passes the write preconditions
+// of requests down to the object layer. minio evaluates them itself only for
+// reads.
+func WriteConditionsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Copies have their own preconditions on the source object.
+		if (r.Method == http.MethodPut || r.Method == http.MethodPost) && r.Header.Get(xhttp.AmzCopySource) == "" {
+			r = r.WithContext(WithWriteConditions(r.Context(), r.Header))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func getWriteConditions(ctx context.Context) (writeConditions, bool) {
+	conditions, ok := ctx.Value(writeConditionsKey{}).(writeConditions)
+	return conditions, ok
+}
+
+// validate returns an error if conditions can't be evaluated.
+func (conditions writeConditions) validate() error {
+	if conditions.ifNoneMatch != "" && conditions.ifNoneMatch != "*" {
+		return minio.NotImplemented{Message: "If-None-Match with ETags"}
+	}
+	return nil
+}
+
+// hold returns whether conditions hold for the current object, which is
+// nil if there's none.
+func (conditions writeConditions) hold(current *versioned.VersionedObject) bool {
+	exists := current != nil && !current.IsDeleteMarker
+
+	if conditions.ifNoneMatch == "*" && exists {
+		return false
+	}
+
+	if conditions.ifMatch != "" {
+		if !exists {
+			return false
+		}
+		return etagMatches(conditions.ifMatch, current.Custom["s3:etag"])
+	}
+
+	return true
+}
+
+// etagMatches returns whether etag is in the list of ETags or "*". ETags are
+// compared using the strong comparison of If-Match, so weak ETags in list
+// never match.
+func etagMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWriteConditions returns minio.PreConditionFailed if the preconditions
+// of the write in ctx don't hold for the latest version of the object at
+// key. It returns nil if there are none.
+func checkWriteConditions(ctx context.Context, project *uplink.Project, bucketName, key string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	conditions, ok := getWriteConditions(ctx)
+	if !ok {
+		return nil
+	}
+	if err := conditions.validate(); err != nil {
+		return err
+	}
+
+	current, err := versioned.StatObject(ctx, project, bucketName, key, nil)
+	if err != nil {
+		if !errors.Is(err, uplink.ErrObjectNotFound) {
+			return ConvertError(err, bucketName, key)
+		}
+		current = nil
+	}
+
+	if !conditions.hold(current) {
+		return minio.PreConditionFailed{}
+	}
+
+	return nil
+}
+
+// lockConditionalWrite serializes conditional writes to the object at key
+// made through this gateway, so that nothing is committed between evaluating
+// preconditions and committing. The satellite can't evaluate preconditions,
+// so writes made through other gateways might still race. It returns a
+// function releasing the lock, which is a no-op if the write has no
+// preconditions.
+func (layer *gatewayLayer) lockConditionalWrite(ctx context.Context, bucketName, key string) (unlock func()) {
+	if _, ok := getWriteConditions(ctx); !ok || layer.writeLocks == nil {
+		return func() {}
+	}
+	return layer.writeLocks.lock(bucketName + "/" + key)
+}
+
+// keyMutex is a set of mutexes identified by keys, which exist only while
+// they're held or waited on.
+type keyMutex struct {
+	mu      sync.Mutex
+	entries map[string]*keyMutexEntry
+}
+
+type keyMutexEntry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newKeyMutex() *keyMutex {
+	return &keyMutex{entries: make(map[string]*keyMutexEntry)}
+}
+
+// lock locks the mutex of key and returns a function unlocking it.
+func (m *keyMutex) lock(key string) (unlock func()) {
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &keyMutexEntry{}
+		m.entries[key] = entry
+	}
+	entry.refs++
+	m.mu.Unlock()
+
+	entry.mu.Lock()
+
+	return func() {
+		entry.mu.Unlock()
+
+		m.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(m.entries, key)
+		}
+		m.mu.Unlock()
+	}
+}
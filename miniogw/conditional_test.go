@@ -0,0 +1,122 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/uplink"
+	versioned "storj.io/uplink/private/object"
+)
+
+func TestWriteConditionsHold(t *testing.T) {
+	current := &versioned.VersionedObject{
+		Object: uplink.Object{Custom: uplink.CustomMetadata{"s3:etag": "abc"}},
+	}
+	deleteMarker := &versioned.VersionedObject{IsDeleteMarker: true}
+
+	for _, tt := range []struct {
+		conditions writeConditions
+		current    *versioned.VersionedObject
+		hold       bool
+	}{
+		{conditions: writeConditions{}, current: current, hold: true},
+		{conditions: writeConditions{ifNoneMatch: "*"}, current: nil, hold: true},
+		{conditions: writeConditions{ifNoneMatch: "*"}, current: deleteMarker, hold: true},
+		{conditions: writeConditions{ifNoneMatch: "*"}, current: current, hold: false},
+		{conditions: writeConditions{ifMatch: `"abc"`}, current: current, hold: true},
+		{conditions: writeConditions{ifMatch: `abc`}, current: current, hold: true},
+		{conditions: writeConditions{ifMatch: `"xyz", "abc"`}, current: current, hold: true},
+		{conditions: writeConditions{ifMatch: `"xyz"`}, current: current, hold: false},
+		// If-Match uses the strong comparison.
+		{conditions: writeConditions{ifMatch: `W/"abc"`}, current: current, hold: false},
+		{conditions: writeConditions{ifMatch: `*`}, current: current, hold: true},
+		{conditions: writeConditions{ifMatch: `*`}, current: nil, hold: false},
+		{conditions: writeConditions{ifMatch: `"abc"`}, current: deleteMarker, hold: false},
+	} {
+		assert.Equal(t, tt.hold, tt.conditions.hold(tt.current), "%+v", tt.conditions)
+	}
+
+	require.NoError(t, writeConditions{ifNoneMatch: "*", ifMatch: `"abc"`}.validate())
+	require.Error(t, writeConditions{ifNoneMatch: `"abc"`}.validate())
+}
+
+func TestWriteConditionsHandler(t *testing.T) {
+	var (
+		conditions writeConditions
+		ok         bool
+	)
+	handler := WriteConditionsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditions, ok = getWriteConditions(r.Context())
+	}))
+
+	request := func(method string, header map[string]string) {
+		r := httptest.NewRequest(method, "/bucket/key", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	request(http.MethodPut, map[string]string{"If-None-Match": "*"})
+	require.True(t, ok)
+	assert.Equal(t, writeConditions{ifNoneMatch: "*"}, conditions)
+
+	request(http.MethodPost, map[string]string{"If-Match": `"abc"`})
+	require.True(t, ok)
+	assert.Equal(t, writeConditions{ifMatch: `"abc"`}, conditions)
+
+	request(http.MethodPut, nil)
+	assert.False(t, ok)
+
+	request(http.MethodGet, map[string]string{"If-Match": `"abc"`})
+	assert.False(t, ok)
+
+	request(http.MethodPut, map[string]string{"If-Match": `"abc"`, "X-Amz-Copy-Source": "bucket/src"})
+	assert.False(t, ok)
+}
+
+func TestKeyMutex(t *testing.T) {
+	m := newKeyMutex()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		holders int
+		counter int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.lock("a")
+			defer unlock()
+
+			mu.Lock()
+			holders++
+			assert.Equal(t, 1, holders)
+			mu.Unlock()
+
+			counter++
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+		}()
+	}
+
+	// Other keys aren't blocked.
+	unlock := m.lock("b")
+	unlock()
+
+	wg.Wait()
+	assert.Equal(t, 10, counter)
+	assert.Empty(t, m.entries)
+}
@@ -3,6 +3,8 @@
 
 package miniogw
 
+import "time"
+
 // MinioConfig is a configuration struct that keeps details about starting Minio.
 type MinioConfig struct {
 	AccessKey string `help:"Minio Access Key to use" default:"insecure-dev-access-key" basic-help:"true"`
@@ -18,12 +20,88 @@ type ServerConfig struct {
 // S3CompatibilityConfig is a configuration struct that determines details about
 // how strict the gateway should be S3-compatible.
 type S3CompatibilityConfig struct {
-	IncludeCustomMetadataListing bool  `help:"include custom metadata in S3's ListObjects, ListObjectsV2 and ListMultipartUploads responses" default:"true"`
-	MaxKeysLimit                 int   `help:"MaxKeys parameter limit for S3's ListObjects and ListObjectsV2 responses" default:"1000"`
-	MaxKeysExhaustiveLimit       int   `help:"maximum number of items to list for gateway-side filtering using arbitrary delimiter/prefix" default:"100000"`
-	MaxUploadsLimit              int   `help:"MaxUploads parameter limit for S3's ListMultipartUploads responses" default:"1000"`
-	FullyCompatibleListing       bool  `help:"make ListObjects(V2) fully S3-compatible (specifically: always return lexicographically ordered results) but slow" default:"false"`
-	DisableCopyObject            bool  `help:"return 501 (Not Implemented) for CopyObject calls" default:"false"`
-	MinPartSize                  int64 `help:"minimum part size for multipart uploads" default:"5242880"` // 5 MiB
-	DeleteObjectsConcurrency     int   `help:"how many objects to delete in parallel with DeleteObjects" default:"100"`
+	IncludeCustomMetadataListing bool          `help:"include custom metadata in S3's ListObjects, ListObjectsV2 and ListMultipartUploads responses" default:"true"`
+	MaxKeysLimit                 int           `help:"MaxKeys parameter limit for S3's ListObjects and ListObjectsV2 responses" default:"1000"`
+	MaxKeysExhaustiveLimit       int           `help:"maximum number of items to hold in memory while listing for gateway-side filtering using arbitrary delimiter/prefix; the rest is spilled to temporary files" default:"100000"`
+	ListingTempDir               string        `help:"directory for temporary files of gateway-side filtered listings (the system default if empty)" default:""`
+	SortedListingTTL             time.Duration `help:"how long sorted results of a gateway-side filtered listing are kept for its next page (set to 0 to list and sort again for every page)" default:"1m"`
+	MaxSortedListings            int           `help:"maximum number of gateway-side filtered listings whose sorted results are kept for their next pages" default:"100"`
+	MaxUploadsLimit              int           `help:"MaxUploads parameter limit for S3's ListMultipartUploads responses" default:"1000"`
+	FullyCompatibleListing       bool          `help:"make ListObjects(V2) fully S3-compatible (specifically: always return lexicographically ordered results) but slow" default:"false"`
+	DisableCopyObject            bool          `help:"return 501 (Not Implemented) for CopyObject calls" default:"false"`
+	MinPartSize                  int64         `help:"minimum part size for multipart uploads" default:"5242880"` // 5 MiB
+	DeleteObjectsConcurrency     int           `help:"how many objects to delete in parallel with DeleteObjects" default:"100"`
+	ListingTokenSecret           string        `help:"secret protecting ListObjectsV2 continuation tokens (derived from the gateway's secret key if empty); instances behind the same endpoint need the same one" default:""`
+	MetadataStorePath            string        `help:"path to the local database of metadata the satellite can't store, e.g., tags of noncurrent object versions, bucket configurations and checksums and sizes of parts; it isn't shared between gateway instances (set to empty to disable)" default:"$CONFDIR/metadata.db"`
+
+	ListingIndex ListingIndexConfig
+	Cache        CacheConfig
+	Compression  CompressionConfig
+	Lifecycle    LifecycleConfig
+	Notification NotificationConfig
+}
+
+// ListingIndexConfig is a configuration struct for the local, persistent index
+// of object keys that allows serving lexicographically ordered listings without
+// listing the entire bucket. Buckets are indexed separately for each project,
+// identified by WithProjectID.
+type ListingIndexConfig struct {
+	Enabled           bool          `help:"serve ListObjects(V2) from a local, lexicographically ordered index of object keys" default:"false"`
+	Path              string        `help:"path to the listing index database" default:"$CONFDIR/listing-index.db"`
+	ReconcileInterval time.Duration `help:"how often the listing index of a bucket is reconciled with the satellite" default:"15m"`
+}
+
+// CacheConfig is a configuration struct for the in-process cache of list
+// pages, object stats and bucket existence. Writes made through the gateway
+// invalidate it, but writes made by other clients are only visible once
+// cached entries expire. Buckets are cached separately for each project,
+// identified by WithProjectID.
+type CacheConfig struct {
+	Enabled      bool          `help:"cache list pages, object stats and bucket existence in memory" default:"false"`
+	ListTTL      time.Duration `help:"how long list pages are cached" default:"10s"`
+	StatTTL      time.Duration `help:"how long object stats are cached" default:"30s"`
+	BucketTTL    time.Duration `help:"how long bucket existence is cached" default:"1m"`
+	MaxListPages int           `help:"maximum number of cached list pages" default:"1000"`
+	MaxStats     int           `help:"maximum number of cached object stats" default:"10000"`
+	MaxBuckets   int           `help:"maximum number of cached buckets" default:"1000"`
+}
+
+// CompressionConfig is a configuration struct for transparent compression of
+// uploaded objects. Compressed objects are listed with their compressed size
+// unless custom metadata is included in listings.
+type CompressionConfig struct {
+	Buckets      []string `help:"buckets whose objects are stored compressed with zstd" default:""`
+	ContentTypes []string `help:"content types (e.g., application/json or text/*) of objects stored compressed with zstd" default:""`
+}
+
+// LifecycleConfig is a configuration struct for enforcing bucket lifecycle
+// configurations on existing objects and multipart uploads.
+type LifecycleConfig struct {
+	EnforceInterval time.Duration `help:"how often lifecycle rules of a bucket are enforced on its existing objects and multipart uploads" default:"24h"`
+}
+
+// NotificationConfig is a configuration struct for sending bucket event
+// notifications to webhooks. Events are queued in the metadata store until
+// they're delivered, so they survive restarts.
+type NotificationConfig struct {
+	Webhooks      []string      `help:"webhook targets of bucket notifications as id=url, referenced by notification configurations as arn:minio:sqs::<id>:webhook" default:""`
+	Timeout       time.Duration `help:"timeout of sending an event to a webhook" default:"10s"`
+	RetryInterval time.Duration `help:"how long to wait before sending an event again after a webhook failed to accept it" default:"30s"`
+	MaxAge        time.Duration `help:"how long events are retried before they're dropped" default:"24h"`
+}
+
+// PublicAccessConfig is a configuration struct that determines which buckets
+// anyone can read from without credentials. Buckets with a bucket policy of
+// their own are governed by it instead.
+type PublicAccessConfig struct {
+	Buckets []string `help:"buckets or bucket/prefix pairs (e.g., assets or docs/public/) whose objects anyone can read" default:""`
+	Listing bool     `help:"allow anyone to list the objects of public buckets and prefixes" default:"false"`
+}
+
+// WebsiteConfig is a configuration struct for serving buckets with a website
+// configuration as static websites.
+type WebsiteConfig struct {
+	Endpoint string   `help:"host name of the website endpoint, which serves websites of buckets at /<bucket>/ (disabled if empty)" default:""`
+	Domains  []string `help:"domains serving the website of a bucket from its root or a prefix as domain=bucket[/prefix] (e.g., docs.example.com=docs-site or *.example.com=sites/www/)" default:""`
+	Listings []string `help:"buckets whose websites list the objects of directories without an index document" default:""`
 }
@@ -0,0 +1,458 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	minio "storj.io/minio/cmd"
+)
+
+// BucketCORSLayer is implemented by object layers that support bucket CORS
+// configurations.
+type BucketCORSLayer interface {
+	GetBucketCORS(ctx context.Context, bucket string) (*BucketCORS, error)
+	SetBucketCORS(ctx context.Context, bucket string, config *BucketCORS) error
+	DeleteBucketCORS(ctx context.Context, bucket string) error
+}
+
+var (
+	_ BucketCORSLayer = (*gatewayLayer)(nil)
+	_ BucketCORSLayer = (*singleTenancyLayer)(nil)
+)
+
+// ErrMalformedCORS is a custom error for CORS configurations that are
+// invalid.
+var ErrMalformedCORS = miniogo.ErrorResponse{
+	Code:       "MalformedXML",
+	StatusCode: http.StatusBadRequest,
+	Message:    "The CORS configuration you provided is not well-formed or did not validate.",
+}
+
+// ErrNoSuchCORSConfiguration is a custom error for buckets without a CORS
+// configuration.
+var ErrNoSuchCORSConfiguration = miniogo.ErrorResponse{
+	Code:       "NoSuchCORSConfiguration",
+	StatusCode: http.StatusNotFound,
+	Message:    "The CORS configuration does not exist",
+}
+
+// errCORSForbidden is the error of preflight requests that no CORS rule
+// allows.
+var errCORSForbidden = minio.APIError{
+	Code:           "AccessForbidden",
+	Description:    "CORSResponse: This CORS request is not allowed. This is usually because the evaluation of Origin, request method / Access-Control-Request-Method or Access-Control-Request-Headers are not whitelisted by the resource's CORS spec.",
+	HTTPStatusCode: http.StatusForbidden,
+}
+
+// storeCORSKey is the metadata store key of the CORS configuration of a
+// bucket.
+var storeCORSKey = []byte("cors")
+
+// maxCORSRules is the maximum number of rules of a CORS configuration.
+const maxCORSRules = 100
+
+// BucketCORS is the CORS configuration of a bucket.
+type BucketCORS struct {
+	XMLName xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CORSConfiguration"`
+	Rules   []CORSRule `xml:"CORSRule"`
+}
+
+// CORSRule is a rule of a CORS configuration. Origins and headers may
+// contain one "*" wildcard.
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  *int     `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// ParseBucketCORS parses and validates a CORS configuration.
+func ParseBucketCORS(r io.Reader) (*BucketCORS, error) {
+	var config struct {
+		XMLName xml.Name
+		Rules   []CORSRule `xml:"CORSRule"`
+	}
+	if err := xml.NewDecoder(r).Decode(&config); err != nil || config.XMLName.Local != "CORSConfiguration" {
+		return nil, ErrMalformedCORS
+	}
+
+	cors := &BucketCORS{Rules: config.Rules}
+	if err := cors.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cors, nil
+}
+
+// Validate returns an error if config is invalid.
+func (config *BucketCORS) Validate() error {
+	if len(config.Rules) == 0 || len(config.Rules) > maxCORSRules {
+		return ErrMalformedCORS
+	}
+
+	for _, rule := range config.Rules {
+		if len(rule.ID) > 255 || len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return ErrMalformedCORS
+		}
+		for _, method := range rule.AllowedMethods {
+			switch method {
+			case http.MethodGet, http.MethodPut, http.MethodHead, http.MethodPost, http.MethodDelete:
+			default:
+				return ErrMalformedCORS
+			}
+		}
+		for _, patterns := range [][]string{rule.AllowedOrigins, rule.AllowedHeaders} {
+			for _, pattern := range patterns {
+				if strings.Count(pattern, "*") > 1 {
+					return ErrMalformedCORS
+				}
+			}
+		}
+		if rule.MaxAgeSeconds != nil && *rule.MaxAgeSeconds < 0 {
+			return ErrMalformedCORS
+		}
+	}
+
+	return nil
+}
+
+// wildcardMatches returns whether value matches pattern, which contains at
+// most one "*" matching any string.
+func wildcardMatches(pattern, value string) bool {
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok {
+		return pattern == value
+	}
+	return len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
+
+// allowsOrigin returns whether rule allows requests from origin.
+func (rule CORSRule) allowsOrigin(origin string) bool {
+	for _, pattern := range rule.AllowedOrigins {
+		if wildcardMatches(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsMethod returns whether rule allows requests using method.
+func (rule CORSRule) allowsMethod(method string) bool {
+	for _, allowed := range rule.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsHeader returns whether rule allows requests with header.
+func (rule CORSRule) allowsHeader(header string) bool {
+	header = strings.ToLower(header)
+	for _, pattern := range rule.AllowedHeaders {
+		if wildcardMatches(strings.ToLower(pattern), header) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the first rule of config allowing a request from origin
+// using method with headers.
+func (config *BucketCORS) match(origin, method string, headers []string) (CORSRule, bool) {
+	for _, rule := range config.Rules {
+		if !rule.allowsOrigin(origin) || !rule.allowsMethod(method) {
+			continue
+		}
+		allowed := true
+		for _, header := range headers {
+			if !rule.allowsHeader(header) {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return rule, true
+		}
+	}
+	return CORSRule{}, false
+}
+
+// setCORSHeaders sets the response headers of a request from origin allowed
+// by rule. preflight is whether the request is a preflight request asking
+// for headers.
+func setCORSHeaders(header http.Header, rule CORSRule, origin string, preflight bool, headers []string) {
+	if len(rule.AllowedOrigins) == 1 && rule.AllowedOrigins[0] == "*" {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+	if len(rule.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ", "))
+	}
+	if preflight {
+		if len(headers) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		}
+		if rule.MaxAgeSeconds != nil {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(*rule.MaxAgeSeconds))
+		}
+	}
+}
+
+// deleteCORSHeaders removes CORS response headers from header.
+func deleteCORSHeaders(header http.Header) {
+	for key := range header {
+		if strings.HasPrefix(strings.ToLower(key), "access-control-") {
+			delete(header, key)
+		}
+	}
+}
+
+// requestHeaders returns the headers in Access-Control-Request-Headers.
+func requestHeaders(header http.Header) (headers []string) {
+	for _, value := range header.Values("Access-Control-Request-Headers") {
+		for _, h := range strings.Split(value, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				headers = append(headers, h)
+			}
+		}
+	}
+	return headers
+}
+
+// requestBucket returns the bucket a path-style request is made to.
+func requestBucket(r *http.Request) string {
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	return bucket
+}
+
+// CORSServer is implemented by gateways that evaluate CORS configurations of
+// buckets.
+type CORSServer interface {
+	CORSHandler(next http.Handler) http.Handler
+}
+
+var (
+	_ CORSServer = (*Gateway)(nil)
+	_ CORSServer = (*singleTenantGateway)(nil)
+)
+
+// CORSHandler is a middleware that evaluates CORS configurations of buckets
+// on preflight and actual requests to them, replacing the CORS headers set
+// by minio, which only supports a global list of allowed origins. Requests
+// to buckets without a configuration are passed on unchanged.
+func (gateway *Gateway) CORSHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		bucket := requestBucket(r)
+		if origin == "" || bucket == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		store, err := gateway.openMetadataStore()
+		if err != nil || store == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		config, err := bucketCORS(r.Context(), store, bucket)
+		if err != nil || config == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		if method := r.Header.Get("Access-Control-Request-Method"); r.Method == http.MethodOptions && method != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			headers := requestHeaders(r.Header)
+			rule, ok := config.match(origin, method, headers)
+			if !ok {
+				minio.WriteErrorResponse(r.Context(), w, errCORSForbidden, r.URL, false)
+				return
+			}
+			setCORSHeaders(w.Header(), rule, origin, true, headers)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		rule, ok := config.match(origin, r.Method, nil)
+		next.ServeHTTP(&corsResponseWriter{
+			ResponseWriter: w,
+			set: func(header http.Header) {
+				deleteCORSHeaders(header)
+				if ok {
+					setCORSHeaders(header, rule, origin, false, nil)
+				}
+			},
+		}, r)
+	})
+}
+
+// CORSHandler evaluates CORS configurations of buckets of the project of the
+// gateway's access using the wrapped gateway if it's a CORSServer.
+func (g *singleTenantGateway) CORSHandler(next http.Handler) http.Handler {
+	server, ok := g.gateway.(CORSServer)
+	if !ok {
+		return next
+	}
+
+	handler := server.CORSHandler(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if layer := g.layer.Load(); layer != nil {
+			r = r.WithContext(WithProjectID(r.Context(), layer.projectID))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// corsResponseWriter replaces the CORS headers of a response right before
+// they're written.
+type corsResponseWriter struct {
+	http.ResponseWriter
+	set         func(http.Header)
+	wroteHeader bool
+}
+
+// WriteHeader sets the CORS headers and writes the response headers.
+func (w *corsResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.set(w.Header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the response body, writing headers first if needed.
+func (w *corsResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush flushes the response if the underlying writer supports it.
+func (w *corsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *corsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// GetBucketCORS returns the CORS configuration of bucket.
+func (layer *gatewayLayer) GetBucketCORS(ctx context.Context, bucketName string) (_ *BucketCORS, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return nil, err
+	}
+
+	if layer.metadataStore == nil {
+		return nil, ErrNoSuchCORSConfiguration
+	}
+
+	config, err := bucketCORS(ctx, layer.metadataStore, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if config == nil {
+		return nil, ErrNoSuchCORSConfiguration
+	}
+
+	return config, nil
+}
+
+// SetBucketCORS sets the CORS configuration of bucket. Rules are kept in the
+// metadata store.
+func (layer *gatewayLayer) SetBucketCORS(ctx context.Context, bucketName string, config *BucketCORS) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "PutBucketCors"}
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	value, err := xml.Marshal(config)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	return ConvertError(layer.metadataStore.put(storeBucketConfig, storeBucket(ctx, bucketName), storeCORSKey, value), bucketName, "")
+}
+
+// DeleteBucketCORS removes the CORS configuration of bucket.
+func (layer *gatewayLayer) DeleteBucketCORS(ctx context.Context, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storeCORSKey), bucketName, "")
+}
+
+// checkBucketConfigRequest returns an error if a request to read or change a
+// configuration of bucket can't proceed, e.g., if the bucket doesn't exist.
+func (layer *gatewayLayer) checkBucketConfigRequest(ctx context.Context, bucketName string) error {
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = project.StatBucket(ctx, bucketName)
+	return ConvertError(err, bucketName, "")
+}
+
+// bucketCORS returns the CORS configuration of bucket in store or nil if it
+// has none.
+func bucketCORS(ctx context.Context, store *metadataStore, bucketName string) (*BucketCORS, error) {
+	value, found, err := store.get(storeBucketConfig, storeBucket(ctx, bucketName), storeCORSKey)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var config BucketCORS
+	if err := xml.Unmarshal(value, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
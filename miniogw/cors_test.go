@@ -0,0 +1,257 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+)
+
+func TestParseBucketCORS(t *testing.T) {
+	config, err := ParseBucketCORS(strings.NewReader(`<CORSConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+		<CORSRule>
+			<AllowedOrigin>https://*.example.com</AllowedOrigin>
+			<AllowedMethod>PUT</AllowedMethod>
+			<AllowedMethod>POST</AllowedMethod>
+			<AllowedHeader>*</AllowedHeader>
+			<ExposeHeader>ETag</ExposeHeader>
+			<MaxAgeSeconds>3000</MaxAgeSeconds>
+		</CORSRule>
+		<CORSRule>
+			<AllowedOrigin>*</AllowedOrigin>
+			<AllowedMethod>GET</AllowedMethod>
+		</CORSRule>
+	</CORSConfiguration>`))
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 2)
+	assert.Equal(t, []string{"PUT", "POST"}, config.Rules[0].AllowedMethods)
+	assert.Equal(t, 3000, *config.Rules[0].MaxAgeSeconds)
+
+	for _, body := range []string{
+		`not xml`,
+		`<CORSConfiguration></CORSConfiguration>`,
+		`<CORSConfiguration><CORSRule><AllowedMethod>GET</AllowedMethod></CORSRule></CORSConfiguration>`,
+		`<CORSConfiguration><CORSRule><AllowedOrigin>*</AllowedOrigin></CORSRule></CORSConfiguration>`,
+		`<CORSConfiguration><CORSRule><AllowedOrigin>*</AllowedOrigin><AllowedMethod>PATCH</AllowedMethod></CORSRule></CORSConfiguration>`,
+		`<CORSConfiguration><CORSRule><AllowedOrigin>*.*</AllowedOrigin><AllowedMethod>GET</AllowedMethod></CORSRule></CORSConfiguration>`,
+	} {
+		_, err := ParseBucketCORS(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrMalformedCORS, body)
+	}
+}
+
+func TestBucketCORSMatch(t *testing.T) {
+	assert.True(t, wildcardMatches("https://*.example.com", "https://app.example.com"))
+	assert.False(t, wildcardMatches("https://*.example.com", "https://example.com"))
+	assert.True(t, wildcardMatches("*", ""))
+	assert.False(t, wildcardMatches("https://example.com", "http://example.com"))
+
+	config := &BucketCORS{Rules: []CORSRule{
+		{ID: "upload", AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"PUT"}, AllowedHeaders: []string{"content-*", "x-amz-*"}},
+		{ID: "read", AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET", "HEAD"}},
+	}}
+
+	rule, ok := config.match("https://app.example.com", "PUT", []string{"Content-Type", "X-Amz-Date"})
+	require.True(t, ok)
+	assert.Equal(t, "upload", rule.ID)
+
+	_, ok = config.match("https://app.example.com", "PUT", []string{"Authorization"})
+	assert.False(t, ok)
+
+	_, ok = config.match("https://other.example.com", "PUT", nil)
+	assert.False(t, ok)
+
+	rule, ok = config.match("https://other.example.com", "GET", nil)
+	require.True(t, ok)
+	assert.Equal(t, "read", rule.ID)
+}
+
+func TestCORSHandler(t *testing.T) {
+	gateway := NewStorjGateway(S3CompatibilityConfig{MetadataStorePath: filepath.Join(t.TempDir(), "metadata.db")})
+	defer func() { require.NoError(t, gateway.Close()) }()
+
+	store, err := gateway.openMetadataStore()
+	require.NoError(t, err)
+
+	maxAge := 600
+	value, err := xml.Marshal(&BucketCORS{Rules: []CORSRule{
+		{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"GET", "PUT"}, AllowedHeaders: []string{"*"}, ExposeHeaders: []string{"ETag"}, MaxAgeSeconds: &maxAge},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeBucketConfig, projectBucket{name: "web"}, storeCORSKey, value))
+
+	var called bool
+	handler := gateway.CORSHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		// minio allows all origins by default.
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		called = false
+		r := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// Allowed preflight requests are answered by the handler.
+	w := request(http.MethodOptions, "/web/index.html", map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "content-type, x-amz-date",
+	})
+	assert.False(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, PUT", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "content-type, x-amz-date", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = request(http.MethodOptions, "/web/index.html", map[string]string{
+		"Origin":                        "https://evil.example.com",
+		"Access-Control-Request-Method": "PUT",
+	})
+	assert.False(t, called)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Actual requests get the headers of the matching rule only.
+	w = request(http.MethodGet, "/web/index.html", map[string]string{"Origin": "https://app.example.com"})
+	assert.True(t, called)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "ETag", w.Header().Get("Access-Control-Expose-Headers"))
+
+	w = request(http.MethodGet, "/web/index.html", map[string]string{"Origin": "https://evil.example.com"})
+	assert.True(t, called)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Buckets without a configuration are left to minio.
+	w = request(http.MethodGet, "/other/index.html", map[string]string{"Origin": "https://evil.example.com"})
+	assert.True(t, called)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// corsTestLayer is an object layer keeping CORS configurations in a metadata
+// store, like gatewayLayer does.
+type corsTestLayer struct {
+	minio.ObjectLayer
+
+	store *metadataStore
+}
+
+func (layer *corsTestLayer) GetBucketCORS(ctx context.Context, bucket string) (*BucketCORS, error) {
+	config, err := bucketCORS(ctx, layer.store, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, ErrNoSuchCORSConfiguration
+	}
+	return config, nil
+}
+
+func (layer *corsTestLayer) SetBucketCORS(ctx context.Context, bucket string, config *BucketCORS) error {
+	value, err := xml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return layer.store.put(storeBucketConfig, storeBucket(ctx, bucket), storeCORSKey, value)
+}
+
+func (layer *corsTestLayer) DeleteBucketCORS(ctx context.Context, bucket string) error {
+	return layer.store.delete(storeBucketConfig, storeBucket(ctx, bucket), storeCORSKey)
+}
+
+func TestBucketCORSAPI(t *testing.T) {
+	gateway := NewStorjGateway(S3CompatibilityConfig{MetadataStorePath: filepath.Join(t.TempDir(), "metadata.db")})
+	defer func() { require.NoError(t, gateway.Close()) }()
+
+	store, err := gateway.openMetadataStore()
+	require.NoError(t, err)
+
+	// minio-go has no CORS API, so requests are signed directly.
+	singleTenant := &singleTenantGateway{gateway: gateway.WithLogger(zap.NewNop().Sugar())}
+	singleTenant.layer.Store(&singleTenancyLayer{logger: zap.NewNop(), layer: &corsTestLayer{store: store}})
+	_, url := startGatewayAPITestServer(t, singleTenant)
+
+	ctx := context.Background()
+
+	resp := signedRequest(t, http.MethodGet, url+"/web?cors", nil, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = signedRequest(t, http.MethodPut, url+"/web?cors", nil, `<CORSConfiguration>
+		<CORSRule>
+			<AllowedOrigin>https://app.example.com</AllowedOrigin>
+			<AllowedMethod>PUT</AllowedMethod>
+			<AllowedHeader>*</AllowedHeader>
+		</CORSRule>
+	</CORSConfiguration>`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = signedRequest(t, http.MethodGet, url+"/web?cors", nil, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	config, err := ParseBucketCORS(resp.Body)
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 1)
+	assert.Equal(t, []string{"https://app.example.com"}, config.Rules[0].AllowedOrigins)
+
+	resp = signedRequest(t, http.MethodPut, url+"/web?cors", nil, `<CORSConfiguration></CORSConfiguration>`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	preflight := func(origin string) *http.Response {
+		r, err := http.NewRequestWithContext(ctx, http.MethodOptions, url+"/web/index.html", nil)
+		require.NoError(t, err)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", "PUT")
+		r.Header.Set("Access-Control-Request-Headers", "content-type")
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	// The configuration set through the API answers preflight requests.
+	resp = preflight("https://app.example.com")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "content-type", resp.Header.Get("Access-Control-Allow-Headers"))
+
+	resp = preflight("https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = signedRequest(t, http.MethodDelete, url+"/web?cors", nil, "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = signedRequest(t, http.MethodGet, url+"/web?cors", nil, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Without a configuration, preflight requests are left to minio.
+	resp = preflight("https://evil.example.com")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The API needs a valid signature.
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/web?cors", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
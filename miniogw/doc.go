@@ -0,0 +1,21 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package miniogw implements a minio gateway to Storj.
+//
+// minio routes S3 requests to the methods of minio.ObjectLayer and rejects or
+// drops everything it doesn't know about. Features beyond that reach the
+// object layer in three ways, all of which are set up by RegisterHandlers and
+// Server:
+//
+//   - Request headers minio doesn't pass down, e.g., write preconditions or
+//     checksums, are injected into the request context by middlewares added
+//     to minio.GlobalHandlers, and the object layer reads them from there.
+//   - APIs minio rejects, e.g., bucket lifecycle configurations, are served
+//     by a middleware added to minio.GlobalHandlers, which calls the optional
+//     interfaces of the object layer, e.g., BucketLifecycleLayer, found using
+//     type assertions.
+//   - Requests minio answers before its routes, i.e., CORS preflight requests,
+//     and requests to website endpoints are served in front of minio, which
+//     listens at an internal address.
+package miniogw
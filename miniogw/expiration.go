@@ -0,0 +1,160 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/uplink"
+)
+
+type metadataDirectiveKey struct{}
+
+// WithMetadataDirective injects whether the copy request with header
+// replaces the metadata of the source object into ctx.
+func WithMetadataDirective(ctx context.Context, header http.Header) context.Context {
+	replace := strings.EqualFold(header.Get(xhttp.AmzMetadataDirective), "REPLACE")
+	return context.WithValue(ctx, metadataDirectiveKey{}, replace)
+}
+
+// MetadataDirectiveHandler is a middleware that passes the metadata
+// directive of copy requests down to the object layer. minio only passes the
+// resulting metadata, so without it same-key copies can't always tell a new
+// expiration from the one the object was uploaded with.
+func MetadataDirectiveHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.Header.Get(xhttp.AmzCopySource) != "" {
+			r = r.WithContext(WithMetadataDirective(r.Context(), r.Header))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func getMetadataDirective(ctx context.Context) (replace, ok bool) {
+	replace, ok = ctx.Value(metadataDirectiveKey{}).(bool)
+	return replace, ok
+}
+
+// expirationChange returns the expiration requested by a same-key copy of an
+// object with existing metadata, where metadata is the metadata of the copy
+// prepared by minio. changed is false if the copy doesn't ask for a new
+// expiration. Without the metadata directive in ctx, a new expiration is
+// only recognized if its value differs from the one the object has.
+func expirationChange(ctx context.Context, metadata map[string]string, existing uplink.CustomMetadata) (expires time.Time, changed bool, err error) {
+	for _, alias := range objectTTLKeyAliases {
+		value, ok := metadata[alias]
+		if !ok {
+			continue
+		}
+
+		if replace, ok := getMetadataDirective(ctx); ok {
+			changed = replace
+		} else {
+			changed = value != existing[alias]
+		}
+		if !changed {
+			return time.Time{}, false, nil
+		}
+
+		expires, err = parseTTL(metadata)
+		return expires, true, err
+	}
+
+	return time.Time{}, false, nil
+}
+
+// reuploadMetadata returns the metadata of an object with existing metadata
+// uploaded again with metadata prepared by minio for a same-key copy. Tags
+// are handled like in upsertObjectMetadata; the ETag and the way data is
+// stored are determined by the new upload.
+func reuploadMetadata(metadata map[string]string, existing uplink.CustomMetadata) map[string]string {
+	reupload := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		reupload[k] = v
+	}
+
+	upsertObjectMetadata(reupload, existing)
+
+	return withoutUploadMetadata(reupload)
+}
+
+// withoutUploadMetadata removes the metadata determined by the upload of an
+// object from metadata and returns it, so that the object's data can be
+// uploaded again with it: the ETag and the way data is stored.
+func withoutUploadMetadata(metadata map[string]string) map[string]string {
+	delete(metadata, "s3:etag")
+	for _, key := range compressionMetadataKeys {
+		delete(metadata, key)
+	}
+	return metadata
+}
+
+// changeObjectExpiration gives the latest version of the object at key the
+// expiration in metadata prepared by minio for a same-key copy. The
+// satellite can't change the expiration of an existing object, so the
+// object's data is uploaded again from the copy source, which creates a new
+// version in versioned buckets.
+func (layer *gatewayLayer) changeObjectExpiration(ctx context.Context, bucketName, key string, existing *uplink.Object, srcInfo minio.ObjectInfo) (_ minio.ObjectInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if srcInfo.PutObjReader == nil {
+		return minio.ObjectInfo{}, minio.NotImplemented{Message: "CopyObject (expiration)"}
+	}
+
+	return layer.PutObject(ctx, bucketName, key, srcInfo.PutObjReader, minio.ObjectOptions{
+		UserDefined: reuploadMetadata(srcInfo.UserDefined, existing.Custom),
+		NoLock:      true,
+	})
+}
+
+// expirationHeader returns the value of the x-amz-expiration header of an
+// object expiring at expires because of the lifecycle rule ruleID, which is
+// empty if the object expires because of its own expiration time.
+func expirationHeader(expires time.Time, ruleID string) string {
+	header := fmt.Sprintf(`expiry-date="%s"`, expires.UTC().Format(http.TimeFormat))
+	if ruleID != "" {
+		header += fmt.Sprintf(`, rule-id="%s"`, ruleID)
+	}
+	return header
+}
+
+// withExpirationHeader returns info with when the object expires among
+// metadata returned as headers. Lifecycle rules of the bucket are considered
+// only if info describes the latest version, as they expire only these.
+// Copies don't inherit the header, as CopyObject removes it.
+func (layer *gatewayLayer) withExpirationHeader(ctx context.Context, info minio.ObjectInfo, latest bool) minio.ObjectInfo {
+	expires, ruleID := info.Expires, ""
+
+	if latest && !info.DeleteMarker {
+		config, err := layer.bucketLifecycle(ctx, info.Bucket)
+		if err != nil {
+			layer.logger.Infof("lifecycle: reading configuration of %q failed: %v", info.Bucket, err)
+		}
+		if config != nil {
+			t, id := config.expiration(info.Name, info.UserDefined["s3:tags"], info.Size, info.ModTime)
+			if !t.IsZero() && (expires.IsZero() || !expires.Before(t)) {
+				expires, ruleID = t, id
+			}
+		}
+	}
+
+	if expires.IsZero() {
+		return info
+	}
+
+	userDefined := make(map[string]string, len(info.UserDefined)+1)
+	for k, v := range info.UserDefined {
+		userDefined[k] = v
+	}
+	userDefined[xhttp.AmzExpiration] = expirationHeader(expires, ruleID)
+	info.UserDefined = userDefined
+
+	return info
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/minio/pkg/bucket/lifecycle"
+	"storj.io/uplink"
+)
+
+func TestMetadataDirectiveHandler(t *testing.T) {
+	var replace, ok bool
+	handler := MetadataDirectiveHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		replace, ok = getMetadataDirective(r.Context())
+	}))
+
+	request := func(method string, header map[string]string) {
+		r := httptest.NewRequest(method, "/bucket/key", nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	request(http.MethodPut, map[string]string{"X-Amz-Copy-Source": "bucket/key", "X-Amz-Metadata-Directive": "REPLACE"})
+	require.True(t, ok)
+	assert.True(t, replace)
+
+	request(http.MethodPut, map[string]string{"X-Amz-Copy-Source": "bucket/key"})
+	require.True(t, ok)
+	assert.False(t, replace)
+
+	request(http.MethodPut, map[string]string{"X-Amz-Metadata-Directive": "REPLACE"})
+	assert.False(t, ok)
+}
+
+func TestExpirationChange(t *testing.T) {
+	existing := uplink.CustomMetadata{"X-Amz-Meta-Object-Expires": "2030-01-01T00:00:00Z"}
+	replace := WithMetadataDirective(context.Background(), http.Header{"X-Amz-Metadata-Directive": {"REPLACE"}})
+	keep := WithMetadataDirective(context.Background(), http.Header{})
+
+	_, changed, err := expirationChange(replace, map[string]string{"content-type": "text/plain"}, existing)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	expires, changed, err := expirationChange(replace, map[string]string{"X-Amz-Meta-Object-Expires": "2031-01-01T00:00:00Z"}, existing)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC), expires)
+
+	expires, changed, err = expirationChange(replace, map[string]string{"X-Amz-Meta-Object-Expires": "none"}, existing)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, expires.IsZero())
+
+	// The same relative expiration extends it again.
+	expires, changed, err = expirationChange(replace, map[string]string{"X-Amz-Meta-Object-Expires": "+1h"}, uplink.CustomMetadata{"X-Amz-Meta-Object-Expires": "+1h"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	// Metadata copied from the source object doesn't change anything.
+	_, changed, err = expirationChange(keep, map[string]string{"X-Amz-Meta-Object-Expires": "+1h"}, existing)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Without the directive, only a different value is a change.
+	_, changed, err = expirationChange(context.Background(), map[string]string(existing), existing)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, changed, err = expirationChange(context.Background(), map[string]string{"X-Amz-Meta-Object-Expires": "+1h"}, existing)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	_, _, err = expirationChange(replace, map[string]string{"X-Amz-Meta-Object-Expires": "tomorrow"}, existing)
+	require.Error(t, err)
+}
+
+func TestReuploadMetadata(t *testing.T) {
+	existing := uplink.CustomMetadata{
+		"s3:etag":                         "abc-2",
+		"s3:tags":                         "a=b",
+		checksumMetadataKey(checksumSHA1): "sum",
+		compressionMetadataKey:            compressionZstd,
+		uncompressedSizeMetadataKey:       "10",
+	}
+	metadata := map[string]string{"X-Amz-Meta-Object-Expires": "none"}
+
+	assert.Equal(t, map[string]string{
+		"X-Amz-Meta-Object-Expires":       "none",
+		"s3:tags":                         "a=b",
+		checksumMetadataKey(checksumSHA1): "sum",
+	}, reuploadMetadata(metadata, existing))
+	assert.Len(t, metadata, 1)
+}
+
+func TestWithExpirationHeader(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	info := minio.ObjectInfo{Bucket: "bucket", Name: "tmp/a", ModTime: created, UserDefined: map[string]string{"a": "b"}}
+
+	assert.Equal(t, info, layer.withExpirationHeader(ctx, info, true))
+
+	info.Expires = expires
+	withHeader := layer.withExpirationHeader(ctx, info, true)
+	assert.Equal(t, `expiry-date="Mon, 05 Jan 2026 00:00:00 GMT"`, withHeader.UserDefined[xhttp.AmzExpiration])
+	assert.NotContains(t, info.UserDefined, xhttp.AmzExpiration)
+
+	prefix := "tmp/"
+	value, err := xml.Marshal(&BucketLifecycle{Rules: []LifecycleRule{
+		{ID: "tmp", Status: lifecycle.Enabled, Prefix: &prefix, Expiration: &LifecycleExpiration{Days: 1}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeBucketConfig, projectBucket{name: "bucket"}, storeLifecycleKey, value))
+
+	// The lifecycle rule expires the object earlier.
+	withHeader = layer.withExpirationHeader(ctx, info, true)
+	assert.Equal(t, `expiry-date="Sat, 03 Jan 2026 00:00:00 GMT", rule-id="tmp"`, withHeader.UserDefined[xhttp.AmzExpiration])
+
+	// Lifecycle rules don't expire noncurrent versions this way.
+	withHeader = layer.withExpirationHeader(ctx, info, false)
+	assert.Equal(t, `expiry-date="Mon, 05 Jan 2026 00:00:00 GMT"`, withHeader.UserDefined[xhttp.AmzExpiration])
+}
@@ -6,12 +6,19 @@ package miniogw
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
-	"sort"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -32,6 +39,7 @@ import (
 	"storj.io/private/version"
 	"storj.io/uplink"
 	"storj.io/uplink/private/bucket"
+	"storj.io/uplink/private/metaclient"
 	uo "storj.io/uplink/private/object"
 	versioned "storj.io/uplink/private/object"
 )
@@ -101,6 +109,54 @@ var (
 		Message:    "Please reduce your request rate.",
 	}
 
+	// ErrObjectLocked is a custom error for when an object version can't be
+	// deleted or its retention can't be changed because of Object Lock.
+	ErrObjectLocked = miniogo.ErrorResponse{
+		Code:       "AccessDenied",
+		StatusCode: http.StatusForbidden,
+		Message:    "Access Denied because object protected by object lock.",
+	}
+
+	// ErrNoSuchObjectLockConfiguration is a custom error for when an object
+	// version has no retention.
+	ErrNoSuchObjectLockConfiguration = miniogo.ErrorResponse{
+		Code:       "NoSuchObjectLockConfiguration",
+		StatusCode: http.StatusNotFound,
+		Message:    "The specified object does not have a ObjectLock configuration.",
+	}
+
+	// ErrObjectLockModeNotSupported is a custom error for retention in
+	// governance mode, which the satellite doesn't support.
+	ErrObjectLockModeNotSupported = miniogo.ErrorResponse{
+		Code:       "NotImplemented",
+		StatusCode: http.StatusNotImplemented,
+		Message:    "Only retention in COMPLIANCE mode is supported.",
+	}
+
+	// ErrLegalHoldNotSupported is a custom error for placing legal holds,
+	// which the satellite doesn't support.
+	ErrLegalHoldNotSupported = miniogo.ErrorResponse{
+		Code:       "NotImplemented",
+		StatusCode: http.StatusNotImplemented,
+		Message:    "Legal holds are not supported.",
+	}
+
+	// ErrObjectLockHeadersNotSupported is a custom error for uploads and
+	// copies requesting Object Lock using headers.
+	ErrObjectLockHeadersNotSupported = miniogo.ErrorResponse{
+		Code:       "NotImplemented",
+		StatusCode: http.StatusNotImplemented,
+		Message:    "Object Lock headers are not supported. Use a default retention rule of the bucket or PutObjectRetention instead.",
+	}
+
+	// ErrVersioningLocked is a custom error for when suspending versioning of
+	// a bucket with Object Lock enabled is requested.
+	ErrVersioningLocked = miniogo.ErrorResponse{
+		Code:       "InvalidBucketState",
+		StatusCode: http.StatusConflict,
+		Message:    "An Object Lock configuration is present on this bucket, so the versioning state cannot be changed.",
+	}
+
 	// ErrNoUplinkProject is a custom error that indicates there was no
 	// `*uplink.Project` in the context for the gateway to pick up. This error
 	// may signal that passing credentials down to the object layer is working
@@ -110,6 +166,9 @@ var (
 	// ErrTooManyItemsToList indicates that ListObjects/ListObjectsV2 failed
 	// because of too many items to list for gateway-side filtering using an
 	// arbitrary delimiter and/or prefix.
+	//
+	// Deprecated: exhaustive listings spill to temporary files and are no
+	// longer limited.
 	ErrTooManyItemsToList = minio.NotImplemented{
 		Message: "ListObjects(V2): listing too many items for gateway-side filtering using arbitrary delimiter/prefix",
 	}
@@ -118,12 +177,29 @@ var (
 // Gateway is the implementation of cmd.Gateway.
 type Gateway struct {
 	compatibilityConfig S3CompatibilityConfig
+
+	cache          *objectCache
+	objectListings *sortedListings[uplink.Object]
+	uploadListings *sortedListings[uplink.UploadInfo]
+	writeLocks     *keyMutex
+	lifecycle      *lifecycleWorker
+
+	mu              sync.Mutex
+	listingIndex    *listingIndex
+	listingTokenKey []byte
+	metadataStore   *metadataStore
+	notifications   *notificationWorker
 }
 
 // NewStorjGateway creates a new Storj S3 gateway.
 func NewStorjGateway(compatibilityConfig S3CompatibilityConfig) *Gateway {
 	return &Gateway{
 		compatibilityConfig: compatibilityConfig,
+		cache:               newObjectCache(compatibilityConfig.Cache),
+		objectListings:      newSortedListings[uplink.Object](compatibilityConfig.SortedListingTTL, compatibilityConfig.MaxSortedListings),
+		uploadListings:      newSortedListings[uplink.UploadInfo](compatibilityConfig.SortedListingTTL, compatibilityConfig.MaxSortedListings),
+		writeLocks:          newKeyMutex(),
+		lifecycle:           newLifecycleWorker(compatibilityConfig.Lifecycle),
 	}
 }
 
@@ -134,12 +210,177 @@ func (gateway *Gateway) Name() string {
 
 // NewGatewayLayer implements cmd.Gateway.
 func (gateway *Gateway) NewGatewayLayer(logger debugLogger, creds auth.Credentials) (minio.ObjectLayer, error) {
+	index, err := gateway.openListingIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenKey, err := gateway.getListingTokenKey(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := gateway.openMetadataStore()
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := gateway.startNotifications(store, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &gatewayLayer{
 		logger:              logger,
 		compatibilityConfig: gateway.compatibilityConfig,
+		cache:               gateway.cache,
+		objectListings:      gateway.objectListings,
+		uploadListings:      gateway.uploadListings,
+		writeLocks:          gateway.writeLocks,
+		lifecycle:           gateway.lifecycle,
+		listingIndex:        index,
+		listingTokenKey:     tokenKey,
+		metadataStore:       store,
+		notifications:       notifications,
 	}, nil
 }
 
+// WithLogger returns gateway as a minio.Gateway whose layers log using
+// logger.
+func (gateway *Gateway) WithLogger(logger debugLogger) minio.Gateway {
+	return loggingGateway{Gateway: gateway, logger: logger}
+}
+
+// loggingGateway is a Gateway whose layers log using logger.
+type loggingGateway struct {
+	*Gateway
+	logger debugLogger
+}
+
+// NewGatewayLayer implements cmd.Gateway.
+func (gateway loggingGateway) NewGatewayLayer(creds auth.Credentials) (minio.ObjectLayer, error) {
+	return gateway.Gateway.NewGatewayLayer(gateway.logger, creds)
+}
+
+// getListingTokenKey returns the key protecting continuation tokens. It's
+// derived from the configured secret or, if there's none, from the secret
+// key of creds, so that gateway instances sharing credentials accept each
+// other's tokens. It's generated randomly only if neither is set.
+func (gateway *Gateway) getListingTokenKey(creds auth.Credentials) ([]byte, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	if gateway.listingTokenKey != nil {
+		return gateway.listingTokenKey, nil
+	}
+
+	if secret := gateway.compatibilityConfig.ListingTokenSecret; secret != "" {
+		key := sha256.Sum256([]byte(secret))
+		gateway.listingTokenKey = key[:]
+		return gateway.listingTokenKey, nil
+	}
+
+	if creds.SecretKey != "" {
+		// The key is derived so that tokens don't reveal anything about
+		// the secret key.
+		mac := hmac.New(sha256.New, []byte(creds.SecretKey))
+		_, _ = mac.Write([]byte("listing token key"))
+		gateway.listingTokenKey = mac.Sum(nil)
+		return gateway.listingTokenKey, nil
+	}
+
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	gateway.listingTokenKey = key
+
+	return gateway.listingTokenKey, nil
+}
+
+// openListingIndex opens the listing index on first use if it's enabled. The
+// index is shared by all layers created by gateway.
+func (gateway *Gateway) openListingIndex() (_ *listingIndex, err error) {
+	if !gateway.compatibilityConfig.ListingIndex.Enabled {
+		return nil, nil
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	if gateway.listingIndex == nil {
+		gateway.listingIndex, err = openListingIndex(gateway.compatibilityConfig.ListingIndex)
+	}
+
+	return gateway.listingIndex, err
+}
+
+// openMetadataStore opens the metadata store on first use if it's enabled. The
+// store is shared by all layers created by gateway.
+func (gateway *Gateway) openMetadataStore() (_ *metadataStore, err error) {
+	if gateway.compatibilityConfig.MetadataStorePath == "" {
+		return nil, nil
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	if gateway.metadataStore == nil {
+		gateway.metadataStore, err = openMetadataStore(gateway.compatibilityConfig.MetadataStorePath)
+	}
+
+	return gateway.metadataStore, err
+}
+
+// startNotifications starts sending bucket event notifications on first use
+// if any webhooks are configured. Events are queued in store, so
+// notifications are disabled without it.
+func (gateway *Gateway) startNotifications(store *metadataStore, logger debugLogger) (_ *notificationWorker, err error) {
+	if len(gateway.compatibilityConfig.Notification.Webhooks) == 0 || store == nil {
+		return nil, nil
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	if gateway.notifications == nil {
+		gateway.notifications, err = startNotificationWorker(gateway.compatibilityConfig.Notification, store, logger)
+	}
+
+	return gateway.notifications, err
+}
+
+// Close releases resources held by the gateway, e.g., the listing index, the
+// metadata store and temporary files of kept listings.
+func (gateway *Gateway) Close() error {
+	// Lifecycle enforcement uses the metadata store, so it's stopped first.
+	gateway.lifecycle.Close()
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	var group errs.Group
+
+	group.Add(gateway.objectListings.Close(), gateway.uploadListings.Close())
+
+	if gateway.listingIndex != nil {
+		group.Add(gateway.listingIndex.Close())
+		gateway.listingIndex = nil
+	}
+
+	// Notifications are queued in the metadata store, so they're stopped
+	// before it's closed.
+	gateway.notifications.Close()
+	gateway.notifications = nil
+
+	if gateway.metadataStore != nil {
+		group.Add(gateway.metadataStore.Close())
+		gateway.metadataStore = nil
+	}
+
+	return group.Err()
+}
+
 // Production implements cmd.Gateway.
 func (gateway *Gateway) Production() bool {
 	return version.Build.Release
@@ -149,6 +390,16 @@ type gatewayLayer struct {
 	logger debugLogger
 	minio.GatewayUnsupported
 	compatibilityConfig S3CompatibilityConfig
+
+	cache           *objectCache
+	objectListings  *sortedListings[uplink.Object]
+	uploadListings  *sortedListings[uplink.UploadInfo]
+	writeLocks      *keyMutex
+	lifecycle       *lifecycleWorker
+	listingIndex    *listingIndex
+	listingTokenKey []byte
+	metadataStore   *metadataStore
+	notifications   *notificationWorker
 }
 
 type debugLogger interface {
@@ -182,9 +433,25 @@ func (layer *gatewayLayer) MakeBucketWithLocation(ctx context.Context, bucket st
 		return err
 	}
 
-	_, err = project.CreateBucket(ctx, bucket)
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateBucket(cacheBucket)
 
-	return ConvertError(err, bucket, "")
+	if opts.LockEnabled {
+		err = createBucketWithObjectLock(ctx, project, bucket)
+	} else {
+		_, err = project.CreateBucket(ctx, bucket)
+	}
+	if err != nil {
+		return ConvertError(err, bucket, "")
+	}
+
+	if index, ok := layer.indexBucket(ctx, bucket); ok {
+		if err := layer.listingIndex.markEmpty(index); err != nil {
+			layer.logger.Infof("listing index: indexing new bucket %q failed: %v", bucket, err)
+		}
+	}
+
+	return nil
 }
 
 func (layer *gatewayLayer) GetBucketInfo(ctx context.Context, bucketName string) (bucketInfo minio.BucketInfo, err error) {
@@ -199,15 +466,26 @@ func (layer *gatewayLayer) GetBucketInfo(ctx context.Context, bucketName string)
 		return minio.BucketInfo{}, err
 	}
 
+	cache, cacheBucket := layer.bucketCache(ctx, bucketName)
+	if info, ok := cache.getBucket(cacheBucket); ok {
+		return info, nil
+	}
+
+	generation := cache.generation(cacheBucket)
+
 	bucket, err := project.StatBucket(ctx, bucketName)
 	if err != nil {
 		return minio.BucketInfo{}, ConvertError(err, bucketName, "")
 	}
 
-	return minio.BucketInfo{
+	bucketInfo = minio.BucketInfo{
 		Name:    bucket.Name,
 		Created: bucket.Created,
-	}, nil
+	}
+
+	cache.putBucket(cacheBucket, generation, bucketInfo)
+
+	return bucketInfo, nil
 }
 
 func (layer *gatewayLayer) ListBuckets(ctx context.Context) (items []minio.BucketInfo, err error) {
@@ -244,7 +522,32 @@ func (layer *gatewayLayer) DeleteBucket(ctx context.Context, bucket string, forc
 		return err
 	}
 
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateBucket(cacheBucket)
+
+	defer func() {
+		if index, ok := layer.indexBucket(ctx, bucket); err == nil && ok {
+			if dropErr := layer.listingIndex.dropBucket(index); dropErr != nil {
+				layer.logger.Infof("listing index: dropping index of %q failed: %v", bucket, dropErr)
+			}
+		}
+		if err == nil && layer.metadataStore != nil {
+			if dropErr := layer.metadataStore.dropBucket(storeBucket(ctx, bucket)); dropErr != nil {
+				layer.logger.Infof("metadata store: dropping metadata of %q failed: %v", bucket, dropErr)
+			}
+		}
+	}()
+
 	if forceDelete {
+		// Deleting the bucket with its objects would bypass Object Lock.
+		enabled, err := bucketObjectLockEnabled(ctx, project, bucket)
+		if err != nil {
+			return ConvertError(err, bucket, "")
+		}
+		if enabled {
+			return ErrObjectLocked
+		}
+
 		_, err = project.DeleteBucketWithObjects(ctx, bucket)
 		return ConvertError(err, bucket, "")
 	}
@@ -269,9 +572,8 @@ func (layer *gatewayLayer) DeleteBucket(ctx context.Context, bucket string, forc
 func (layer *gatewayLayer) listObjectsFast(
 	ctx context.Context,
 	project *uplink.Project,
-	bucket, prefix, continuationToken, delimiter string,
+	bucket, prefix, after, delimiter string,
 	maxKeys int,
-	startAfter string,
 ) (
 	prefixes []string,
 	objects []minio.ObjectInfo,
@@ -280,12 +582,6 @@ func (layer *gatewayLayer) listObjectsFast(
 ) {
 	defer mon.Task()(&ctx)(&err)
 
-	after := startAfter
-
-	if continuationToken != "" {
-		after = continuationToken
-	}
-
 	recursive := delimiter == ""
 
 	list := project.ListObjects(ctx, bucket, &uplink.ListObjectsOptions{
@@ -326,9 +622,8 @@ func (layer *gatewayLayer) listObjectsFast(
 func (layer *gatewayLayer) listObjectsSingle(
 	ctx context.Context,
 	project *uplink.Project,
-	bucket, prefix, continuationToken, delimiter string,
+	bucket, prefix, after, delimiter string,
 	maxKeys int,
-	startAfter string,
 ) (
 	prefixes []string,
 	objects []minio.ObjectInfo,
@@ -337,12 +632,6 @@ func (layer *gatewayLayer) listObjectsSingle(
 ) {
 	defer mon.Task()(&ctx)(&err)
 
-	after := startAfter
-
-	if continuationToken != "" {
-		after = continuationToken
-	}
-
 	limit := limitResults(maxKeys, layer.compatibilityConfig.MaxKeysLimit)
 
 	if limit > 0 && after == "" {
@@ -407,32 +696,31 @@ func collapseKey(prefix, delimiter, key string) (commonPrefix string, collapsed
 	return "", false
 }
 
-// itemsToPrefixesAndObjects dispatches items into prefixes and objects. It
-// collapses all keys into common prefixes that share a path between prefix and
-// delimiter. If there are more items than the limit, nextContinuationToken will
-// be the last non-truncated item.
+// itemsToPrefixesAndObjects dispatches items into prefixes and objects. Items
+// must be sorted by key. It collapses all keys into common prefixes that share
+// a path between prefix and delimiter. If there are more items than the limit,
+// nextContinuationToken will be the last non-truncated item.
 func (layer *gatewayLayer) itemsToPrefixesAndObjects(
-	items []*uplink.Object,
+	items objectIterator,
 	bucket, prefix, delimiter string,
 	maxKeys int,
 ) (
 	prefixes []string,
 	objects []minio.ObjectInfo,
 	nextContinuationToken string,
+	err error,
 ) {
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Key < items[j].Key
-	})
-
 	limit := limitResults(maxKeys, layer.compatibilityConfig.MaxKeysLimit)
 	prefixesLookup := make(map[string]struct{})
 
-	for _, item := range items {
+	for items.Next() {
+		item := items.Item()
+
 		// There's a possibility that we managed to get libuplink to get the
 		// necessary prefixes for us. If that's true, just add it.
 		if item.IsPrefix {
 			if limit == 0 {
-				return prefixes, objects, nextContinuationToken
+				return prefixes, objects, nextContinuationToken, nil
 			}
 			prefixes = append(prefixes, item.Key)
 			nextContinuationToken = item.Key
@@ -444,7 +732,7 @@ func (layer *gatewayLayer) itemsToPrefixesAndObjects(
 		// If we cannot roll up into CommonPrefix, just add the key.
 		if !ok {
 			if limit == 0 {
-				return prefixes, objects, nextContinuationToken
+				return prefixes, objects, nextContinuationToken, nil
 			}
 			objects = append(objects, minioObjectInfo(bucket, "", item))
 			nextContinuationToken = item.Key
@@ -456,7 +744,7 @@ func (layer *gatewayLayer) itemsToPrefixesAndObjects(
 		// as we can't have two same common prefixes in the output.
 		if _, ok := prefixesLookup[commonPrefix]; !ok {
 			if limit == 0 {
-				return prefixes, objects, nextContinuationToken
+				return prefixes, objects, nextContinuationToken, nil
 			}
 			prefixesLookup[commonPrefix] = struct{}{}
 			prefixes = append(prefixes, commonPrefix)
@@ -464,20 +752,26 @@ func (layer *gatewayLayer) itemsToPrefixesAndObjects(
 			limit--
 		}
 	}
+	if items.Err() != nil {
+		return nil, nil, "", items.Err()
+	}
 
-	return prefixes, objects, ""
+	return prefixes, objects, "", nil
 }
 
 // listObjectsExhaustive lists the entire bucket discarding keys that do not
-// begin with the necessary prefix and come before continuationToken/startAfter.
-// It calls itemsToPrefixesAndObjects to dispatch results into prefixes and
-// objects.
+// begin with the necessary prefix, come before after or are in skip. It sorts
+// the remaining items with bounded memory, spilling sorted runs of
+// MaxKeysExhaustiveLimit items to temporary files, and calls
+// itemsToPrefixesAndObjects to dispatch the merged results into prefixes and
+// objects. If the listing is truncated, the merged results are kept for the
+// next page, so that it doesn't have to list and sort the bucket again.
 func (layer *gatewayLayer) listObjectsExhaustive(
 	ctx context.Context,
 	project *uplink.Project,
-	bucket, prefix, continuationToken, delimiter string,
+	bucket, prefix, after, delimiter string,
 	maxKeys int,
-	startAfter string,
+	skip []string,
 ) (
 	prefixes []string,
 	objects []minio.ObjectInfo,
@@ -486,6 +780,61 @@ func (layer *gatewayLayer) listObjectsExhaustive(
 ) {
 	defer mon.Task()(&ctx)(&err)
 
+	// Listings are only kept if they can't be taken by other projects.
+	listings := layer.objectListings
+	listingBucket, ok := projectBucketOf(ctx, bucket)
+	if !ok {
+		listings = nil
+	}
+	listingKey := func(cursor string) string {
+		return listCacheKey(listingBucket, listingToken{
+			Strategy:  listingStrategyExhaustive,
+			Prefix:    prefix,
+			Delimiter: delimiter,
+			Cursor:    cursor,
+			Skip:      skip,
+		}, 0)
+	}
+
+	listing := listings.take(listingKey(after))
+	if listing == nil {
+		listing, err = layer.sortObjects(ctx, project, bucket, prefix, after, delimiter, maxKeys, skip, listings != nil)
+		if err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	prefixes, objects, nextContinuationToken, err = layer.itemsToPrefixesAndObjects(listing.items, bucket, prefix, delimiter, maxKeys)
+	if err != nil {
+		return nil, nil, "", errs.Combine(err, listing.Close())
+	}
+	if nextContinuationToken == "" {
+		return prefixes, objects, "", listing.Close()
+	}
+
+	// The item that didn't fit into this page starts the next one.
+	listing.items.Unread()
+	if err = listings.keep(listingKey(nextContinuationToken), listing); err != nil {
+		return nil, nil, "", err
+	}
+
+	return prefixes, objects, nextContinuationToken, nil
+}
+
+// sortObjects lists and sorts items for listObjectsExhaustive. If kept is
+// true and there are more items than fit into a page of maxKeys, all of them
+// are spilled, so that they don't stay in memory while the listing is kept
+// between pages.
+func (layer *gatewayLayer) sortObjects(
+	ctx context.Context,
+	project *uplink.Project,
+	bucket, prefix, after, delimiter string,
+	maxKeys int,
+	skip []string,
+	kept bool,
+) (_ *sortedListing[uplink.Object], err error) {
+	defer mon.Task()(&ctx)(&err)
+
 	// Filling Prefix and Recursive are a few optimizations that try to make
 	// exhaustive listing less resource-intensive if it's possible. We still
 	// have to comply with (*uplink.Project).ListObjects' API.
@@ -506,20 +855,14 @@ func (layer *gatewayLayer) listObjectsExhaustive(
 		Custom:    layer.compatibilityConfig.IncludeCustomMetadataListing,
 	})
 
-	// Cursor priority: ContinuationToken > StartAfter == Marker
-	after := startAfter
-
-	if continuationToken != "" {
-		after = continuationToken
-	}
-
-	var items []*uplink.Object
-
-	for i := 0; list.Next(); i++ {
-		if i == layer.compatibilityConfig.MaxKeysExhaustiveLimit {
-			return nil, nil, "", ErrTooManyItemsToList
+	sorter := newObjectSorter(layer.compatibilityConfig.ListingTempDir, layer.compatibilityConfig.MaxKeysExhaustiveLimit)
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, sorter.Close())
 		}
+	}()
 
+	for list.Next() {
 		item := list.Item()
 
 		// Skip keys that do not begin with the required prefix.
@@ -553,20 +896,31 @@ func (layer *gatewayLayer) listObjectsExhaustive(
 		}
 
 		// We don't care about keys before ContinuationToken/StartAfter/Marker.
-		// Skip them.
-		if key <= after {
+		// Skip them, as well as keys that were already listed out of order.
+		if key <= after || slices.Contains(skip, key) {
 			continue
 		}
 
-		items = append(items, item)
+		if err = sorter.Add(item); err != nil {
+			return nil, err
+		}
 	}
 	if list.Err() != nil {
-		return nil, nil, "", list.Err()
+		return nil, list.Err()
 	}
 
-	prefixes, objects, nextContinuationToken = layer.itemsToPrefixesAndObjects(items, bucket, prefix, delimiter, maxKeys)
+	if kept && sorter.Len() > limitResults(maxKeys, layer.compatibilityConfig.MaxKeysLimit) {
+		if err = sorter.Flush(); err != nil {
+			return nil, err
+		}
+	}
 
-	return prefixes, objects, nextContinuationToken, nil
+	items, err := sorter.Iterate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &sortedListing[uplink.Object]{sorter: sorter, items: items}, nil
 }
 
 // listObjectsGeneral lists bucket trying to best-effort conform to AWS S3's
@@ -605,74 +959,170 @@ func (layer *gatewayLayer) listObjectsExhaustive(
 //
 // If layer.compatibilityConfig.FullyCompatibleListing is true, it will always
 // list exhaustively to achieve full S3 compatibility. Use at your own risk.
+//
+// If the listing index is enabled and the index of the bucket is ready, none
+// of the above applies: it will call listObjectsIndexed to serve
+// lexicographically ordered results for any prefix and delimiter directly from
+// the index.
+//
+// The way to list is chosen only for the first page. The state of the listing,
+// including the chosen way, is returned as next so that ListObjectsV2 can hand
+// it out in a continuation token and the next page continues exactly where the
+// previous one stopped. A listing optimized for non-terminated prefix
+// continues exhaustively, skipping items it has already returned. An indexed
+// listing continues exhaustively if the index isn't ready anymore, as both
+// return items in the same order. The returned NextContinuationToken is the
+// raw key to continue after, as ListObjects (V1) needs it for NextMarker.
+//
+// If the cache is enabled, pages are served from it until they expire or the
+// bucket is written to through the gateway.
 func (layer *gatewayLayer) listObjectsGeneral(
 	ctx context.Context,
 	project *uplink.Project,
-	bucket, prefix, continuationToken, delimiter string,
+	bucket string,
+	state listingToken,
 	maxKeys int,
-	startAfter string,
-) (_ minio.ListObjectsV2Info, err error) {
+) (result minio.ListObjectsV2Info, next *listingToken, err error) {
 	defer mon.Task()(&ctx)(&err)
 
+	if cache, cacheBucket := layer.bucketCache(ctx, bucket); cache != nil {
+		cacheKey := listCacheKey(cacheBucket, state, maxKeys)
+		if cached, cachedNext, ok := cache.getList(cacheBucket, cacheKey); ok {
+			return cached, cachedNext, nil
+		}
+
+		generation := cache.generation(cacheBucket)
+		defer func() {
+			if err == nil {
+				cache.putList(cacheBucket, cacheKey, generation, result, next)
+			}
+		}()
+	}
+
 	var (
 		prefixes []string
 		objects  []minio.ObjectInfo
 		token    string
+		// restart is true if the next page starts where this one started.
+		restart bool
 	)
 
-	supportedPrefix := prefix == "" || strings.HasSuffix(prefix, "/")
+	prefix, delimiter := state.Prefix, state.Delimiter
+
+	layer.scheduleLifecycle(ctx, project, bucket)
 
-	if !layer.compatibilityConfig.FullyCompatibleListing && supportedPrefix && (delimiter == "" || delimiter == "/") {
+	indexReady := false
+	if index, ok := layer.indexBucket(ctx, bucket); ok {
+		indexReady, err = layer.listingIndex.ensure(layer.logger, project, index)
+		if err != nil {
+			return minio.ListObjectsV2Info{}, nil, err
+		}
+	}
+
+	strategy := state.Strategy
+	if strategy == 0 {
+		supportedPrefix := prefix == "" || strings.HasSuffix(prefix, "/")
+
+		switch {
+		case indexReady:
+			strategy = listingStrategyIndexed
+		case !layer.compatibilityConfig.FullyCompatibleListing && supportedPrefix && (delimiter == "" || delimiter == "/"):
+			strategy = listingStrategyFast
+		case !supportedPrefix:
+			strategy = listingStrategySingle
+		default:
+			strategy = listingStrategyExhaustive
+		}
+	}
+	if strategy == listingStrategyIndexed && !indexReady {
+		strategy = listingStrategyExhaustive
+	}
+
+	next = &listingToken{
+		Strategy:  strategy,
+		Prefix:    prefix,
+		Delimiter: delimiter,
+		Skip:      state.Skip,
+	}
+
+	switch strategy {
+	case listingStrategyIndexed:
+		prefixes, objects, token, err = layer.listObjectsIndexed(
+			ctx,
+			bucket, prefix, state.Cursor, delimiter,
+			maxKeys)
+		if err != nil {
+			return minio.ListObjectsV2Info{}, nil, err
+		}
+	case listingStrategyFast:
 		prefixes, objects, token, err = layer.listObjectsFast(
 			ctx,
 			project,
-			bucket, prefix, continuationToken, delimiter,
-			maxKeys,
-			startAfter)
+			bucket, prefix, state.Cursor, delimiter,
+			maxKeys)
 		if err != nil {
-			return minio.ListObjectsV2Info{}, err
+			return minio.ListObjectsV2Info{}, nil, err
 		}
-	} else if !supportedPrefix {
+	case listingStrategySingle:
 		prefixes, objects, token, err = layer.listObjectsSingle(
 			ctx,
 			project,
-			bucket, prefix, continuationToken, delimiter,
-			maxKeys,
-			startAfter)
+			bucket, prefix, state.Cursor, delimiter,
+			maxKeys)
 		if err != nil {
-			return minio.ListObjectsV2Info{}, err
+			return minio.ListObjectsV2Info{}, nil, err
 		}
 		// Prefix optimization did not work; we need to fall back to exhaustive.
 		if prefixes == nil && objects == nil {
+			next.Strategy = listingStrategyExhaustive
 			prefixes, objects, token, err = layer.listObjectsExhaustive(
 				ctx,
 				project,
-				bucket, prefix, continuationToken, delimiter,
+				bucket, prefix, state.Cursor, delimiter,
 				maxKeys,
-				startAfter)
+				state.Skip)
 			if err != nil {
-				return minio.ListObjectsV2Info{}, err
+				return minio.ListObjectsV2Info{}, nil, err
+			}
+		} else {
+			// Items found this way aren't necessarily the first ones, so the
+			// listing has to continue from where it started, skipping them.
+			next.Strategy = listingStrategyExhaustive
+			restart = true
+			next.Skip = append(slices.Clone(prefixes), state.Skip...)
+			for _, object := range objects {
+				next.Skip = append(next.Skip, object.Name)
 			}
 		}
-	} else {
+	case listingStrategyExhaustive:
 		prefixes, objects, token, err = layer.listObjectsExhaustive(
 			ctx,
 			project,
-			bucket, prefix, continuationToken, delimiter,
+			bucket, prefix, state.Cursor, delimiter,
 			maxKeys,
-			startAfter)
+			state.Skip)
 		if err != nil {
-			return minio.ListObjectsV2Info{}, err
+			return minio.ListObjectsV2Info{}, nil, err
 		}
+	default:
+		return minio.ListObjectsV2Info{}, nil, errs.New("unknown listing strategy: %d", strategy)
+	}
+
+	switch {
+	case token == "":
+		next = nil
+	case restart:
+		next.Cursor = state.Cursor
+	default:
+		next.Cursor = token
 	}
 
 	return minio.ListObjectsV2Info{
 		IsTruncated:           token != "",
-		ContinuationToken:     continuationToken,
 		NextContinuationToken: token,
 		Objects:               objects,
 		Prefixes:              prefixes,
-	}, nil
+	}, next, nil
 }
 
 // ListObjects calls listObjectsGeneral and translates response from
@@ -694,7 +1144,11 @@ func (layer *gatewayLayer) ListObjects(ctx context.Context, bucket, prefix, mark
 	}
 
 	// For V1, marker is V2's startAfter and continuationToken does not exist.
-	v2, err := layer.listObjectsGeneral(ctx, project, bucket, prefix, "", delimiter, maxKeys, marker)
+	v2, _, err := layer.listObjectsGeneral(ctx, project, bucket, listingToken{
+		Prefix:    prefix,
+		Delimiter: delimiter,
+		Cursor:    marker,
+	}, maxKeys)
 
 	result := minio.ListObjectsInfo{
 		IsTruncated: v2.IsTruncated,
@@ -706,7 +1160,8 @@ func (layer *gatewayLayer) ListObjects(ctx context.Context, bucket, prefix, mark
 	return result, ConvertError(err, bucket, "")
 }
 
-// ListObjectsV2 calls listObjectsGeneral.
+// ListObjectsV2 calls listObjectsGeneral. It hands out the state of the
+// listing as opaque continuation tokens.
 func (layer *gatewayLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (_ minio.ListObjectsV2Info, err error) {
 	defer mon.Task()(&ctx)(&err)
 
@@ -719,9 +1174,32 @@ func (layer *gatewayLayer) ListObjectsV2(ctx context.Context, bucket, prefix, co
 		return minio.ListObjectsV2Info{}, err
 	}
 
-	result, err := layer.listObjectsGeneral(ctx, project, bucket, prefix, continuationToken, delimiter, maxKeys, startAfter)
+	// Cursor priority: ContinuationToken > StartAfter
+	state := listingToken{
+		Prefix:    prefix,
+		Delimiter: delimiter,
+		Cursor:    startAfter,
+	}
 
-	return result, ConvertError(err, bucket, "")
+	if continuationToken != "" {
+		state, err = layer.decodeListingToken(bucket, prefix, delimiter, continuationToken)
+		if err != nil {
+			return minio.ListObjectsV2Info{}, err
+		}
+	}
+
+	result, next, err := layer.listObjectsGeneral(ctx, project, bucket, state, maxKeys)
+	if err != nil {
+		return minio.ListObjectsV2Info{}, ConvertError(err, bucket, "")
+	}
+
+	result.ContinuationToken = continuationToken
+	result.NextContinuationToken = ""
+	if next != nil {
+		result.NextContinuationToken = layer.encodeListingToken(bucket, next)
+	}
+
+	return result, nil
 }
 
 // ListObjectVersions returns information about all versions of the objects in a bucket.
@@ -823,12 +1301,15 @@ func (layer *gatewayLayer) GetObjectNInfo(ctx context.Context, bucket, object st
 		return nil, ConvertError(err, bucket, object)
 	}
 
-	download, err := versioned.DownloadObject(context.Background(), project, bucket, object, version, downloadOpts)
+	download, info, err := downloadObject(context.Background(), project, bucket, object, version, downloadOpts)
 	if err != nil {
 		return nil, ConvertError(err, bucket, object)
 	}
 
-	objectInfo := minioVersionedObjectInfo(bucket, "", download.Info())
+	objectInfo := layer.withExpirationHeader(ctx, minioVersionedObjectInfo(bucket, "", info), version == nil)
+	if rs == nil {
+		objectInfo = withChecksumHeaders(ctx, objectInfo)
+	}
 	downloadCloser := func() { _ = download.Close() }
 
 	f, _, _, err := minio.NewGetObjectReader(rs, objectInfo, opts, downloadCloser)
@@ -894,6 +1375,16 @@ func (layer *gatewayLayer) GetObjectInfo(ctx context.Context, bucket, objectPath
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
 	}
 
+	// Only stats of the latest version are cached.
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	if version == nil {
+		if info, ok := cache.getStat(cacheBucket, objectPath); ok {
+			return withChecksumHeaders(ctx, layer.withExpirationHeader(ctx, info, true)), nil
+		}
+	}
+
+	generation := cache.generation(cacheBucket)
+
 	object, err := versioned.StatObject(context.Background(), project, bucket, objectPath, version)
 	if err != nil {
 		// TODO this should be removed and implemented on satellite side
@@ -901,11 +1392,18 @@ func (layer *gatewayLayer) GetObjectInfo(ctx context.Context, bucket, objectPath
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
 	}
 
-	return minioVersionedObjectInfo(bucket, "", object), nil
+	objInfo = minioVersionedObjectInfo(bucket, "", object)
+
+	if version == nil {
+		cache.putStat(cacheBucket, objectPath, generation, objInfo)
+	}
+
+	return withChecksumHeaders(ctx, layer.withExpirationHeader(ctx, objInfo, version == nil)), nil
 }
 
 func (layer *gatewayLayer) PutObject(ctx context.Context, bucket, object string, data *minio.PutObjReader, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
+	defer layer.notifyCreated(ctx, eventObjectCreatedPut, bucket, object, &objInfo, &err)
 	layer.logger.Infof("PutObject miniogw started: %s", err)
 	if err := ValidateBucket(ctx, bucket); err != nil {
 		layer.logger.Info("PutObject error: bucket name invalid")
@@ -928,6 +1426,9 @@ func (layer *gatewayLayer) PutObject(ctx context.Context, bucket, object string,
 		return minio.ObjectInfo{}, err
 	}
 
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateObject(cacheBucket, object)
+
 	layer.logger.Infof("PutObject project: %#+v", project)
 
 	// TODO this should be removed and implemented on satellite side
@@ -952,21 +1453,80 @@ func (layer *gatewayLayer) PutObject(ctx context.Context, bucket, object string,
 		layer.logger.Infof("PutObject error: err invalid TTL: %s", err)
 		return minio.ObjectInfo{}, ErrInvalidTTL
 	}
+
+	if err := setWebsiteRedirectLocation(ctx, opts.UserDefined); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	checksum, err := newUploadChecksum(ctx)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	// Fail early before uploading any data. Preconditions are evaluated
+	// again right before committing.
+	if err := checkWriteConditions(ctx, project, bucket, object); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	retention, err := layer.newObjectRetention(ctx, bucket)
+	if err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
+	}
+	// Locked objects are kept regardless of lifecycle rules.
+	if retention == nil {
+		tags, ok := opts.UserDefined[xhttp.AmzObjectTagging]
+		if !ok {
+			tags = opts.UserDefined["s3:tags"]
+		}
+		expires, err := layer.lifecycleExpiration(ctx, project, bucket, object, tags, data.Size())
+		if err != nil {
+			return minio.ObjectInfo{}, ConvertError(err, bucket, object)
+		}
+		e = earliestExpiration(e, expires)
+	}
 	upload, err := versioned.UploadObject(context.Background(), project, bucket, object, &uo.UploadOptions{
-		Expires: e,
+		Expires:   e,
+		Retention: satelliteRetention(retention),
 	})
 	if err != nil {
 		layer.logger.Infof("PutObject error: upload object error: %s", err)
 		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
 	}
 
-	_, err = io.Copy(upload, data)
+	var reader io.Reader = data
+	if checksum != nil {
+		reader = io.TeeReader(data, checksum)
+	}
+
+	if layer.compatibilityConfig.Compression.shouldCompress(bucket, opts.UserDefined) {
+		var (
+			size   int64
+			frames compressionFrames
+		)
+		size, frames, err = copyCompressed(upload, reader)
+		opts.UserDefined[compressionMetadataKey] = compressionZstd
+		opts.UserDefined[uncompressedSizeMetadataKey] = strconv.FormatInt(size, 10)
+		if index := encodeCompressionFrames(frames); index != "" {
+			opts.UserDefined[compressionFramesMetadataKey] = index
+		}
+	} else {
+		_, err = io.Copy(upload, reader)
+	}
 	if err != nil {
 		abortErr := upload.Abort()
 		err = errs.Combine(err, abortErr)
 		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
 	}
 
+	if checksum != nil {
+		sum, err := checksum.sum()
+		if err != nil {
+			return minio.ObjectInfo{}, errs.Combine(err, upload.Abort())
+		}
+		opts.UserDefined[checksumMetadataKey(checksum.algorithm)] = base64.StdEncoding.EncodeToString(sum)
+	}
+
 	if tagsStr, ok := opts.UserDefined[xhttp.AmzObjectTagging]; ok {
 		opts.UserDefined["s3:tags"] = tagsStr
 		delete(opts.UserDefined, xhttp.AmzObjectTagging)
@@ -983,6 +1543,16 @@ func (layer *gatewayLayer) PutObject(ctx context.Context, bucket, object string,
 		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
 	}
 
+	unlock := layer.lockConditionalWrite(ctx, bucket, object)
+	defer unlock()
+
+	if err := checkWriteConditions(ctx, project, bucket, object); err != nil {
+		if abortErr := upload.Abort(); abortErr != nil {
+			layer.logger.Infof("PutObject error: abort upload error: %s", abortErr)
+		}
+		return minio.ObjectInfo{}, err
+	}
+
 	err = upload.Commit()
 	if err != nil {
 		layer.logger.Infof("PutObject error: commit upload error: %s", err)
@@ -990,18 +1560,42 @@ func (layer *gatewayLayer) PutObject(ctx context.Context, bucket, object string,
 	}
 	layer.logger.Infof("PutObject miniogw finished: %s", err)
 
-	return minioVersionedObjectInfo(bucket, etag, upload.Info()), nil
+	info := upload.Info()
+	if info != nil {
+		layer.indexObject(ctx, bucket, &uplink.Object{Key: info.Key, System: info.System, Custom: opts.UserDefined})
+	}
+
+	return minioVersionedObjectInfo(bucket, etag, info), nil
 }
 
 func (layer *gatewayLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, destOpts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
+	defer layer.notifyCreated(ctx, eventObjectCreatedCopy, destBucket, destObject, &objInfo, &err)
+
+	// Copies made by uploading objects again are notified of as copies only.
+	ctx = withNotificationSource(ctx, notifyNothing)
 
-	// TODO(ver): should be implemented soon on libuplink side
-	if srcOpts.VersionID != "" || destOpts.VersionID != "" {
+	// The version ID of the copy is always chosen by the satellite.
+	if destOpts.VersionID != "" {
 		return minio.ObjectInfo{}, minio.NotImplemented{}
 	}
 
-	srcAndDestSame := srcBucket == destBucket && srcObject == destObject
+	srcVersion, err := decodeVersionID(srcOpts.VersionID)
+	if err != nil {
+		return minio.ObjectInfo{}, minio.InvalidVersionID{Bucket: srcBucket, Object: srcObject, VersionID: srcOpts.VersionID}
+	}
+
+	// The expiration of the source object is returned as a header, but it's
+	// not metadata of the copy.
+	delete(srcInfo.UserDefined, xhttp.AmzExpiration)
+
+	if err := setWebsiteRedirectLocation(ctx, srcInfo.UserDefined); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	// Copying a specific version over the same key (e.g., to restore it)
+	// creates a new version instead of updating metadata in place.
+	srcAndDestSame := srcBucket == destBucket && srcObject == destObject && srcVersion == nil
 
 	if layer.compatibilityConfig.DisableCopyObject && !srcAndDestSame {
 		return minio.ObjectInfo{}, minio.NotImplemented{Message: "CopyObject"}
@@ -1034,6 +1628,9 @@ func (layer *gatewayLayer) CopyObject(ctx context.Context, srcBucket, srcObject,
 		return minio.ObjectInfo{}, err
 	}
 
+	cache, cacheBucket := layer.bucketCache(ctx, destBucket)
+	defer cache.invalidateObject(cacheBucket, destObject)
+
 	if srcAndDestSame {
 		// TODO this should be removed and implemented on satellite side
 		_, err = project.StatBucket(ctx, srcBucket)
@@ -1051,6 +1648,14 @@ func (layer *gatewayLayer) CopyObject(ctx context.Context, srcBucket, srcObject,
 			return minio.ObjectInfo{}, ConvertError(err, srcBucket, srcObject)
 		}
 
+		expires, changed, err := expirationChange(ctx, srcInfo.UserDefined, info.Custom)
+		if err != nil {
+			return minio.ObjectInfo{}, ErrInvalidTTL
+		}
+		if changed && !expires.Equal(info.System.Expires) {
+			return layer.changeObjectExpiration(ctx, srcBucket, srcObject, info, srcInfo)
+		}
+
 		upsertObjectMetadata(srcInfo.UserDefined, info.Custom)
 
 		err = project.UpdateObjectMetadata(ctx, srcBucket, srcObject, srcInfo.UserDefined, nil)
@@ -1058,20 +1663,109 @@ func (layer *gatewayLayer) CopyObject(ctx context.Context, srcBucket, srcObject,
 			return minio.ObjectInfo{}, ConvertError(err, srcBucket, srcObject)
 		}
 
+		info.Custom = srcInfo.UserDefined
+		layer.indexObject(ctx, srcBucket, info)
+
 		return srcInfo, nil
 	}
 
+	expires, err := parseTTL(srcInfo.UserDefined)
+	if err != nil {
+		return minio.ObjectInfo{}, ErrInvalidTTL
+	}
+
+	// The satellite's server-side copy keeps the expiration time of the
+	// source object, so objects that need a new one are copied by uploading
+	// them again.
+	if expires.IsZero() {
+		return layer.copyObjectServerSide(ctx, project, srcBucket, srcObject, srcVersion, destBucket, destObject, srcInfo, destOpts)
+	}
+
+	return layer.PutObject(ctx, destBucket, destObject, srcInfo.PutObjReader, copyUploadOptions(srcInfo, destOpts))
+}
+
+// copyUploadOptions returns the options of uploading the data of the source
+// object of a copy described by srcInfo again. The data minio reads is
+// decompressed, so the copy is stored the way its own bucket asks for.
+func copyUploadOptions(srcInfo minio.ObjectInfo, destOpts minio.ObjectOptions) minio.ObjectOptions {
+	metadata := make(map[string]string, len(srcInfo.UserDefined))
+	for k, v := range srcInfo.UserDefined {
+		metadata[k] = v
+	}
+
 	// https://github.com/minio/minio/blob/master/cmd/erasure-server-pool.go#L1348
-	putOpts := minio.ObjectOptions{
+	return minio.ObjectOptions{
 		ServerSideEncryption: destOpts.ServerSideEncryption,
-		UserDefined:          srcInfo.UserDefined,
+		UserDefined:          withoutUploadMetadata(metadata),
 		Versioned:            destOpts.Versioned,
 		VersionID:            destOpts.VersionID,
 		MTime:                destOpts.MTime,
 		NoLock:               true,
 	}
+}
+
+// copyObjectServerSide copies srcObject (its srcVersion version or the latest
+// one if srcVersion is nil) to destObject using the satellite's server-side
+// copy, so no object data passes through the gateway. The satellite can't
+// change metadata as part of a copy and can only update the metadata of the
+// latest version afterwards, which may already be another one, so copies
+// that need metadata other than the source object's (e.g., with the REPLACE
+// directive or new tags) are made by uploading the object again instead,
+// which commits data and metadata at once. The returned info contains the
+// version ID of the copy.
+func (layer *gatewayLayer) copyObjectServerSide(ctx context.Context, project *uplink.Project, srcBucket, srcObject string, srcVersion []byte, destBucket, destObject string, srcInfo minio.ObjectInfo, destOpts minio.ObjectOptions) (_ minio.ObjectInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	source, err := versioned.StatObject(ctx, project, srcBucket, srcObject, srcVersion)
+	if err != nil {
+		// TODO this should be removed and implemented on satellite side
+		err = checkBucketError(ctx, project, srcBucket, srcObject, err)
+		return minio.ObjectInfo{}, ConvertError(err, srcBucket, srcObject)
+	}
+
+	metadata := make(map[string]string, len(srcInfo.UserDefined))
+	for k, v := range srcInfo.UserDefined {
+		metadata[k] = v
+	}
+
+	upsertObjectMetadata(metadata, source.Custom)
 
-	return layer.PutObject(ctx, destBucket, destObject, srcInfo.PutObjReader, putOpts)
+	if !maps.Equal(metadata, source.Custom) {
+		if srcInfo.PutObjReader == nil {
+			return minio.ObjectInfo{}, minio.NotImplemented{Message: "CopyObject (metadata)"}
+		}
+
+		opts := copyUploadOptions(srcInfo, destOpts)
+		opts.UserDefined = reuploadMetadata(srcInfo.UserDefined, source.Custom)
+
+		return layer.PutObject(ctx, destBucket, destObject, srcInfo.PutObjReader, opts)
+	}
+
+	retention, err := layer.newObjectRetention(ctx, destBucket)
+	if err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, destBucket, destObject)
+	}
+
+	// The version stated above is copied, so that the copy gets the metadata
+	// compared with the requested one even if a new version is uploaded in
+	// the meantime.
+	object, err := versioned.CopyObject(ctx, project, srcBucket, srcObject, source.Version, destBucket, destObject, versioned.CopyObjectOptions{
+		Retention: satelliteRetention(retention),
+	})
+	if err != nil {
+		// TODO this should be removed and implemented on satellite side
+		if errors.Is(err, uplink.ErrObjectNotFound) {
+			err = checkBucketError(ctx, project, srcBucket, srcObject, err)
+			return minio.ObjectInfo{}, ConvertError(err, srcBucket, srcObject)
+		}
+		err = checkBucketError(ctx, project, destBucket, destObject, err)
+		return minio.ObjectInfo{}, ConvertError(err, destBucket, destObject)
+	}
+
+	object.Custom = source.Custom
+	layer.indexObject(ctx, destBucket, &object.Object)
+
+	return minioVersionedObjectInfo(destBucket, object.Custom["s3:etag"], object), nil
 }
 
 func (layer *gatewayLayer) DeleteObject(ctx context.Context, bucket, objectPath string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
@@ -1086,11 +1780,18 @@ func (layer *gatewayLayer) DeleteObject(ctx context.Context, bucket, objectPath
 		return minio.ObjectInfo{}, err
 	}
 
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateObject(cacheBucket, objectPath)
+
 	version, err := decodeVersionID(opts.VersionID)
 	if err != nil {
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
 	}
 
+	if err := layer.checkObjectLock(ctx, project, bucket, objectPath, version); err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+	}
+
 	object, err := versioned.DeleteObject(context.Background(), project, bucket, objectPath, version)
 	if err != nil {
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
@@ -1108,7 +1809,29 @@ func (layer *gatewayLayer) DeleteObject(ctx context.Context, bucket, objectPath
 		}
 	}
 
-	return minioVersionedObjectInfo(bucket, "", object), nil
+	if version == nil {
+		layer.unindexObject(ctx, bucket, objectPath)
+	} else {
+		// Deleting a specific version might have exposed an older one.
+		layer.reindexObject(ctx, project, bucket, objectPath)
+	}
+
+	// Tags and locks of the deleted version aren't needed anymore.
+	if object != nil && !object.IsDeleteMarker {
+		layer.forgetObjectVersion(ctx, bucket, objectPath, object.Version)
+	}
+
+	objInfo = minioVersionedObjectInfo(bucket, "", object)
+
+	if object != nil {
+		event := eventObjectRemovedDelete
+		if object.IsDeleteMarker && version == nil {
+			event = eventObjectRemovedDeleteMarkerCreated
+		}
+		layer.notify(ctx, event, bucket, objectPath, objInfo)
+	}
+
+	return objInfo, nil
 }
 
 func (layer *gatewayLayer) DeleteObjects(ctx context.Context, bucket string, objects []minio.ObjectToDelete, opts minio.ObjectOptions) ([]minio.DeletedObject, []error) {
@@ -1143,11 +1866,6 @@ func (layer *gatewayLayer) IsTaggingSupported() bool {
 func (layer *gatewayLayer) PutObjectTags(ctx context.Context, bucket, objectPath string, tags string, opts minio.ObjectOptions) (_ minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	// TODO(ver): not implemented yet
-	if opts.VersionID != "" {
-		return minio.ObjectInfo{}, minio.NotImplemented{}
-	}
-
 	if err := ValidateBucket(ctx, bucket); err != nil {
 		return minio.ObjectInfo{}, minio.BucketNameInvalid{Bucket: bucket}
 	}
@@ -1157,30 +1875,15 @@ func (layer *gatewayLayer) PutObjectTags(ctx context.Context, bucket, objectPath
 		return minio.ObjectInfo{}, err
 	}
 
-	object, err := project.StatObject(context.Background(), bucket, objectPath)
-	if err != nil {
-		// TODO this should be removed and implemented on satellite side
-		err = checkBucketError(context.Background(), project, bucket, objectPath, err)
-		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
-	}
-
-	if _, ok := object.Custom["s3:tags"]; !ok && tags == "" {
-		return minioObjectInfo(bucket, "", object), nil
-	}
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateObject(cacheBucket, objectPath)
 
-	newMetadata := object.Custom.Clone()
-	if tags == "" {
-		delete(newMetadata, "s3:tags")
-	} else {
-		newMetadata["s3:tags"] = tags
-	}
-
-	err = project.UpdateObjectMetadata(context.Background(), bucket, objectPath, newMetadata, nil)
+	version, err := decodeVersionID(opts.VersionID)
 	if err != nil {
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
 	}
 
-	return minioObjectInfo(bucket, "", object), nil
+	return layer.setObjectTags(ctx, project, bucket, objectPath, version, tags)
 }
 
 func (layer *gatewayLayer) GetObjectTags(ctx context.Context, bucket, objectPath string, opts minio.ObjectOptions) (t *tags.Tags, err error) {
@@ -1207,7 +1910,20 @@ func (layer *gatewayLayer) GetObjectTags(ctx context.Context, bucket, objectPath
 		return nil, ConvertError(err, bucket, objectPath)
 	}
 
-	t, err = tags.ParseObjectTags(object.Custom["s3:tags"])
+	objectTags := object.Custom["s3:tags"]
+
+	// Tags set while the version was noncurrent take precedence.
+	if layer.metadataStore != nil {
+		stored, found, err := layer.metadataStore.get(storeObjectTags, storeBucket(ctx, bucket), objectVersionKey(objectPath, object.Version))
+		if err != nil {
+			return nil, ConvertError(err, bucket, objectPath)
+		}
+		if found {
+			objectTags = string(stored)
+		}
+	}
+
+	t, err = tags.ParseObjectTags(objectTags)
 	if err != nil {
 		return nil, ConvertError(err, bucket, objectPath)
 	}
@@ -1218,11 +1934,6 @@ func (layer *gatewayLayer) GetObjectTags(ctx context.Context, bucket, objectPath
 func (layer *gatewayLayer) DeleteObjectTags(ctx context.Context, bucket, objectPath string, opts minio.ObjectOptions) (_ minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
-	// TODO(ver): not implemented yet
-	if opts.VersionID != "" {
-		return minio.ObjectInfo{}, minio.NotImplemented{}
-	}
-
 	if err := ValidateBucket(ctx, bucket); err != nil {
 		return minio.ObjectInfo{}, minio.BucketNameInvalid{Bucket: bucket}
 	}
@@ -1232,26 +1943,90 @@ func (layer *gatewayLayer) DeleteObjectTags(ctx context.Context, bucket, objectP
 		return minio.ObjectInfo{}, err
 	}
 
-	object, err := project.StatObject(ctx, bucket, objectPath)
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateObject(cacheBucket, objectPath)
+
+	version, err := decodeVersionID(opts.VersionID)
+	if err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+	}
+
+	return layer.setObjectTags(ctx, project, bucket, objectPath, version, "")
+}
+
+// setObjectTags replaces tags of version of the object at objectPath (or its
+// latest version if version is nil) with tags. Empty tags remove them.
+//
+// The satellite only allows updating metadata of the latest version, so tags
+// of noncurrent versions are kept in the metadata store instead, where they
+// take precedence over tags in the metadata of the version.
+func (layer *gatewayLayer) setObjectTags(ctx context.Context, project *uplink.Project, bucket, objectPath string, version []byte, tags string) (_ minio.ObjectInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	object, err := versioned.StatObject(ctx, project, bucket, objectPath, version)
 	if err != nil {
 		// TODO this should be removed and implemented on satellite side
-		err = checkBucketError(context.Background(), project, bucket, objectPath, err)
+		err = checkBucketError(ctx, project, bucket, objectPath, err)
 		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
 	}
 
-	if _, ok := object.Custom["s3:tags"]; !ok {
-		return minioObjectInfo(bucket, "", object), nil
+	if version != nil {
+		latest, err := versioned.StatObject(ctx, project, bucket, objectPath, nil)
+		if err != nil && !errors.Is(err, uplink.ErrObjectNotFound) {
+			return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+		}
+		if latest == nil || !bytes.Equal(latest.Version, object.Version) {
+			return layer.setNoncurrentObjectTags(ctx, bucket, object, tags)
+		}
+	}
+
+	storeKey := objectVersionKey(objectPath, object.Version)
+
+	if _, ok := object.Custom["s3:tags"]; ok || tags != "" {
+		newMetadata := object.Custom.Clone()
+		if tags == "" {
+			delete(newMetadata, "s3:tags")
+		} else {
+			newMetadata["s3:tags"] = tags
+		}
+
+		err = project.UpdateObjectMetadata(ctx, bucket, objectPath, newMetadata, nil)
+		if err != nil {
+			return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+		}
+
+		layer.indexObject(ctx, bucket, &uplink.Object{Key: object.Key, System: object.System, Custom: newMetadata})
+	}
+
+	// The version might have been tagged while it was noncurrent.
+	if layer.metadataStore != nil {
+		if err := layer.metadataStore.delete(storeObjectTags, storeBucket(ctx, bucket), storeKey); err != nil {
+			return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+		}
 	}
 
-	newMetadata := object.Custom.Clone()
-	delete(newMetadata, "s3:tags")
+	return minioVersionedObjectInfo(bucket, "", object), nil
+}
 
-	err = project.UpdateObjectMetadata(ctx, bucket, objectPath, newMetadata, nil)
+// setNoncurrentObjectTags keeps tags of the noncurrent version object in the
+// metadata store.
+func (layer *gatewayLayer) setNoncurrentObjectTags(ctx context.Context, bucket string, object *versioned.VersionedObject, tags string) (_ minio.ObjectInfo, err error) {
+	if layer.metadataStore == nil {
+		return minio.ObjectInfo{}, minio.NotImplemented{Message: "Tagging noncurrent versions requires the metadata store"}
+	}
+
+	storeKey := objectVersionKey(object.Key, object.Version)
+
+	if tags == object.Custom["s3:tags"] {
+		err = layer.metadataStore.delete(storeObjectTags, storeBucket(ctx, bucket), storeKey)
+	} else {
+		err = layer.metadataStore.put(storeObjectTags, storeBucket(ctx, bucket), storeKey, []byte(tags))
+	}
 	if err != nil {
-		return minio.ObjectInfo{}, ConvertError(err, bucket, objectPath)
+		return minio.ObjectInfo{}, ConvertError(err, bucket, object.Key)
 	}
 
-	return minioObjectInfo(bucket, "", object), nil
+	return minioVersionedObjectInfo(bucket, "", object), nil
 }
 
 // GetBucketVersioning retrieves versioning configuration of a bucket.
@@ -1301,6 +2076,16 @@ func (layer *gatewayLayer) SetBucketVersioning(ctx context.Context, bucketName s
 	if v.Suspended() {
 		versioning = false
 
+		// Overwrites in buckets with suspended versioning delete the
+		// previous version, which would bypass Object Lock.
+		enabled, err := bucketObjectLockEnabled(ctx, project, bucketName)
+		if err != nil {
+			return ConvertError(err, bucketName, "")
+		}
+		if enabled {
+			return ErrVersioningLocked
+		}
+
 		// TODO(ver): workaround for not being able to change from unversionded to suspended
 		// https://github.com/storj/storj/issues/6591
 		state, err := bucket.GetBucketVersioning(ctx, project, bucketName)
@@ -1352,6 +2137,10 @@ func ConvertError(err error, bucket, object string) error {
 		return minio.PrefixAccessDenied{Bucket: bucket, Object: object}
 	case errors.Is(err, uplink.ErrTooManyRequests):
 		return ErrSlowDown
+	case errors.Is(err, versioned.ErrNoObjectLockConfiguration):
+		return minio.BucketObjectLockConfigNotFound{Bucket: bucket}
+	case metaclient.ErrRetentionNotFound.Has(err):
+		return ErrNoSuchObjectLockConfiguration
 	case errors.Is(err, versioned.ErrMethodNotAllowed):
 		return minio.MethodNotAllowed{Bucket: bucket, Object: object}
 	case errors.Is(err, io.ErrUnexpectedEOF):
@@ -1402,7 +2191,22 @@ func upsertObjectMetadata(metadata map[string]string, existingMetadata uplink.Cu
 
 	// if X-Amz-Metadata-Directive header is set to "REPLACE", then
 	// srcInfo.UserDefined will be missing s3:etag, so make sure it's copied.
-	metadata["s3:etag"] = existingMetadata["s3:etag"]
+	if etag, ok := existingMetadata["s3:etag"]; ok {
+		metadata["s3:etag"] = etag
+	}
+
+	// The data doesn't change, so neither do its checksums nor the way it's
+	// stored.
+	for _, algorithm := range checksumAlgorithms {
+		if checksum, ok := existingMetadata[checksumMetadataKey(algorithm)]; ok {
+			metadata[checksumMetadataKey(algorithm)] = checksum
+		}
+	}
+	for _, key := range compressionMetadataKeys {
+		if value, ok := existingMetadata[key]; ok {
+			metadata[key] = value
+		}
+	}
 }
 
 // checkBucketError will stat the bucket if the provided error is not nil, in
@@ -1437,9 +2241,10 @@ func minioObjectInfo(bucket, etag string, object *uplink.Object) minio.ObjectInf
 	return minio.ObjectInfo{
 		Bucket:      bucket,
 		Name:        object.Key,
-		Size:        object.System.ContentLength,
+		Size:        objectSize(object),
 		ETag:        etag,
 		ModTime:     object.System.Created,
+		Expires:     object.System.Expires,
 		ContentType: contentType,
 		UserDefined: object.Custom,
 	}
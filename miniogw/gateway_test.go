@@ -4,9 +4,17 @@
 package miniogw
 
 import (
+	"bytes"
+	"context"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/minio/pkg/hash"
 )
 
 func TestLimitResults(t *testing.T) {
@@ -33,3 +41,228 @@ func TestLimitResults(t *testing.T) {
 		assert.Equal(t, tt.expected, limitResults(tt.maxKeys, 1000), i)
 	}
 }
+
+// putTestObject uploads data to key in bucket with metadata like minio's
+// PutObjectHandler does.
+func putTestObject(ctx context.Context, t *testing.T, layer *gatewayLayer, bucket, key string, data []byte, metadata map[string]string) minio.ObjectInfo {
+	reader, err := hash.NewReader(bytes.NewReader(data), int64(len(data)), "", "", int64(len(data)))
+	require.NoError(t, err)
+
+	info, err := layer.PutObject(ctx, bucket, key, minio.NewPutObjReader(reader), minio.ObjectOptions{UserDefined: metadata})
+	require.NoError(t, err)
+
+	return info
+}
+
+// getTestObject returns the info and the data of the versionID version of
+// key in bucket.
+func getTestObject(ctx context.Context, t *testing.T, layer *gatewayLayer, bucket, key, versionID string) (minio.ObjectInfo, []byte) {
+	reader, err := layer.GetObjectNInfo(ctx, bucket, key, nil, nil, 0, minio.ObjectOptions{VersionID: versionID})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	return reader.ObjInfo, data
+}
+
+// copyTestSource returns the info of the source object of a copy the way
+// minio's CopyObjectHandler passes it, with metadata as returned by
+// metadata for the source object's.
+func copyTestSource(ctx context.Context, t *testing.T, layer *gatewayLayer, bucket, key, versionID string, metadata func(map[string]string) map[string]string) minio.ObjectInfo {
+	gr, err := layer.GetObjectNInfo(ctx, bucket, key, nil, nil, 0, minio.ObjectOptions{VersionID: versionID})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, gr.Close()) })
+
+	reader, err := hash.NewReader(gr, gr.ObjInfo.Size, "", "", gr.ObjInfo.Size)
+	require.NoError(t, err)
+
+	srcInfo := gr.ObjInfo
+	srcInfo.PutObjReader = minio.NewPutObjReader(reader)
+
+	source := make(map[string]string, len(srcInfo.UserDefined))
+	for k, v := range srcInfo.UserDefined {
+		source[k] = v
+	}
+	srcInfo.UserDefined = metadata(source)
+
+	return srcInfo
+}
+
+// copyDirective returns metadata of a copy with the COPY metadata and
+// tagging directives, as prepared by minio.
+func copyDirective(source map[string]string) map[string]string {
+	source[xhttp.AmzObjectTagging] = source["s3:tags"]
+	return source
+}
+
+func TestCopyObject(t *testing.T) {
+	satellite, project := newTestProject(t, "bucket", "other")
+	layer, ctx := newTestLayer(t, project)
+
+	data := []byte("copied data")
+	source := putTestObject(ctx, t, layer, "bucket", "source", data, map[string]string{
+		"content-type":         "text/plain",
+		"X-Amz-Meta-Key":       "value",
+		xhttp.AmzObjectTagging: "tag=value",
+	})
+
+	for _, destBucket := range []string{"bucket", "other"} {
+		t.Run(destBucket, func(t *testing.T) {
+			uploads := satellite.count("BeginObject")
+
+			srcInfo := copyTestSource(ctx, t, layer, "bucket", "source", "", copyDirective)
+			info, err := layer.CopyObject(ctx, "bucket", "source", destBucket, "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+			require.NoError(t, err)
+
+			// Copies with the source object's metadata are made on the
+			// satellite only.
+			assert.Equal(t, uploads, satellite.count("BeginObject"))
+			assert.Zero(t, satellite.count("UpdateObjectMetadata"))
+
+			assert.Equal(t, source.ETag, info.ETag)
+			assert.NotEmpty(t, info.VersionID)
+			assert.NotEqual(t, source.VersionID, info.VersionID)
+
+			copied, copiedData := getTestObject(ctx, t, layer, destBucket, "copy", "")
+			assert.Equal(t, data, copiedData)
+			assert.Equal(t, info.VersionID, copied.VersionID)
+			assert.Equal(t, source.ETag, copied.ETag)
+			assert.Equal(t, "value", copied.UserDefined["X-Amz-Meta-Key"])
+			assert.Equal(t, "text/plain", copied.UserDefined["content-type"])
+			assert.Equal(t, "tag=value", copied.UserDefined["s3:tags"])
+		})
+	}
+
+	t.Run("replace metadata", func(t *testing.T) {
+		uploads := satellite.count("BeginObject")
+
+		srcInfo := copyTestSource(ctx, t, layer, "bucket", "source", "", func(source map[string]string) map[string]string {
+			return map[string]string{
+				"content-type":         "application/json",
+				"X-Amz-Meta-Other":     "other",
+				xhttp.AmzObjectTagging: source["s3:tags"],
+			}
+		})
+		info, err := layer.CopyObject(ctx, "bucket", "source", "other", "replaced", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.NoError(t, err)
+
+		// Copies with new metadata are uploaded again instead of updating
+		// the metadata of whichever version is the latest after the copy.
+		assert.Equal(t, uploads+1, satellite.count("BeginObject"))
+		assert.Zero(t, satellite.count("UpdateObjectMetadata"))
+		assert.Equal(t, source.ETag, info.ETag)
+
+		copied, copiedData := getTestObject(ctx, t, layer, "other", "replaced", "")
+		assert.Equal(t, data, copiedData)
+		assert.Equal(t, info.VersionID, copied.VersionID)
+		assert.Equal(t, source.ETag, copied.ETag)
+		assert.Equal(t, "other", copied.UserDefined["X-Amz-Meta-Other"])
+		assert.NotContains(t, copied.UserDefined, "X-Amz-Meta-Key")
+		assert.Equal(t, "application/json", copied.UserDefined["content-type"])
+		assert.Equal(t, "tag=value", copied.UserDefined["s3:tags"])
+	})
+
+	t.Run("replace tags", func(t *testing.T) {
+		srcInfo := copyTestSource(ctx, t, layer, "bucket", "source", "", func(source map[string]string) map[string]string {
+			source[xhttp.AmzTagDirective] = "REPLACE"
+			source[xhttp.AmzObjectTagging] = "other=tag"
+			return source
+		})
+		_, err := layer.CopyObject(ctx, "bucket", "source", "bucket", "tagged", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.NoError(t, err)
+
+		copied, copiedData := getTestObject(ctx, t, layer, "bucket", "tagged", "")
+		assert.Equal(t, data, copiedData)
+		assert.Equal(t, "other=tag", copied.UserDefined["s3:tags"])
+		assert.Equal(t, "value", copied.UserDefined["X-Amz-Meta-Key"])
+
+		unchanged, _ := getTestObject(ctx, t, layer, "bucket", "source", "")
+		assert.Equal(t, "tag=value", unchanged.UserDefined["s3:tags"])
+		assert.Equal(t, source.VersionID, unchanged.VersionID)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		srcInfo := minio.ObjectInfo{UserDefined: map[string]string{}}
+
+		_, err := layer.CopyObject(ctx, "bucket", "missing", "other", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.ObjectNotFound{})
+
+		_, err = layer.CopyObject(ctx, "missing", "source", "other", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.BucketNotFound{})
+
+		srcInfo = copyTestSource(ctx, t, layer, "bucket", "source", "", copyDirective)
+		_, err = layer.CopyObject(ctx, "bucket", "source", "missing", "copy", srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.BucketNotFound{})
+	})
+}
+
+func TestCopyObjectVersion(t *testing.T) {
+	_, project := newTestProject(t, "bucket", "other")
+	layer, ctx := newTestLayer(t, project)
+
+	old := putTestObject(ctx, t, layer, "bucket", "key", []byte("old data"), map[string]string{
+		"X-Amz-Meta-Key":       "old",
+		xhttp.AmzObjectTagging: "version=old",
+	})
+	latest := putTestObject(ctx, t, layer, "bucket", "key", []byte("new data"), map[string]string{
+		"X-Amz-Meta-Key": "new",
+	})
+	require.NotEqual(t, old.VersionID, latest.VersionID)
+
+	t.Run("restore", func(t *testing.T) {
+		srcInfo := copyTestSource(ctx, t, layer, "bucket", "key", old.VersionID, copyDirective)
+		info, err := layer.CopyObject(ctx, "bucket", "key", "bucket", "key", srcInfo, minio.ObjectOptions{VersionID: old.VersionID}, minio.ObjectOptions{})
+		require.NoError(t, err)
+
+		// Copying a version over the same key creates a new version.
+		assert.NotEmpty(t, info.VersionID)
+		assert.NotEqual(t, old.VersionID, info.VersionID)
+		assert.NotEqual(t, latest.VersionID, info.VersionID)
+
+		restored, restoredData := getTestObject(ctx, t, layer, "bucket", "key", "")
+		assert.Equal(t, info.VersionID, restored.VersionID)
+		assert.Equal(t, "old data", string(restoredData))
+		assert.Equal(t, old.ETag, restored.ETag)
+		assert.Equal(t, "old", restored.UserDefined["X-Amz-Meta-Key"])
+		assert.Equal(t, "version=old", restored.UserDefined["s3:tags"])
+
+		// The versions copied over are kept.
+		previous, previousData := getTestObject(ctx, t, layer, "bucket", "key", latest.VersionID)
+		assert.Equal(t, "new data", string(previousData))
+		assert.Equal(t, "new", previous.UserDefined["X-Amz-Meta-Key"])
+	})
+
+	t.Run("replace metadata", func(t *testing.T) {
+		srcInfo := copyTestSource(ctx, t, layer, "bucket", "key", old.VersionID, func(source map[string]string) map[string]string {
+			return map[string]string{"X-Amz-Meta-Other": "other"}
+		})
+		info, err := layer.CopyObject(ctx, "bucket", "key", "other", "copy", srcInfo, minio.ObjectOptions{VersionID: old.VersionID}, minio.ObjectOptions{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, info.VersionID)
+
+		copied, copiedData := getTestObject(ctx, t, layer, "other", "copy", "")
+		assert.Equal(t, info.VersionID, copied.VersionID)
+		assert.Equal(t, "old data", string(copiedData))
+		assert.Equal(t, "other", copied.UserDefined["X-Amz-Meta-Other"])
+		assert.NotContains(t, copied.UserDefined, "X-Amz-Meta-Key")
+		// Tags are kept from the copied version.
+		assert.Equal(t, "version=old", copied.UserDefined["s3:tags"])
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		srcInfo := minio.ObjectInfo{UserDefined: map[string]string{}}
+
+		missing := encodeVersionID(bytes.Repeat([]byte{0xff}, 16))
+		_, err := layer.CopyObject(ctx, "bucket", "key", "other", "copy", srcInfo, minio.ObjectOptions{VersionID: missing}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.ObjectNotFound{})
+
+		_, err = layer.CopyObject(ctx, "bucket", "key", "other", "copy", srcInfo, minio.ObjectOptions{VersionID: "not a version"}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.InvalidVersionID{})
+
+		// The destination is left as it was.
+		copied, _ := getTestObject(ctx, t, layer, "other", "copy", "")
+		assert.Equal(t, "other", copied.UserDefined["X-Amz-Meta-Other"])
+	})
+}
@@ -0,0 +1,753 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/zeebo/errs"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/lifecycle"
+	"storj.io/uplink"
+	versioned "storj.io/uplink/private/object"
+)
+
+// BucketLifecycleLayer is implemented by object layers that support bucket
+// lifecycle configurations. minio's lifecycle.Lifecycle doesn't support
+// AbortIncompleteMultipartUpload, so these methods take a BucketLifecycle
+// instead.
+type BucketLifecycleLayer interface {
+	GetBucketLifecycle(ctx context.Context, bucket string) (*BucketLifecycle, error)
+	SetBucketLifecycle(ctx context.Context, bucket string, config *BucketLifecycle) error
+	DeleteBucketLifecycle(ctx context.Context, bucket string) error
+}
+
+var (
+	_ BucketLifecycleLayer = (*gatewayLayer)(nil)
+	_ BucketLifecycleLayer = (*singleTenancyLayer)(nil)
+)
+
+// ErrMalformedLifecycle is a custom error for lifecycle configurations that
+// are invalid.
+var ErrMalformedLifecycle = miniogo.ErrorResponse{
+	Code:       "MalformedXML",
+	StatusCode: http.StatusBadRequest,
+	Message:    "The lifecycle configuration you provided is not well-formed or did not validate.",
+}
+
+// storeLifecycleKey is the metadata store key of the lifecycle configuration
+// of a bucket.
+var storeLifecycleKey = []byte("lifecycle")
+
+// maxLifecycleRules is the maximum number of rules of a lifecycle
+// configuration.
+const maxLifecycleRules = 1000
+
+// BucketLifecycle is the lifecycle configuration of a bucket. Objects and
+// noncurrent versions can be expired and incomplete multipart uploads
+// aborted. Transitions aren't supported, as there's only one storage class.
+type BucketLifecycle struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LifecycleConfiguration"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// LifecycleRule is a rule of a lifecycle configuration.
+type LifecycleRule struct {
+	ID     string           `xml:"ID,omitempty"`
+	Status lifecycle.Status `xml:"Status"`
+	// Prefix is the deprecated alternative to Filter.
+	Prefix *string          `xml:"Prefix,omitempty"`
+	Filter *LifecycleFilter `xml:"Filter,omitempty"`
+
+	Expiration                     *LifecycleExpiration                     `xml:"Expiration,omitempty"`
+	NoncurrentVersionExpiration    *LifecycleNoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *LifecycleAbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+
+	// Transitions are rejected.
+	Transitions                  []struct{} `xml:"Transition,omitempty"`
+	NoncurrentVersionTransitions []struct{} `xml:"NoncurrentVersionTransition,omitempty"`
+}
+
+// LifecycleFilter selects objects a rule applies to. At most one of its
+// fields is set; And combines several conditions.
+type LifecycleFilter struct {
+	Prefix                *string             `xml:"Prefix,omitempty"`
+	Tag                   *LifecycleTag       `xml:"Tag,omitempty"`
+	ObjectSizeGreaterThan *int64              `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    *int64              `xml:"ObjectSizeLessThan,omitempty"`
+	And                   *LifecycleFilterAnd `xml:"And,omitempty"`
+}
+
+// LifecycleFilterAnd selects objects matching all of its conditions.
+type LifecycleFilterAnd struct {
+	Prefix                string         `xml:"Prefix,omitempty"`
+	Tags                  []LifecycleTag `xml:"Tag,omitempty"`
+	ObjectSizeGreaterThan *int64         `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    *int64         `xml:"ObjectSizeLessThan,omitempty"`
+}
+
+// LifecycleTag is an object tag a rule filters on.
+type LifecycleTag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// LifecycleExpiration expires current object versions. Exactly one of its
+// fields is set.
+type LifecycleExpiration struct {
+	Days                      int        `xml:"Days,omitempty"`
+	Date                      *time.Time `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker *bool      `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+// LifecycleNoncurrentVersionExpiration expires noncurrent object versions
+// NoncurrentDays after they become noncurrent, keeping the
+// NewerNoncurrentVersions newest of them.
+type LifecycleNoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
+}
+
+// LifecycleAbortIncompleteMultipartUpload aborts multipart uploads
+// DaysAfterInitiation after they began.
+type LifecycleAbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// ParseBucketLifecycle parses and validates a lifecycle configuration.
+func ParseBucketLifecycle(r io.Reader) (*BucketLifecycle, error) {
+	var config struct {
+		XMLName xml.Name
+		Rules   []LifecycleRule `xml:"Rule"`
+	}
+	if err := xml.NewDecoder(r).Decode(&config); err != nil {
+		return nil, ErrMalformedLifecycle
+	}
+	switch config.XMLName.Local {
+	case "LifecycleConfiguration", "BucketLifecycleConfiguration":
+	default:
+		return nil, ErrMalformedLifecycle
+	}
+
+	lifecycle := &BucketLifecycle{Rules: config.Rules}
+	if err := lifecycle.Validate(); err != nil {
+		return nil, err
+	}
+
+	return lifecycle, nil
+}
+
+// Validate returns an error if config is invalid or uses unsupported
+// features.
+func (config *BucketLifecycle) Validate() error {
+	if len(config.Rules) == 0 || len(config.Rules) > maxLifecycleRules {
+		return ErrMalformedLifecycle
+	}
+
+	ids := make(map[string]struct{}, len(config.Rules))
+	for _, rule := range config.Rules {
+		if rule.ID != "" {
+			if _, ok := ids[rule.ID]; ok || len(rule.ID) > 255 {
+				return ErrMalformedLifecycle
+			}
+			ids[rule.ID] = struct{}{}
+		}
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rule LifecycleRule) validate() error {
+	if len(rule.Transitions) > 0 || len(rule.NoncurrentVersionTransitions) > 0 {
+		return minio.NotImplemented{Message: "Lifecycle transitions"}
+	}
+
+	if rule.Status != lifecycle.Enabled && rule.Status != lifecycle.Disabled {
+		return ErrMalformedLifecycle
+	}
+
+	if rule.Prefix != nil && rule.Filter != nil {
+		return ErrMalformedLifecycle
+	}
+	if rule.Filter != nil && !rule.Filter.valid() {
+		return ErrMalformedLifecycle
+	}
+
+	if rule.Expiration == nil && rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return ErrMalformedLifecycle
+	}
+
+	if expiration := rule.Expiration; expiration != nil {
+		set := 0
+		if expiration.Days != 0 {
+			if expiration.Days < 0 {
+				return ErrMalformedLifecycle
+			}
+			set++
+		}
+		if expiration.Date != nil {
+			set++
+		}
+		if expiration.ExpiredObjectDeleteMarker != nil {
+			set++
+		}
+		if set != 1 {
+			return ErrMalformedLifecycle
+		}
+	}
+
+	if expiration := rule.NoncurrentVersionExpiration; expiration != nil {
+		if expiration.NoncurrentDays <= 0 || expiration.NewerNoncurrentVersions < 0 {
+			return ErrMalformedLifecycle
+		}
+	}
+
+	if abort := rule.AbortIncompleteMultipartUpload; abort != nil {
+		if abort.DaysAfterInitiation <= 0 {
+			return ErrMalformedLifecycle
+		}
+		// Uploads have neither tags nor a size yet.
+		if filter := rule.Filter; filter != nil && (filter.Tag != nil || filter.And != nil ||
+			filter.ObjectSizeGreaterThan != nil || filter.ObjectSizeLessThan != nil) {
+			return ErrMalformedLifecycle
+		}
+	}
+
+	return nil
+}
+
+func (filter LifecycleFilter) valid() bool {
+	set := 0
+	for _, ok := range []bool{
+		filter.Prefix != nil,
+		filter.Tag != nil,
+		filter.ObjectSizeGreaterThan != nil,
+		filter.ObjectSizeLessThan != nil,
+		filter.And != nil,
+	} {
+		if ok {
+			set++
+		}
+	}
+	return set <= 1
+}
+
+// prefix returns the key prefix rule applies to.
+func (rule LifecycleRule) prefix() string {
+	switch {
+	case rule.Prefix != nil:
+		return *rule.Prefix
+	case rule.Filter == nil:
+		return ""
+	case rule.Filter.Prefix != nil:
+		return *rule.Filter.Prefix
+	case rule.Filter.And != nil:
+		return rule.Filter.And.Prefix
+	}
+	return ""
+}
+
+// matches returns whether rule applies to the object at key with tags (in
+// the format of X-Amz-Tagging) and size, which is negative if unknown.
+// Rules filtering on size don't apply to objects of unknown size.
+func (rule LifecycleRule) matches(key, tags string, size int64) bool {
+	if rule.Status != lifecycle.Enabled || !strings.HasPrefix(key, rule.prefix()) {
+		return false
+	}
+	if rule.Filter == nil {
+		return true
+	}
+
+	var (
+		filterTags            []LifecycleTag
+		greaterThan, lessThan *int64
+	)
+	switch filter := rule.Filter; {
+	case filter.Tag != nil:
+		filterTags = []LifecycleTag{*filter.Tag}
+	case filter.And != nil:
+		filterTags = filter.And.Tags
+		greaterThan, lessThan = filter.And.ObjectSizeGreaterThan, filter.And.ObjectSizeLessThan
+	default:
+		greaterThan, lessThan = filter.ObjectSizeGreaterThan, filter.ObjectSizeLessThan
+	}
+
+	if greaterThan != nil && (size < 0 || size <= *greaterThan) {
+		return false
+	}
+	if lessThan != nil && (size < 0 || size >= *lessThan) {
+		return false
+	}
+
+	if len(filterTags) > 0 {
+		values, err := url.ParseQuery(tags)
+		if err != nil {
+			return false
+		}
+		for _, tag := range filterTags {
+			if !values.Has(tag.Key) || values.Get(tag.Key) != tag.Value {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// expiration returns when the current version of an object created at
+// created at key with tags and size expires according to rules of config,
+// and the ID of the rule expiring it, or zero time if it doesn't.
+func (config *BucketLifecycle) expiration(key, tags string, size int64, created time.Time) (expires time.Time, ruleID string) {
+	for _, rule := range config.Rules {
+		if rule.Expiration == nil || !rule.matches(key, tags, size) {
+			continue
+		}
+
+		var t time.Time
+		switch {
+		case rule.Expiration.Date != nil:
+			t = *rule.Expiration.Date
+		case rule.Expiration.Days > 0:
+			t = lifecycle.ExpectedExpiryTime(created, rule.Expiration.Days)
+		default:
+			continue
+		}
+
+		if expires.IsZero() || t.Before(expires) {
+			expires, ruleID = t, rule.ID
+		}
+	}
+	return expires, ruleID
+}
+
+// expiredVersions returns the versions of the object at key to delete at now
+// according to rules of config. versions are all versions of the object,
+// newest first. A nil version means the current version should be expired,
+// i.e., deleted or hidden behind a delete marker.
+func (config *BucketLifecycle) expiredVersions(key string, versions []*versioned.VersionedObject, now time.Time) (expired [][]byte) {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	latest := versions[0]
+	if latest.IsDeleteMarker {
+		// A delete marker without noncurrent versions is expired.
+		if len(versions) == 1 {
+			for _, rule := range config.Rules {
+				if rule.Expiration != nil && rule.Expiration.ExpiredObjectDeleteMarker != nil &&
+					*rule.Expiration.ExpiredObjectDeleteMarker && rule.matches(key, "", -1) {
+					return [][]byte{latest.Version}
+				}
+			}
+		}
+	} else {
+		expires, _ := config.expiration(key, latest.Custom["s3:tags"], objectSize(&latest.Object), latest.System.Created)
+		if !expires.IsZero() && !now.Before(expires) {
+			expired = append(expired, nil)
+		}
+	}
+
+	for i := 1; i < len(versions); i++ {
+		version := versions[i]
+		// A version becomes noncurrent when the next one is created.
+		noncurrentSince := versions[i-1].System.Created
+
+		for _, rule := range config.Rules {
+			expiration := rule.NoncurrentVersionExpiration
+			if expiration == nil || !rule.matches(key, version.Custom["s3:tags"], objectSize(&version.Object)) {
+				continue
+			}
+			if i-1 < expiration.NewerNoncurrentVersions {
+				continue
+			}
+			if !now.Before(lifecycle.ExpectedExpiryTime(noncurrentSince, expiration.NoncurrentDays)) {
+				expired = append(expired, version.Version)
+				break
+			}
+		}
+	}
+
+	return expired
+}
+
+// abortsUpload returns whether the multipart upload of key that began at
+// created should be aborted at now according to rules of config.
+func (config *BucketLifecycle) abortsUpload(key string, created, now time.Time) bool {
+	for _, rule := range config.Rules {
+		abort := rule.AbortIncompleteMultipartUpload
+		if abort == nil || !rule.matches(key, "", -1) {
+			continue
+		}
+		if !now.Before(lifecycle.ExpectedExpiryTime(created, abort.DaysAfterInitiation)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetBucketLifecycle returns the lifecycle configuration of bucket.
+func (layer *gatewayLayer) GetBucketLifecycle(ctx context.Context, bucketName string) (_ *BucketLifecycle, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return nil, minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := project.StatBucket(ctx, bucketName); err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+
+	config, err := layer.bucketLifecycle(ctx, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if config == nil {
+		return nil, minio.BucketLifecycleNotFound{Bucket: bucketName}
+	}
+
+	return config, nil
+}
+
+// SetBucketLifecycle sets the lifecycle configuration of bucket. Rules are
+// kept in the metadata store.
+func (layer *gatewayLayer) SetBucketLifecycle(ctx context.Context, bucketName string, config *BucketLifecycle) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "PutBucketLifecycle"}
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := project.StatBucket(ctx, bucketName); err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	value, err := xml.Marshal(config)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	if err := layer.metadataStore.put(storeBucketConfig, storeBucket(ctx, bucketName), storeLifecycleKey, value); err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	// Enforce the new rules on existing objects right away.
+	layer.lifecycle.forget(ctx, bucketName)
+	layer.lifecycle.enforceAsync(ctx, layer, project, bucketName, config)
+
+	return nil
+}
+
+// DeleteBucketLifecycle removes the lifecycle configuration of bucket.
+// Expiration already given to objects isn't removed.
+func (layer *gatewayLayer) DeleteBucketLifecycle(ctx context.Context, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := project.StatBucket(ctx, bucketName); err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storeLifecycleKey), bucketName, "")
+}
+
+// bucketLifecycle returns the lifecycle configuration of bucket or nil if it
+// has none.
+func (layer *gatewayLayer) bucketLifecycle(ctx context.Context, bucketName string) (*BucketLifecycle, error) {
+	if layer.metadataStore == nil {
+		return nil, nil
+	}
+
+	value, found, err := layer.metadataStore.get(storeBucketConfig, storeBucket(ctx, bucketName), storeLifecycleKey)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var config BucketLifecycle
+	if err := xml.Unmarshal(value, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// lifecycleExpiration returns when an object uploaded now to bucket at key
+// with tags (in the format of X-Amz-Tagging) and size, which is negative if
+// unknown, expires according to the lifecycle configuration of bucket, or
+// zero time if it doesn't. It also schedules enforcing the configuration on
+// existing objects using project and the project ID in ctx.
+func (layer *gatewayLayer) lifecycleExpiration(ctx context.Context, project *uplink.Project, bucketName, key, tags string, size int64) (time.Time, error) {
+	config, err := layer.bucketLifecycle(ctx, bucketName)
+	if err != nil || config == nil {
+		return time.Time{}, err
+	}
+
+	layer.lifecycle.enforceAsync(ctx, layer, project, bucketName, config)
+
+	expires, _ := config.expiration(key, tags, size, time.Now())
+	return expires, nil
+}
+
+// scheduleLifecycle schedules enforcing the lifecycle configuration of
+// bucket, if it has one, on existing objects using project and the project ID
+// in ctx.
+func (layer *gatewayLayer) scheduleLifecycle(ctx context.Context, project *uplink.Project, bucketName string) {
+	config, err := layer.bucketLifecycle(ctx, bucketName)
+	if err != nil {
+		layer.logger.Infof("lifecycle: reading configuration of %q failed: %v", bucketName, err)
+		return
+	}
+	if config != nil {
+		layer.lifecycle.enforceAsync(ctx, layer, project, bucketName, config)
+	}
+}
+
+// earliestExpiration returns the earlier of non-zero expirations a and b.
+func earliestExpiration(a, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+// enforceLifecycle expires objects and aborts multipart uploads in bucket
+// according to config at now. Objects are deleted through layer, so locked
+// versions are kept.
+func (layer *gatewayLayer) enforceLifecycle(ctx context.Context, project *uplink.Project, bucketName string, config *BucketLifecycle, now time.Time) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx = WithUplinkProject(ctx, project)
+
+	var expires, aborts bool
+	for _, rule := range config.Rules {
+		if rule.Status != lifecycle.Enabled {
+			continue
+		}
+		expires = expires || rule.Expiration != nil || rule.NoncurrentVersionExpiration != nil
+		aborts = aborts || rule.AbortIncompleteMultipartUpload != nil
+	}
+
+	var group errs.Group
+	if expires {
+		group.Add(layer.expireObjects(ctx, project, bucketName, config, now))
+	}
+	if aborts {
+		group.Add(layer.abortIncompleteUploads(ctx, project, bucketName, config, now))
+	}
+	return group.Err()
+}
+
+func (layer *gatewayLayer) expireObjects(ctx context.Context, project *uplink.Project, bucketName string, config *BucketLifecycle, now time.Time) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx = withNotificationSource(ctx, notifyLifecycle)
+
+	var (
+		key      string
+		versions []*versioned.VersionedObject
+	)
+
+	expire := func() {
+		for _, version := range config.expiredVersions(key, versions, now) {
+			_, err := layer.DeleteObject(ctx, bucketName, key, minio.ObjectOptions{VersionID: encodeVersionID(version)})
+			if err != nil {
+				layer.logger.Infof("lifecycle: expiring %q (version %q) in %q failed: %v", key, encodeVersionID(version), bucketName, err)
+			}
+		}
+	}
+
+	opts := &versioned.ListObjectVersionsOptions{
+		Recursive: true,
+		System:    true,
+		Custom:    true,
+		Limit:     layer.compatibilityConfig.MaxKeysLimit,
+	}
+	for {
+		items, more, err := versioned.ListObjectVersions(ctx, project, bucketName, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if item.Key != key {
+				expire()
+				key, versions = item.Key, versions[:0]
+			}
+			versions = append(versions, item)
+		}
+
+		if !more || len(items) == 0 {
+			break
+		}
+		last := items[len(items)-1]
+		opts.Cursor, opts.VersionCursor = last.Key, last.Version
+	}
+	expire()
+
+	return nil
+}
+
+func (layer *gatewayLayer) abortIncompleteUploads(ctx context.Context, project *uplink.Project, bucketName string, config *BucketLifecycle, now time.Time) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	uploads := project.ListUploads(ctx, bucketName, &uplink.ListUploadsOptions{
+		Recursive: true,
+		System:    true,
+	})
+	for uploads.Next() {
+		upload := uploads.Item()
+		if !config.abortsUpload(upload.Key, upload.System.Created, now) {
+			continue
+		}
+		if err := layer.AbortMultipartUpload(ctx, bucketName, upload.Key, upload.UploadID, minio.ObjectOptions{}); err != nil {
+			layer.logger.Infof("lifecycle: aborting upload %q of %q in %q failed: %v", upload.UploadID, upload.Key, bucketName, err)
+		}
+	}
+
+	return uploads.Err()
+}
+
+// lifecycleWorker enforces lifecycle configurations on existing objects and
+// multipart uploads in the background. The gateway has no credentials of its
+// own, so a bucket is enforced using the project of a request to it once
+// the enforce interval has passed since it was last enforced.
+type lifecycleWorker struct {
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	// enforced holds when each bucket was last enforced. A bucket is being
+	// enforced if it has an entry in running. Buckets are keyed like in the
+	// metadata store, as their names are only unique within a project.
+	enforced map[projectBucket]time.Time
+	running  map[projectBucket]struct{}
+}
+
+func newLifecycleWorker(config LifecycleConfig) *lifecycleWorker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &lifecycleWorker{
+		interval: config.EnforceInterval,
+		ctx:      ctx,
+		cancel:   cancel,
+		enforced: make(map[projectBucket]time.Time),
+		running:  make(map[projectBucket]struct{}),
+	}
+}
+
+// Close stops any enforcement in progress.
+func (worker *lifecycleWorker) Close() {
+	if worker == nil {
+		return
+	}
+
+	worker.mu.Lock()
+	worker.closed = true
+	worker.mu.Unlock()
+
+	worker.cancel()
+	worker.wg.Wait()
+}
+
+// enforceAsync starts enforcing config on bucket in the background using
+// project unless it has been enforced recently or is being enforced. The
+// project ID in ctx, the context of the request that triggered it, tells
+// buckets of different projects apart and is kept so that the enforcement
+// reads metadata of the bucket and removes expired objects from the listing
+// index of the right project.
+func (worker *lifecycleWorker) enforceAsync(ctx context.Context, layer *gatewayLayer, project *uplink.Project, bucket string, config *BucketLifecycle) {
+	if worker == nil {
+		return
+	}
+
+	key := storeBucket(ctx, bucket)
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+
+	if _, ok := worker.running[key]; ok || worker.closed {
+		return
+	}
+	if time.Since(worker.enforced[key]) < worker.interval {
+		return
+	}
+	worker.running[key] = struct{}{}
+
+	worker.wg.Add(1)
+	go func() {
+		defer worker.wg.Done()
+
+		now := time.Now()
+		err := layer.enforceLifecycle(withProjectOf(worker.ctx, ctx), project, bucket, config, now)
+		if err != nil {
+			layer.logger.Infof("lifecycle: enforcing rules of %q failed: %v", bucket, err)
+		}
+
+		worker.mu.Lock()
+		defer worker.mu.Unlock()
+
+		delete(worker.running, key)
+		if err == nil {
+			worker.enforced[key] = now
+		}
+	}()
+}
+
+// forget makes the next enforceAsync call for bucket of the project in ctx
+// enforce it regardless of when it was last enforced.
+func (worker *lifecycleWorker) forget(ctx context.Context, bucket string) {
+	if worker == nil {
+		return
+	}
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+
+	delete(worker.enforced, storeBucket(ctx, bucket))
+}
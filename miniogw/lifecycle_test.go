@@ -0,0 +1,312 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	miniolifecycle "github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/lifecycle"
+	"storj.io/uplink"
+	versioned "storj.io/uplink/private/object"
+)
+
+func TestParseBucketLifecycle(t *testing.T) {
+	config, err := ParseBucketLifecycle(strings.NewReader(`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+		<Rule>
+			<ID>logs</ID>
+			<Status>Enabled</Status>
+			<Filter><And><Prefix>logs/</Prefix><Tag><Key>class</Key><Value>tmp</Value></Tag></And></Filter>
+			<Expiration><Days>30</Days></Expiration>
+			<NoncurrentVersionExpiration><NoncurrentDays>7</NoncurrentDays></NoncurrentVersionExpiration>
+		</Rule>
+		<Rule>
+			<ID>uploads</ID>
+			<Status>Enabled</Status>
+			<Filter><Prefix></Prefix></Filter>
+			<AbortIncompleteMultipartUpload><DaysAfterInitiation>1</DaysAfterInitiation></AbortIncompleteMultipartUpload>
+		</Rule>
+	</LifecycleConfiguration>`))
+	require.NoError(t, err)
+	require.Len(t, config.Rules, 2)
+	assert.Equal(t, 30, config.Rules[0].Expiration.Days)
+	assert.Equal(t, "logs/", config.Rules[0].prefix())
+	assert.Equal(t, 1, config.Rules[1].AbortIncompleteMultipartUpload.DaysAfterInitiation)
+
+	for _, body := range []string{
+		`not xml`,
+		`<Other><Rule><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule></Other>`,
+		`<LifecycleConfiguration></LifecycleConfiguration>`,
+		// unknown status
+		`<LifecycleConfiguration><Rule><Status>On</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+		// no action
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status></Rule></LifecycleConfiguration>`,
+		// both Days and Date
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Expiration><Days>1</Days><Date>2026-01-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`,
+		// duplicate IDs
+		`<LifecycleConfiguration><Rule><ID>a</ID><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule>` +
+			`<Rule><ID>a</ID><Status>Enabled</Status><Expiration><Days>2</Days></Expiration></Rule></LifecycleConfiguration>`,
+		// both Prefix and Filter
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Prefix>a</Prefix><Filter><Prefix>a</Prefix></Filter><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+		// aborting uploads filtered by tags
+		`<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Tag><Key>a</Key><Value>b</Value></Tag></Filter>` +
+			`<AbortIncompleteMultipartUpload><DaysAfterInitiation>1</DaysAfterInitiation></AbortIncompleteMultipartUpload></Rule></LifecycleConfiguration>`,
+	} {
+		_, err := ParseBucketLifecycle(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrMalformedLifecycle, body)
+	}
+
+	_, err = ParseBucketLifecycle(strings.NewReader(`<LifecycleConfiguration><Rule><Status>Enabled</Status>` +
+		`<Transition><Days>1</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`))
+	assert.ErrorAs(t, err, &minio.NotImplemented{})
+}
+
+func TestLifecycleRuleMatches(t *testing.T) {
+	prefix := "logs/"
+	size := int64(100)
+
+	rule := LifecycleRule{Status: lifecycle.Enabled, Prefix: &prefix}
+	assert.True(t, rule.matches("logs/a", "", -1))
+	assert.False(t, rule.matches("data/a", "", -1))
+
+	rule.Status = lifecycle.Disabled
+	assert.False(t, rule.matches("logs/a", "", -1))
+
+	rule = LifecycleRule{Status: lifecycle.Enabled, Filter: &LifecycleFilter{Tag: &LifecycleTag{Key: "class", Value: "tmp"}}}
+	assert.True(t, rule.matches("a", "class=tmp&other=1", -1))
+	assert.False(t, rule.matches("a", "class=keep", -1))
+	assert.False(t, rule.matches("a", "", -1))
+
+	rule = LifecycleRule{Status: lifecycle.Enabled, Filter: &LifecycleFilter{ObjectSizeGreaterThan: &size}}
+	assert.True(t, rule.matches("a", "", 101))
+	assert.False(t, rule.matches("a", "", 100))
+	assert.False(t, rule.matches("a", "", -1))
+
+	rule = LifecycleRule{Status: lifecycle.Enabled, Filter: &LifecycleFilter{And: &LifecycleFilterAnd{
+		Prefix:             prefix,
+		Tags:               []LifecycleTag{{Key: "class", Value: "tmp"}},
+		ObjectSizeLessThan: &size,
+	}}}
+	assert.True(t, rule.matches("logs/a", "class=tmp", 99))
+	assert.False(t, rule.matches("logs/a", "class=tmp", 100))
+	assert.False(t, rule.matches("data/a", "class=tmp", 99))
+}
+
+func TestBucketLifecycleExpiration(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	prefix := "tmp/"
+
+	config := &BucketLifecycle{Rules: []LifecycleRule{
+		{ID: "month", Status: lifecycle.Enabled, Expiration: &LifecycleExpiration{Days: 30}},
+		{ID: "tmp", Status: lifecycle.Enabled, Prefix: &prefix, Expiration: &LifecycleExpiration{Days: 1}},
+		{ID: "date", Status: lifecycle.Disabled, Expiration: &LifecycleExpiration{Date: &date}},
+	}}
+
+	created := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expires, ruleID := config.expiration("a", "", -1, created)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), expires)
+	assert.Equal(t, "month", ruleID)
+
+	expires, ruleID = config.expiration("tmp/a", "", -1, created)
+	assert.Equal(t, time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), expires)
+	assert.Equal(t, "tmp", ruleID)
+
+	config.Rules[2].Status = lifecycle.Enabled
+	expires, ruleID = config.expiration("a", "", -1, created.AddDate(0, 2, 0))
+	assert.Equal(t, date, expires)
+	assert.Equal(t, "date", ruleID)
+
+	expires, _ = (&BucketLifecycle{}).expiration("a", "", -1, created)
+	assert.True(t, expires.IsZero())
+
+	assert.Equal(t, created, earliestExpiration(time.Time{}, created))
+	assert.Equal(t, created, earliestExpiration(created, time.Time{}))
+	assert.Equal(t, created, earliestExpiration(created.Add(time.Hour), created))
+}
+
+func TestBucketLifecycleExpiredVersions(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	version := func(id byte, age time.Duration, deleteMarker bool) *versioned.VersionedObject {
+		return &versioned.VersionedObject{
+			Object:         uplink.Object{System: uplink.SystemMetadata{Created: now.Add(-age)}},
+			Version:        []byte{id},
+			IsDeleteMarker: deleteMarker,
+		}
+	}
+
+	config := &BucketLifecycle{Rules: []LifecycleRule{
+		{Status: lifecycle.Enabled, Expiration: &LifecycleExpiration{Days: 10}},
+		{Status: lifecycle.Enabled, NoncurrentVersionExpiration: &LifecycleNoncurrentVersionExpiration{NoncurrentDays: 2, NewerNoncurrentVersions: 1}},
+	}}
+
+	// The current version is recent, version 2 is kept as the newest
+	// noncurrent version and version 3 became noncurrent 10 days ago.
+	versions := []*versioned.VersionedObject{
+		version(1, 5*day, false),
+		version(2, 10*day, false),
+		version(3, 20*day, false),
+	}
+	assert.Equal(t, [][]byte{{3}}, config.expiredVersions("a", versions, now))
+
+	versions = []*versioned.VersionedObject{version(1, 11*day, false)}
+	assert.Equal(t, [][]byte{nil}, config.expiredVersions("a", versions, now))
+
+	// Delete markers are only expired by ExpiredObjectDeleteMarker.
+	versions = []*versioned.VersionedObject{version(1, 20*day, true)}
+	assert.Empty(t, config.expiredVersions("a", versions, now))
+
+	expired := true
+	config.Rules = append(config.Rules, LifecycleRule{Status: lifecycle.Enabled, Expiration: &LifecycleExpiration{ExpiredObjectDeleteMarker: &expired}})
+	assert.Equal(t, [][]byte{{1}}, config.expiredVersions("a", versions, now))
+
+	versions = append(versions, version(2, 30*day, false))
+	assert.Empty(t, config.expiredVersions("a", versions, now))
+}
+
+func TestBucketLifecycleAbortsUpload(t *testing.T) {
+	prefix := "tmp/"
+	config := &BucketLifecycle{Rules: []LifecycleRule{
+		{Status: lifecycle.Enabled, Prefix: &prefix, AbortIncompleteMultipartUpload: &LifecycleAbortIncompleteMultipartUpload{DaysAfterInitiation: 1}},
+	}}
+
+	now := time.Date(2026, 6, 2, 12, 0, 0, 0, time.UTC)
+	assert.True(t, config.abortsUpload("tmp/a", now.Add(-48*time.Hour), now))
+	assert.False(t, config.abortsUpload("tmp/a", now.Add(-time.Hour), now))
+	assert.False(t, config.abortsUpload("a", now.Add(-36*time.Hour), now))
+}
+
+func TestBucketLifecycleStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	config, err := layer.bucketLifecycle(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	prefix := "logs/"
+	value, err := xml.Marshal(&BucketLifecycle{Rules: []LifecycleRule{
+		{Status: lifecycle.Enabled, Filter: &LifecycleFilter{Prefix: &prefix}, Expiration: &LifecycleExpiration{Days: 3}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeBucketConfig, projectBucket{name: "bucket"}, storeLifecycleKey, value))
+
+	config, err = layer.bucketLifecycle(ctx, "bucket")
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	require.Len(t, config.Rules, 1)
+	assert.Equal(t, &prefix, config.Rules[0].Filter.Prefix)
+	assert.Equal(t, 3, config.Rules[0].Expiration.Days)
+
+	require.NoError(t, store.dropBucket(projectBucket{name: "bucket"}))
+
+	config, err = layer.bucketLifecycle(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Nil(t, config)
+}
+
+func TestLifecycleWorkerClosed(t *testing.T) {
+	worker := newLifecycleWorker(LifecycleConfig{EnforceInterval: time.Hour})
+	worker.Close()
+
+	// Nothing is enforced once the worker is closed.
+	worker.enforceAsync(context.Background(), &gatewayLayer{}, nil, "bucket", &BucketLifecycle{})
+	assert.Empty(t, worker.running)
+
+	var nilWorker *lifecycleWorker
+	nilWorker.enforceAsync(context.Background(), &gatewayLayer{}, nil, "bucket", &BucketLifecycle{})
+	nilWorker.Close()
+}
+
+// lifecycleTestLayer is an object layer keeping lifecycle configurations in
+// memory.
+type lifecycleTestLayer struct {
+	minio.ObjectLayer
+
+	configs map[string]*BucketLifecycle
+}
+
+func (layer *lifecycleTestLayer) GetBucketLifecycle(ctx context.Context, bucket string) (*BucketLifecycle, error) {
+	config, ok := layer.configs[bucket]
+	if !ok {
+		return nil, minio.BucketLifecycleNotFound{Bucket: bucket}
+	}
+	return config, nil
+}
+
+func (layer *lifecycleTestLayer) SetBucketLifecycle(ctx context.Context, bucket string, config *BucketLifecycle) error {
+	layer.configs[bucket] = config
+	return nil
+}
+
+func (layer *lifecycleTestLayer) DeleteBucketLifecycle(ctx context.Context, bucket string) error {
+	delete(layer.configs, bucket)
+	return nil
+}
+
+func TestBucketLifecycleAPI(t *testing.T) {
+	layer := &lifecycleTestLayer{configs: make(map[string]*BucketLifecycle)}
+	client, _ := startAPITestServer(t, layer)
+
+	ctx := context.Background()
+
+	_, err := client.GetBucketLifecycle(ctx, "bucket")
+	assert.Equal(t, "NoSuchLifecycleConfiguration", miniogo.ToErrorResponse(err).Code)
+
+	config := miniolifecycle.NewConfiguration()
+	config.Rules = []miniolifecycle.Rule{{
+		ID:         "logs",
+		Status:     "Enabled",
+		RuleFilter: miniolifecycle.Filter{Prefix: "logs/"},
+		Expiration: miniolifecycle.Expiration{Days: 3},
+	}}
+	require.NoError(t, client.SetBucketLifecycle(ctx, "bucket", config))
+
+	require.Contains(t, layer.configs, "bucket")
+	require.Len(t, layer.configs["bucket"].Rules, 1)
+	assert.Equal(t, 3, layer.configs["bucket"].Rules[0].Expiration.Days)
+
+	got, err := client.GetBucketLifecycle(ctx, "bucket")
+	require.NoError(t, err)
+	require.Len(t, got.Rules, 1)
+	assert.Equal(t, "logs", got.Rules[0].ID)
+	assert.Equal(t, "logs/", got.Rules[0].RuleFilter.Prefix)
+	assert.EqualValues(t, 3, got.Rules[0].Expiration.Days)
+
+	// Invalid configurations are rejected.
+	config.Rules[0].Expiration.Days = 0
+	err = client.SetBucketLifecycle(ctx, "bucket", config)
+	assert.Equal(t, "MalformedXML", miniogo.ToErrorResponse(err).Code)
+
+	// Setting an empty configuration deletes it.
+	require.NoError(t, client.SetBucketLifecycle(ctx, "bucket", miniolifecycle.NewConfiguration()))
+	assert.Empty(t, layer.configs)
+
+	// Requests that aren't allowed don't reach the layer.
+	other, err := miniogo.New(client.EndpointURL().Host, &miniogo.Options{
+		Creds:  credentials.NewStaticV4("other", "secret", ""),
+		Region: "us-east-1",
+	})
+	require.NoError(t, err)
+	err = other.SetBucketLifecycle(ctx, "bucket", config)
+	assert.Equal(t, "AccessDenied", miniogo.ToErrorResponse(err).Code)
+	assert.Empty(t, layer.configs)
+}
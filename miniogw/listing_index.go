@@ -0,0 +1,643 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.etcd.io/bbolt"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/uplink"
+)
+
+// listingIndexError is the error class for the local listing index.
+var listingIndexError = errs.Class("listing index")
+
+var (
+	indexRootBucket    = []byte("buckets")
+	indexCurrentKey    = []byte("current")
+	indexReconciledKey = []byte("reconciled")
+)
+
+// reconcileBatchSize is the number of listed objects written to the index in a
+// single transaction during reconciliation.
+const reconcileBatchSize = 1000
+
+// listingIndex is a persistent, local index of object keys. It allows serving
+// lexicographically ordered listings with arbitrary prefixes and delimiters
+// without listing entire buckets on the satellite.
+//
+// The index is kept in sync by object-modifying calls that go through
+// gatewayLayer and periodically reconciled with the satellite to pick up
+// changes made elsewhere. A bucket's index serves listings only after it has
+// been reconciled at least once.
+//
+// Bucket names are only unique within a project, so the database holds a
+// nested bucket for each indexed bucket of each project. Each of them
+// contains generations of the index (nested buckets themselves), the number of
+// the current generation and the time of the last successful reconciliation.
+// Reconciliation fills a new generation and swaps it with the current one,
+// carrying over keys modified in the meantime.
+type listingIndex struct {
+	db                *bbolt.DB
+	reconcileInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+	// dirty holds keys modified while a bucket is being reconciled. A bucket
+	// is being reconciled if and only if it has an entry here.
+	dirty map[projectBucket]map[string]struct{}
+}
+
+// indexEntry is the value stored for each key in the listing index.
+type indexEntry struct {
+	Size    int64             `json:"size"`
+	Created time.Time         `json:"created"`
+	Expires time.Time         `json:"expires,omitempty"`
+	Custom  map[string]string `json:"custom,omitempty"`
+}
+
+// indexItem is a single result of listing the index.
+type indexItem struct {
+	Key      string
+	IsPrefix bool
+	Entry    indexEntry
+}
+
+func openListingIndex(config ListingIndexConfig) (*listingIndex, error) {
+	db, err := bbolt.Open(config.Path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, listingIndexError.Wrap(err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(indexRootBucket)
+		return err
+	})
+	if err != nil {
+		return nil, listingIndexError.Wrap(errs.Combine(err, db.Close()))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &listingIndex{
+		db:                db,
+		reconcileInterval: config.ReconcileInterval,
+		ctx:               ctx,
+		cancel:            cancel,
+		dirty:             make(map[projectBucket]map[string]struct{}),
+	}, nil
+}
+
+// Close stops any reconciliation in progress and closes the index database.
+func (index *listingIndex) Close() error {
+	index.mu.Lock()
+	index.closed = true
+	index.mu.Unlock()
+
+	index.cancel()
+	index.wg.Wait()
+
+	return listingIndexError.Wrap(index.db.Close())
+}
+
+// ensure reports whether the index of bucket can serve listings. It schedules
+// a background reconciliation using project if the index of bucket is missing
+// or older than the reconcile interval.
+func (index *listingIndex) ensure(log debugLogger, project *uplink.Project, bucket projectBucket) (ready bool, err error) {
+	var reconciled time.Time
+
+	err = index.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(indexRootBucket).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(indexReconciledKey); v != nil {
+			return reconciled.UnmarshalBinary(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, listingIndexError.Wrap(err)
+	}
+
+	if time.Since(reconciled) >= index.reconcileInterval {
+		index.reconcileAsync(log, project, bucket)
+	}
+
+	return !reconciled.IsZero(), nil
+}
+
+// reconcileAsync starts reconciling bucket in the background unless it's
+// already being reconciled.
+func (index *listingIndex) reconcileAsync(log debugLogger, project *uplink.Project, bucket projectBucket) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if _, ok := index.dirty[bucket]; ok || index.closed {
+		return
+	}
+	index.dirty[bucket] = make(map[string]struct{})
+
+	index.wg.Add(1)
+	go func() {
+		defer index.wg.Done()
+
+		if err := index.reconcile(index.ctx, project, bucket); err != nil {
+			log.Infof("listing index: reconciling %q failed: %v", bucket.name, err)
+		}
+	}()
+}
+
+// reconcile lists bucket on the satellite into a new generation of its index
+// and makes it current. The caller must mark bucket as being reconciled.
+func (index *listingIndex) reconcile(ctx context.Context, project *uplink.Project, bucket projectBucket) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	defer func() {
+		index.mu.Lock()
+		delete(index.dirty, bucket)
+		index.mu.Unlock()
+	}()
+
+	next, err := index.nextGeneration(bucket)
+	if err != nil {
+		return err
+	}
+
+	list := project.ListObjects(ctx, bucket.name, &uplink.ListObjectsOptions{
+		Recursive: true,
+		System:    true,
+		Custom:    true,
+	})
+
+	batch := make([]*uplink.Object, 0, reconcileBatchSize)
+
+	flush := func() error {
+		err := index.db.Update(func(tx *bbolt.Tx) error {
+			b := tx.Bucket(indexRootBucket).Bucket(bucket.key())
+			if b == nil {
+				return nil // the bucket has been dropped in the meantime
+			}
+			staging := b.Bucket(next)
+			if staging == nil {
+				return nil
+			}
+			for _, object := range batch {
+				value, err := marshalIndexEntry(object)
+				if err != nil {
+					return err
+				}
+				if err = staging.Put([]byte(object.Key), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		batch = batch[:0]
+		return listingIndexError.Wrap(err)
+	}
+
+	for list.Next() {
+		batch = append(batch, list.Item())
+		if len(batch) == reconcileBatchSize {
+			if err = flush(); err != nil {
+				return errs.Combine(err, index.discardGeneration(bucket, next))
+			}
+		}
+	}
+	if err = list.Err(); err != nil {
+		if errors.Is(err, uplink.ErrBucketNotFound) {
+			return index.dropBucket(bucket)
+		}
+		return errs.Combine(err, index.discardGeneration(bucket, next))
+	}
+	if err = flush(); err != nil {
+		return errs.Combine(err, index.discardGeneration(bucket, next))
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(indexRootBucket).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+
+		staging := b.Bucket(next)
+		if staging == nil {
+			return nil
+		}
+
+		// Carry over keys that were modified while we were listing, as the
+		// listing might not reflect these modifications.
+		current := b.Get(indexCurrentKey)
+		var live *bbolt.Bucket
+		if current != nil {
+			live = b.Bucket(current)
+		}
+		for key := range index.dirty[bucket] {
+			var value []byte
+			if live != nil {
+				value = live.Get([]byte(key))
+			}
+			if value != nil {
+				if err := staging.Put([]byte(key), bytes.Clone(value)); err != nil {
+					return err
+				}
+			} else if err := staging.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+
+		if live != nil {
+			if err := b.DeleteBucket(current); err != nil {
+				return err
+			}
+		}
+
+		reconciled, err := time.Now().MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err = b.Put(indexCurrentKey, next); err != nil {
+			return err
+		}
+		return b.Put(indexReconciledKey, reconciled)
+	}))
+}
+
+// nextGeneration creates an empty generation of the index of bucket that
+// follows the current one and returns its name.
+func (index *listingIndex) nextGeneration(bucket projectBucket) (next []byte, err error) {
+	err = index.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.Bucket(indexRootBucket).CreateBucketIfNotExists(bucket.key())
+		if err != nil {
+			return err
+		}
+
+		var generation uint64
+		if current := b.Get(indexCurrentKey); current != nil {
+			generation = binary.BigEndian.Uint64(current) + 1
+		}
+		next = binary.BigEndian.AppendUint64(nil, generation)
+
+		// Remove leftovers from an interrupted reconciliation.
+		if b.Bucket(next) != nil {
+			if err = b.DeleteBucket(next); err != nil {
+				return err
+			}
+		}
+
+		_, err = b.CreateBucket(next)
+		return err
+	})
+	return next, listingIndexError.Wrap(err)
+}
+
+// discardGeneration removes an unfinished generation of the index of bucket.
+func (index *listingIndex) discardGeneration(bucket projectBucket, generation []byte) error {
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(indexRootBucket).Bucket(bucket.key())
+		if b == nil || b.Bucket(generation) == nil {
+			return nil
+		}
+		return b.DeleteBucket(generation)
+	}))
+}
+
+// liveGeneration returns the current generation of the index of bucket. If
+// create is true, it creates the index of bucket if necessary. Otherwise, it
+// might return nil.
+func liveGeneration(tx *bbolt.Tx, bucket projectBucket, create bool) (*bbolt.Bucket, error) {
+	root := tx.Bucket(indexRootBucket)
+
+	b := root.Bucket(bucket.key())
+	if b == nil {
+		if !create {
+			return nil, nil
+		}
+		var err error
+		if b, err = root.CreateBucket(bucket.key()); err != nil {
+			return nil, err
+		}
+	}
+
+	current := b.Get(indexCurrentKey)
+	if current == nil {
+		if !create {
+			return nil, nil
+		}
+		current = binary.BigEndian.AppendUint64(nil, 0)
+		if err := b.Put(indexCurrentKey, current); err != nil {
+			return nil, err
+		}
+	}
+
+	if !create {
+		return b.Bucket(current), nil
+	}
+	return b.CreateBucketIfNotExists(current)
+}
+
+// put records object in the index of bucket.
+func (index *listingIndex) put(bucket projectBucket, object *uplink.Object) error {
+	value, err := marshalIndexEntry(object)
+	if err != nil {
+		return listingIndexError.Wrap(err)
+	}
+
+	return index.update(bucket, object.Key, func(live *bbolt.Bucket) error {
+		return live.Put([]byte(object.Key), value)
+	})
+}
+
+// delete removes key from the index of bucket.
+func (index *listingIndex) delete(bucket projectBucket, key string) error {
+	return index.update(bucket, key, func(live *bbolt.Bucket) error {
+		return live.Delete([]byte(key))
+	})
+}
+
+func (index *listingIndex) update(bucket projectBucket, key string, fn func(live *bbolt.Bucket) error) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	if keys, ok := index.dirty[bucket]; ok {
+		keys[key] = struct{}{}
+	}
+
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		live, err := liveGeneration(tx, bucket, true)
+		if err != nil {
+			return err
+		}
+		return fn(live)
+	}))
+}
+
+// invalidate makes the index of bucket unusable until it's reconciled again.
+func (index *listingIndex) invalidate(bucket projectBucket) error {
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(indexRootBucket).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		return b.Delete(indexReconciledKey)
+	}))
+}
+
+// markEmpty makes bucket's index usable without reconciliation. It's meant to
+// be called for newly created buckets.
+func (index *listingIndex) markEmpty(bucket projectBucket) error {
+	if err := index.dropBucket(bucket); err != nil {
+		return err
+	}
+
+	reconciled, err := time.Now().MarshalBinary()
+	if err != nil {
+		return listingIndexError.Wrap(err)
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		if _, err := liveGeneration(tx, bucket, true); err != nil {
+			return err
+		}
+		return tx.Bucket(indexRootBucket).Bucket(bucket.key()).Put(indexReconciledKey, reconciled)
+	}))
+}
+
+// dropBucket removes the index of bucket.
+func (index *listingIndex) dropBucket(bucket projectBucket) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	return listingIndexError.Wrap(index.db.Update(func(tx *bbolt.Tx) error {
+		root := tx.Bucket(indexRootBucket)
+		if root.Bucket(bucket.key()) == nil {
+			return nil
+		}
+		return root.DeleteBucket(bucket.key())
+	}))
+}
+
+// list returns up to limit items from the index of bucket that begin with
+// prefix and come after after, in lexicographical order. It collapses keys
+// into common prefixes that share a path between prefix and delimiter. It also
+// reports whether there are more items to list.
+func (index *listingIndex) list(bucket projectBucket, prefix, after, delimiter string, limit int, now time.Time) (items []indexItem, more bool, err error) {
+	if limit <= 0 {
+		return nil, false, nil
+	}
+
+	err = index.db.View(func(tx *bbolt.Tx) error {
+		live, err := liveGeneration(tx, bucket, false)
+		if err != nil || live == nil {
+			return err
+		}
+
+		c := live.Cursor()
+
+		start := prefix
+		if after > start {
+			start = after
+		}
+
+		for k, v := c.Seek([]byte(start)); k != nil && bytes.HasPrefix(k, []byte(prefix)); {
+			key := string(k)
+
+			if commonPrefix, ok := collapseKey(prefix, delimiter, key); ok {
+				if commonPrefix > after {
+					if len(items) == limit {
+						more = true
+						return nil
+					}
+					items = append(items, indexItem{Key: commonPrefix, IsPrefix: true})
+				}
+				// Skip all remaining keys under this common prefix.
+				successor := prefixSuccessor(commonPrefix)
+				if successor == nil {
+					return nil
+				}
+				k, v = c.Seek(successor)
+				continue
+			}
+
+			if key > after {
+				var entry indexEntry
+				if err := json.Unmarshal(v, &entry); err != nil {
+					return err
+				}
+				if entry.Expires.IsZero() || entry.Expires.After(now) {
+					if len(items) == limit {
+						more = true
+						return nil
+					}
+					items = append(items, indexItem{Key: key, Entry: entry})
+				}
+			}
+
+			k, v = c.Next()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, listingIndexError.Wrap(err)
+	}
+
+	return items, more, nil
+}
+
+// prefixSuccessor returns the smallest key that is greater than all keys
+// beginning with prefix, or nil if there is no such key.
+func prefixSuccessor(prefix string) []byte {
+	successor := []byte(prefix)
+	for i := len(successor) - 1; i >= 0; i-- {
+		if successor[i] != 0xff {
+			successor[i]++
+			return successor[:i+1]
+		}
+	}
+	return nil
+}
+
+func marshalIndexEntry(object *uplink.Object) ([]byte, error) {
+	return json.Marshal(indexEntry{
+		Size:    objectSize(object),
+		Created: object.System.Created,
+		Expires: object.System.Expires,
+		Custom:  object.Custom,
+	})
+}
+
+// listObjectsIndexed lists bucket using the listing index. It supports any
+// prefix and delimiter, and its results are always lexicographically ordered.
+func (layer *gatewayLayer) listObjectsIndexed(
+	ctx context.Context,
+	bucket, prefix, after, delimiter string,
+	maxKeys int,
+) (
+	prefixes []string,
+	objects []minio.ObjectInfo,
+	nextContinuationToken string,
+	err error,
+) {
+	defer mon.Task()(&ctx)(&err)
+
+	limit := limitResults(maxKeys, layer.compatibilityConfig.MaxKeysLimit)
+
+	index, ok := layer.indexBucket(ctx, bucket)
+	if !ok {
+		return nil, nil, "", listingIndexError.New("no index of %q", bucket)
+	}
+
+	items, more, err := layer.listingIndex.list(index, prefix, after, delimiter, limit, time.Now())
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	for _, item := range items {
+		if item.IsPrefix {
+			prefixes = append(prefixes, item.Key)
+			continue
+		}
+
+		object := &uplink.Object{
+			Key: item.Key,
+			System: uplink.SystemMetadata{
+				Created:       item.Entry.Created,
+				Expires:       item.Entry.Expires,
+				ContentLength: item.Entry.Size,
+			},
+		}
+		if layer.compatibilityConfig.IncludeCustomMetadataListing {
+			object.Custom = item.Entry.Custom
+		}
+
+		objects = append(objects, minioObjectInfo(bucket, "", object))
+	}
+
+	if more {
+		nextContinuationToken = items[len(items)-1].Key
+	}
+
+	return prefixes, objects, nextContinuationToken, nil
+}
+
+// indexBucket returns bucket of the project in ctx for the listing index. It
+// reports false if the listing index is disabled or ctx doesn't identify the
+// project, in which case the index must not be used.
+func (layer *gatewayLayer) indexBucket(ctx context.Context, bucket string) (projectBucket, bool) {
+	if layer.listingIndex == nil {
+		return projectBucket{}, false
+	}
+	return projectBucketOf(ctx, bucket)
+}
+
+// indexObject records object in the listing index if it's enabled. If that
+// fails, the index of bucket is invalidated until the next reconciliation.
+func (layer *gatewayLayer) indexObject(ctx context.Context, bucket string, object *uplink.Object) {
+	index, ok := layer.indexBucket(ctx, bucket)
+	if !ok || object == nil {
+		return
+	}
+	if err := layer.listingIndex.put(index, object); err != nil {
+		layer.invalidateListingIndex(index, err)
+	}
+}
+
+// unindexObject removes key from the listing index if it's enabled. If that
+// fails, the index of bucket is invalidated until the next reconciliation.
+func (layer *gatewayLayer) unindexObject(ctx context.Context, bucket, key string) {
+	index, ok := layer.indexBucket(ctx, bucket)
+	if !ok {
+		return
+	}
+	if err := layer.listingIndex.delete(index, key); err != nil {
+		layer.invalidateListingIndex(index, err)
+	}
+}
+
+// reindexObject updates the listing index with the latest version of key,
+// e.g., after a specific version of it has been deleted.
+func (layer *gatewayLayer) reindexObject(ctx context.Context, project *uplink.Project, bucket, key string) {
+	index, ok := layer.indexBucket(ctx, bucket)
+	if !ok {
+		return
+	}
+
+	object, err := project.StatObject(ctx, bucket, key)
+	switch {
+	case errors.Is(err, uplink.ErrObjectNotFound):
+		layer.unindexObject(ctx, bucket, key)
+	case err != nil:
+		layer.invalidateListingIndex(index, err)
+	default:
+		layer.indexObject(ctx, bucket, object)
+	}
+}
+
+func (layer *gatewayLayer) invalidateListingIndex(bucket projectBucket, cause error) {
+	layer.logger.Infof("listing index: invalidating index of %q: %v", bucket.name, cause)
+	if err := layer.listingIndex.invalidate(bucket); err != nil {
+		layer.logger.Infof("listing index: invalidating index of %q failed: %v", bucket.name, err)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/uplink"
+)
+
+func TestListingIndexList(t *testing.T) {
+	index, err := openListingIndex(ListingIndexConfig{
+		Path:              filepath.Join(t.TempDir(), "index.db"),
+		ReconcileInterval: time.Hour,
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, index.Close()) }()
+
+	now := time.Now()
+
+	bucket := projectBucket{project: "project", name: "bucket"}
+	// Bucket names are only unique within a project.
+	otherProject := projectBucket{project: "other", name: "bucket"}
+
+	for _, key := range []string{"a", "a/b", "a/c/d", "a/c/e", "a+b", "b", "b-c-d", "b-c-e", "c"} {
+		require.NoError(t, index.put(bucket, &uplink.Object{Key: key, System: uplink.SystemMetadata{ContentLength: 1}}))
+	}
+	require.NoError(t, index.put(bucket, &uplink.Object{Key: "expired", System: uplink.SystemMetadata{Expires: now.Add(-time.Minute)}}))
+	require.NoError(t, index.put(projectBucket{project: "project", name: "other"}, &uplink.Object{Key: "x"}))
+	require.NoError(t, index.put(otherProject, &uplink.Object{Key: "y"}))
+	require.NoError(t, index.delete(bucket, "c"))
+
+	keys := func(items []indexItem) (keys []string) {
+		for _, item := range items {
+			keys = append(keys, item.Key)
+		}
+		return keys
+	}
+
+	for i, tt := range [...]struct {
+		prefix, after, delimiter string
+		limit                    int
+		expected                 []string
+		more                     bool
+	}{
+		{"", "", "", 100, []string{"a", "a+b", "a/b", "a/c/d", "a/c/e", "b", "b-c-d", "b-c-e"}, false},
+		{"", "", "/", 100, []string{"a", "a+b", "a/", "b", "b-c-d", "b-c-e"}, false},
+		{"", "", "-", 100, []string{"a", "a+b", "a/b", "a/c/d", "a/c/e", "b", "b-"}, false},
+		{"a", "", "/", 100, []string{"a", "a+b", "a/"}, false},
+		{"a/", "", "/", 100, []string{"a/b", "a/c/"}, false},
+		{"a/", "a/b", "/", 100, []string{"a/c/"}, false},
+		{"a/", "a/c/", "/", 100, nil, false},
+		{"b-", "", "-", 100, []string{"b-c-"}, false},
+		{"", "", "/", 2, []string{"a", "a+b"}, true},
+		{"", "a+b", "/", 2, []string{"a/", "b"}, true},
+		{"", "b", "/", 2, []string{"b-c-d", "b-c-e"}, false},
+		{"", "", "", 0, nil, false},
+		{"nothing", "", "", 100, nil, false},
+	} {
+		items, more, err := index.list(bucket, tt.prefix, tt.after, tt.delimiter, tt.limit, now)
+		require.NoError(t, err, i)
+		assert.Equal(t, tt.expected, keys(items), i)
+		assert.Equal(t, tt.more, more, i)
+	}
+
+	require.NoError(t, index.dropBucket(bucket))
+
+	items, more, err := index.list(bucket, "", "", "", 100, now)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.False(t, more)
+
+	items, _, err = index.list(otherProject, "", "", "", 100, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"y"}, keys(items))
+}
+
+func TestListingIndexBucket(t *testing.T) {
+	layer := &gatewayLayer{listingIndex: &listingIndex{}}
+
+	// Without knowing the project, the index can't be used.
+	_, ok := layer.indexBucket(context.Background(), "bucket")
+	assert.False(t, ok)
+
+	index, ok := layer.indexBucket(WithProjectID(context.Background(), "project"), "bucket")
+	require.True(t, ok)
+	assert.Equal(t, projectBucket{project: "project", name: "bucket"}, index)
+
+	_, ok = (&gatewayLayer{}).indexBucket(WithProjectID(context.Background(), "project"), "bucket")
+	assert.False(t, ok)
+}
+
+func TestPrefixSuccessor(t *testing.T) {
+	assert.Equal(t, []byte("a0"), prefixSuccessor("a/"))
+	assert.Equal(t, []byte("b"), prefixSuccessor("a\xff"))
+	assert.Nil(t, prefixSuccessor("\xff\xff"))
+	assert.Nil(t, prefixSuccessor(""))
+}
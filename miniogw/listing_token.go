@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/zeebo/errs"
+
+	minio "storj.io/minio/cmd"
+)
+
+// listingStrategy is the way listObjectsGeneral lists a bucket.
+type listingStrategy byte
+
+const (
+	listingStrategyFast listingStrategy = iota + 1
+	listingStrategySingle
+	listingStrategyExhaustive
+	listingStrategyIndexed
+)
+
+const (
+	// listingTokenVersion is the version of the encoded listingToken format.
+	listingTokenVersion = 1
+	// listingTokenMACSize is the number of bytes of HMAC-SHA256 kept in
+	// encoded listingTokens.
+	listingTokenMACSize = 16
+)
+
+// listingToken is the state of a paginated listing that ListObjectsV2 hands
+// out as an opaque continuation token.
+type listingToken struct {
+	Strategy  listingStrategy
+	Prefix    string
+	Delimiter string
+	// Cursor is the key (or common prefix) to continue listing after.
+	Cursor string
+	// Skip contains keys (or common prefixes) that have already been listed
+	// out of order and must not be listed again.
+	Skip []string
+}
+
+// encodeListingToken encodes token for listing bucket. The encoded token is
+// protected with an HMAC over its contents and bucket.
+//
+// The format is:
+//
+//	version | strategy | prefix | delimiter | cursor | len(skip) | skip... | mac
+//
+// where strings are prefixed with their uvarint-encoded length.
+func (layer *gatewayLayer) encodeListingToken(bucket string, token *listingToken) string {
+	data := []byte{listingTokenVersion, byte(token.Strategy)}
+
+	data = appendTokenString(data, token.Prefix)
+	data = appendTokenString(data, token.Delimiter)
+	data = appendTokenString(data, token.Cursor)
+	data = binary.AppendUvarint(data, uint64(len(token.Skip)))
+	for _, key := range token.Skip {
+		data = appendTokenString(data, key)
+	}
+
+	data = append(data, layer.listingTokenMAC(bucket, data)...)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListingToken decodes an encoded token for listing bucket with prefix
+// and delimiter. It returns minio.InvalidArgument if the token is malformed,
+// has been tampered with or doesn't match the listing.
+func (layer *gatewayLayer) decodeListingToken(bucket, prefix, delimiter, encoded string) (token listingToken, err error) {
+	invalid := func(msg string) error {
+		return minio.InvalidArgument{
+			Bucket: bucket,
+			Err:    errs.New("invalid continuation token: %s", msg),
+		}
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < 2+listingTokenMACSize {
+		return listingToken{}, invalid("malformed")
+	}
+
+	data, mac := data[:len(data)-listingTokenMACSize], data[len(data)-listingTokenMACSize:]
+	if !hmac.Equal(mac, layer.listingTokenMAC(bucket, data)) {
+		return listingToken{}, invalid("integrity check failed")
+	}
+
+	if data[0] != listingTokenVersion {
+		return listingToken{}, invalid("unsupported version")
+	}
+
+	token.Strategy = listingStrategy(data[1])
+	if token.Strategy < listingStrategyFast || token.Strategy > listingStrategyIndexed {
+		return listingToken{}, invalid("unknown strategy")
+	}
+
+	r := tokenReader{data: data[2:]}
+
+	token.Prefix = r.string()
+	token.Delimiter = r.string()
+	token.Cursor = r.string()
+	for n := r.uvarint(); n > 0 && r.ok(); n-- {
+		token.Skip = append(token.Skip, r.string())
+	}
+
+	if !r.ok() || len(r.data) > 0 {
+		return listingToken{}, invalid("malformed")
+	}
+
+	if token.Prefix != prefix || token.Delimiter != delimiter {
+		return listingToken{}, invalid("prefix or delimiter mismatch")
+	}
+
+	return token, nil
+}
+
+func (layer *gatewayLayer) listingTokenMAC(bucket string, data []byte) []byte {
+	mac := hmac.New(sha256.New, layer.listingTokenKey)
+	_, _ = mac.Write(appendTokenString(nil, bucket))
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)[:listingTokenMACSize]
+}
+
+func appendTokenString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+// tokenReader reads values appended by appendTokenString and
+// binary.AppendUvarint. Once reading fails, ok returns false and all
+// subsequent reads return zero values.
+type tokenReader struct {
+	data   []byte
+	failed bool
+}
+
+func (r *tokenReader) ok() bool { return !r.failed }
+
+func (r *tokenReader) uvarint() uint64 {
+	if r.failed {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.failed = true
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *tokenReader) string() string {
+	n := r.uvarint()
+	if r.failed || n > uint64(len(r.data)) {
+		r.failed = true
+		return ""
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/auth"
+)
+
+func TestListingToken(t *testing.T) {
+	layer := &gatewayLayer{listingTokenKey: []byte("key")}
+
+	for i, token := range []listingToken{
+		{Strategy: listingStrategyFast},
+		{Strategy: listingStrategyFast, Prefix: "a/", Delimiter: "/", Cursor: "a/b"},
+		{Strategy: listingStrategyExhaustive, Prefix: "a", Delimiter: "/", Skip: []string{"a/", "a"}},
+		{Strategy: listingStrategyIndexed, Prefix: "ą/ę", Delimiter: "-", Cursor: "ą/ę-\xff"},
+	} {
+		encoded := layer.encodeListingToken("bucket", &token)
+
+		decoded, err := layer.decodeListingToken("bucket", token.Prefix, token.Delimiter, encoded)
+		require.NoError(t, err, i)
+		assert.Equal(t, token, decoded, i)
+
+		isInvalidArgument := func(err error) bool {
+			return errors.As(err, &minio.InvalidArgument{})
+		}
+
+		_, err = layer.decodeListingToken("bucket", token.Prefix+"x", token.Delimiter, encoded)
+		assert.True(t, isInvalidArgument(err), i)
+
+		_, err = layer.decodeListingToken("bucket", token.Prefix, token.Delimiter+"x", encoded)
+		assert.True(t, isInvalidArgument(err), i)
+
+		_, err = layer.decodeListingToken("other", token.Prefix, token.Delimiter, encoded)
+		assert.True(t, isInvalidArgument(err), i)
+
+		other := &gatewayLayer{listingTokenKey: []byte("other key")}
+		_, err = other.decodeListingToken("bucket", token.Prefix, token.Delimiter, encoded)
+		assert.True(t, isInvalidArgument(err), i)
+
+		data, err := base64.RawURLEncoding.DecodeString(encoded)
+		require.NoError(t, err, i)
+		data[len(data)/2] ^= 1
+		_, err = layer.decodeListingToken("bucket", token.Prefix, token.Delimiter, base64.RawURLEncoding.EncodeToString(data))
+		assert.True(t, isInvalidArgument(err), i)
+	}
+
+	for _, encoded := range []string{"", "a", "!!!", "raw/key", base64.RawURLEncoding.EncodeToString(make([]byte, 64))} {
+		_, err := layer.decodeListingToken("bucket", "", "", encoded)
+		assert.True(t, errors.As(err, &minio.InvalidArgument{}), encoded)
+	}
+}
+
+func TestListingTokenKey(t *testing.T) {
+	creds := auth.Credentials{AccessKey: "access", SecretKey: "secret"}
+
+	key := func(config S3CompatibilityConfig, creds auth.Credentials) []byte {
+		key, err := NewStorjGateway(config).getListingTokenKey(creds)
+		require.NoError(t, err)
+		return key
+	}
+
+	// Instances sharing credentials accept each other's tokens.
+	derived := key(S3CompatibilityConfig{}, creds)
+	assert.Equal(t, derived, key(S3CompatibilityConfig{}, creds))
+	assert.NotEqual(t, derived, key(S3CompatibilityConfig{}, auth.Credentials{AccessKey: "access", SecretKey: "other"}))
+	assert.NotContains(t, string(derived), "secret")
+
+	// The configured secret takes precedence.
+	configured := key(S3CompatibilityConfig{ListingTokenSecret: "token secret"}, creds)
+	assert.NotEqual(t, derived, configured)
+	assert.Equal(t, configured, key(S3CompatibilityConfig{ListingTokenSecret: "token secret"}, auth.Credentials{}))
+
+	// The key is kept once chosen.
+	gateway := NewStorjGateway(S3CompatibilityConfig{})
+	first, err := gateway.getListingTokenKey(creds)
+	require.NoError(t, err)
+	second, err := gateway.getListingTokenKey(auth.Credentials{SecretKey: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
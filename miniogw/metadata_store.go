@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.etcd.io/bbolt"
+)
+
+// metadataStoreError is the error class for the local metadata store.
+var metadataStoreError = errs.Class("metadata store")
+
+// Kinds of metadata kept in the metadata store.
+var (
+	storeObjectTags      = []byte("object-tags")
+	storeObjectLock      = []byte("object-lock")
+	storePartChecksums   = []byte("part-checksums")
+	storeObjectParts     = []byte("object-parts")
+	storeCompressedParts = []byte("compressed-parts")
+	storeBucketConfig    = []byte("bucket-config")
+
+	// storeNotificationQueue holds bucket event notifications that haven't
+	// been delivered yet.
+	storeNotificationQueue = []byte("notification-queue")
+)
+
+// metadataStore is a persistent, local store of metadata that the satellite
+// can't keep for the gateway, e.g., tags of noncurrent object versions or
+// bucket configurations.
+//
+// The database holds a top-level bucket for each kind of metadata, and each of
+// them holds a nested bucket for each S3 bucket with any metadata of this
+// kind. Bucket names are only unique within a project, so nested buckets are
+// keyed by the project ID and the bucket name, like the listing index.
+type metadataStore struct {
+	db *bbolt.DB
+}
+
+func openMetadataStore(path string) (*metadataStore, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, metadataStoreError.Wrap(err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, kind := range [][]byte{storeObjectTags, storeObjectLock, storePartChecksums, storeObjectParts, storeCompressedParts, storeBucketConfig, storeNotificationQueue} {
+			if _, err := tx.CreateBucketIfNotExists(kind); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, metadataStoreError.Wrap(errs.Combine(err, db.Close()))
+	}
+
+	return &metadataStore{db: db}, nil
+}
+
+// Close closes the store database.
+func (store *metadataStore) Close() error {
+	return metadataStoreError.Wrap(store.db.Close())
+}
+
+// get returns the value of key in bucket for kind of metadata and whether
+// it's been found. The returned value is a copy.
+func (store *metadataStore) get(kind []byte, bucket projectBucket, key []byte) (value []byte, found bool, err error) {
+	err = store.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(kind).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		// Empty values can't be told apart from missing ones using Get.
+		k, v := b.Cursor().Seek(key)
+		if bytes.Equal(k, key) {
+			value, found = bytes.Clone(v), true
+		}
+		return nil
+	})
+	return value, found, metadataStoreError.Wrap(err)
+}
+
+// put sets the value of key in bucket for kind of metadata.
+func (store *metadataStore) put(kind []byte, bucket projectBucket, key, value []byte) error {
+	return metadataStoreError.Wrap(store.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.Bucket(kind).CreateBucketIfNotExists(bucket.key())
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			value = []byte{}
+		}
+		return b.Put(key, value)
+	}))
+}
+
+// delete removes key in bucket for kind of metadata.
+func (store *metadataStore) delete(kind []byte, bucket projectBucket, key []byte) error {
+	return metadataStoreError.Wrap(store.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(kind).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		return b.Delete(key)
+	}))
+}
+
+// deletePrefix removes all keys starting with prefix in bucket for kind of
+// metadata.
+func (store *metadataStore) deletePrefix(kind []byte, bucket projectBucket, prefix []byte) error {
+	return metadataStoreError.Wrap(store.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(kind).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// forEachPrefix calls fn with each key of kind in bucket starting with prefix
+// and its value, which are only valid until fn returns.
+func (store *metadataStore) forEachPrefix(kind []byte, bucket projectBucket, prefix []byte, fn func(key, value []byte) error) error {
+	return metadataStoreError.Wrap(store.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(kind).Bucket(bucket.key())
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// forEach calls fn with each key of kind in any bucket and its value, which
+// are only valid until fn returns.
+func (store *metadataStore) forEach(kind []byte, fn func(bucket projectBucket, key, value []byte) error) error {
+	return metadataStoreError.Wrap(store.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(kind).ForEachBucket(func(bucket []byte) error {
+			return tx.Bucket(kind).Bucket(bucket).ForEach(func(k, v []byte) error {
+				return fn(parseProjectBucket(bucket), k, v)
+			})
+		})
+	}))
+}
+
+// dropBucket removes all metadata of bucket, e.g., after it's been deleted.
+// Notifications queued for the bucket are still delivered.
+func (store *metadataStore) dropBucket(bucket projectBucket) error {
+	return metadataStoreError.Wrap(store.db.Update(func(tx *bbolt.Tx) error {
+		return tx.ForEach(func(kind []byte, b *bbolt.Bucket) error {
+			if bytes.Equal(kind, storeNotificationQueue) {
+				return nil
+			}
+			err := b.DeleteBucket(bucket.key())
+			if errors.Is(err, bbolt.ErrBucketNotFound) {
+				return nil
+			}
+			return err
+		})
+	}))
+}
+
+// objectVersionKey returns the store key of version of the object at key.
+func objectVersionKey(key string, version []byte) []byte {
+	return append(appendTokenString(nil, key), version...)
+}
+
+// uploadPartKey returns the store key of part partNumber of the multipart
+// upload uploadID. Keys of all parts of the upload start with
+// uploadKey(uploadID).
+func uploadPartKey(uploadID string, partNumber int) []byte {
+	return binary.BigEndian.AppendUint32(uploadKey(uploadID), uint32(partNumber))
+}
+
+// uploadKey returns the store key of the multipart upload uploadID.
+func uploadKey(uploadID string) []byte {
+	return appendTokenString(nil, uploadID)
+}
+
+// storeBucket returns bucket of the project in ctx as keyed in the metadata
+// store. Metadata of buckets accessed without a project ID is kept
+// separately from that of any project.
+func storeBucket(ctx context.Context, bucket string) projectBucket {
+	if storeBucket, ok := projectBucketOf(ctx, bucket); ok {
+		return storeBucket
+	}
+	return projectBucket{name: bucket}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.db")
+
+	store, err := openMetadataStore(path)
+	require.NoError(t, err)
+
+	keyA := objectVersionKey("a", []byte{1})
+	keyB := objectVersionKey("a", []byte{2})
+	assert.NotEqual(t, keyA, objectVersionKey("a\x01", nil))
+
+	_, found, err := store.get(storeObjectTags, projectBucket{name: "bucket"}, keyA)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.put(storeObjectTags, projectBucket{name: "bucket"}, keyA, []byte("k=v")))
+	require.NoError(t, store.put(storeObjectTags, projectBucket{name: "bucket"}, keyB, nil))
+	require.NoError(t, store.put(storeObjectTags, projectBucket{name: "other"}, keyA, []byte("x=y")))
+
+	value, found, err := store.get(storeObjectTags, projectBucket{name: "bucket"}, keyA)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "k=v", string(value))
+
+	// Empty values are found.
+	value, found, err = store.get(storeObjectTags, projectBucket{name: "bucket"}, keyB)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Empty(t, value)
+
+	require.NoError(t, store.delete(storeObjectTags, projectBucket{name: "bucket"}, keyA))
+	_, found, err = store.get(storeObjectTags, projectBucket{name: "bucket"}, keyA)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.dropBucket(projectBucket{name: "bucket"}))
+	require.NoError(t, store.dropBucket(projectBucket{name: "missing"}))
+	_, found, err = store.get(storeObjectTags, projectBucket{name: "bucket"}, keyB)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Buckets of different projects with the same name are kept apart.
+	projectA := projectBucket{project: "a", name: "bucket"}
+	projectB := projectBucket{project: "b", name: "bucket"}
+	require.NoError(t, store.put(storeObjectTags, projectA, keyA, []byte("a")))
+	_, found, err = store.get(storeObjectTags, projectB, keyA)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.put(storeObjectTags, projectB, keyA, []byte("b")))
+	require.NoError(t, store.dropBucket(projectB))
+	value, found, err = store.get(storeObjectTags, projectA, keyA)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a", string(value))
+
+	require.NoError(t, store.Close())
+
+	// Metadata survives reopening.
+	store, err = openMetadataStore(path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	value, found, err = store.get(storeObjectTags, projectBucket{name: "other"}, keyA)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "x=y", string(value))
+}
@@ -6,8 +6,10 @@ package miniogw
 import (
 	"context"
 	"errors"
-	"fmt"
+	"io"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,12 +20,18 @@ import (
 	minio "storj.io/minio/cmd"
 	"storj.io/minio/cmd/config/storageclass"
 	xhttp "storj.io/minio/cmd/http"
+	"storj.io/minio/pkg/hash"
 	"storj.io/uplink"
 	"storj.io/uplink/private/multipart"
 	versioned "storj.io/uplink/private/object"
 )
 
 // ListMultipartUploads lists all multipart uploads.
+//
+// Uploads are sorted by key and uploads of the same key by upload ID. As in
+// AWS S3, uploadIDMarker is only taken into account together with keyMarker:
+// uploads of keyMarker are listed if their upload ID is greater than
+// uploadIDMarker.
 func (layer *gatewayLayer) ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result minio.ListMultipartsInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
@@ -36,70 +44,416 @@ func (layer *gatewayLayer) ListMultipartUploads(ctx context.Context, bucket, pre
 		return minio.ListMultipartsInfo{}, err
 	}
 
-	if delimiter != "" && delimiter != "/" {
-		return minio.ListMultipartsInfo{}, minio.NotImplemented{Message: fmt.Sprintf("Unsupported delimiter: %q", delimiter)}
-	}
-
 	// TODO this should be removed and implemented on satellite side
 	defer func() {
 		err = checkBucketError(ctx, project, bucket, "", err)
 	}()
-	recursive := delimiter == ""
+
+	var (
+		nextKeyMarker      string
+		nextUploadIDMarker string
+		uploads            []minio.MultipartInfo
+		prefixes           []string
+	)
+
+	after := uploadCursor{Key: keyMarker}
+	if keyMarker != "" {
+		after.UploadID = uploadIDMarker
+	}
+
+	supportedPrefix := prefix == "" || strings.HasSuffix(prefix, "/")
+	// Uploads of a key ending with a forward slash can't be listed alone.
+	supportedCursor := after.UploadID == "" || !strings.HasSuffix(after.Key, "/")
+
+	if supportedPrefix && supportedCursor && (delimiter == "" || delimiter == "/") {
+		uploads, prefixes, nextKeyMarker, nextUploadIDMarker, err = layer.listUploadsFast(ctx, project, bucket, prefix, after, delimiter, maxUploads)
+	} else {
+		uploads, prefixes, nextKeyMarker, nextUploadIDMarker, err = layer.listUploadsExhaustive(ctx, project, bucket, prefix, after, delimiter, maxUploads)
+	}
+	if err != nil {
+		return result, convertMultipartError(err, bucket, "", "")
+	}
+
+	return minio.ListMultipartsInfo{
+		KeyMarker:          keyMarker,
+		UploadIDMarker:     uploadIDMarker,
+		NextKeyMarker:      nextKeyMarker,
+		NextUploadIDMarker: nextUploadIDMarker,
+		MaxUploads:         maxUploads,
+		IsTruncated:        nextKeyMarker != "",
+		Uploads:            uploads,
+		Prefix:             prefix,
+		Delimiter:          delimiter,
+		CommonPrefixes:     prefixes,
+	}, nil
+}
+
+// uploadCursor is the position in a listing of uploads to continue after. If
+// UploadID is empty, all uploads of Key are skipped.
+type uploadCursor struct {
+	Key      string
+	UploadID string
+}
+
+// skips returns whether an upload of key (which might be a collapsed key)
+// with uploadID comes before or at cursor.
+func (cursor uploadCursor) skips(key, uploadID string, collapsed bool) bool {
+	if key != cursor.Key {
+		return key < cursor.Key
+	}
+	return collapsed || cursor.UploadID == "" || uploadID <= cursor.UploadID
+}
+
+// lessUpload orders uploads by key and uploads of the same key by upload ID.
+func lessUpload(a, b *uplink.UploadInfo) bool {
+	if a.Key != b.Key {
+		return a.Key < b.Key
+	}
+	return a.UploadID < b.UploadID
+}
+
+// listUploadsFast lists uploads using libuplink's own prefix collapsing. It
+// only supports prefixes that are empty or terminated with a forward slash
+// and delimiters that are empty or a forward slash.
+//
+// libuplink lists uploads after a key, so if the listing continues in the
+// middle of the uploads of after.Key, it lists the remaining ones separately.
+func (layer *gatewayLayer) listUploadsFast(
+	ctx context.Context,
+	project *uplink.Project,
+	bucket, prefix string,
+	after uploadCursor,
+	delimiter string,
+	maxUploads int,
+) (
+	uploads []minio.MultipartInfo,
+	prefixes []string,
+	nextKeyMarker string,
+	nextUploadIDMarker string,
+	err error,
+) {
+	defer mon.Task()(&ctx)(&err)
+
+	var remaining []*uplink.UploadInfo
+
+	if after.UploadID != "" && strings.HasPrefix(after.Key, prefix) {
+		if _, collapsed := collapseKey(prefix, delimiter, after.Key); !collapsed {
+			remaining, err = layer.listKeyUploads(ctx, project, bucket, after)
+			if err != nil {
+				return nil, nil, "", "", err
+			}
+		}
+	}
 
 	list := project.ListUploads(ctx, bucket, &uplink.ListUploadsOptions{
 		Prefix:    prefix,
-		Cursor:    strings.TrimPrefix(keyMarker, prefix),
-		Recursive: recursive,
+		Cursor:    strings.TrimPrefix(after.Key, prefix),
+		Recursive: delimiter == "",
 		System:    true,
 		Custom:    layer.compatibilityConfig.IncludeCustomMetadataListing,
 	})
 
-	var (
-		nextKeyMarker string
-		uploads       []minio.MultipartInfo
-		prefixes      []string
-	)
+	return layer.uploadsToPrefixesAndUploads(&keySortedUploads{list: list, group: remaining}, bucket, prefix, delimiter, maxUploads)
+}
 
-	limit := limitResults(maxUploads, layer.compatibilityConfig.MaxUploadsLimit)
+// listKeyUploads returns uploads of after.Key that come after after.UploadID,
+// sorted by upload ID. after.Key must not end with a forward slash, as
+// libuplink treats such keys as prefixes.
+func (layer *gatewayLayer) listKeyUploads(ctx context.Context, project *uplink.Project, bucket string, after uploadCursor) (uploads []*uplink.UploadInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
 
-	for limit > 0 && list.Next() {
-		limit--
-		object := list.Item()
+	// Listing with a prefix that doesn't end with a forward slash lists
+	// uploads of exactly this key.
+	list := project.ListUploads(ctx, bucket, &uplink.ListUploadsOptions{
+		Prefix: after.Key,
+		System: true,
+		Custom: layer.compatibilityConfig.IncludeCustomMetadataListing,
+	})
+
+	for list.Next() {
+		item := list.Item()
+		if item.Key == after.Key && !after.skips(item.Key, item.UploadID, false) {
+			uploads = append(uploads, item)
+		}
+	}
+	if list.Err() != nil {
+		return nil, list.Err()
+	}
+
+	sort.Slice(uploads, func(i, j int) bool {
+		return lessUpload(uploads[i], uploads[j])
+	})
+
+	return uploads, nil
+}
+
+// keySortedUploads wraps an iterator over uploads sorted by key and sorts
+// uploads of the same key by upload ID. Uploads in group are returned first.
+type keySortedUploads struct {
+	list  uploadIterator
+	group []*uplink.UploadInfo
+	next  *uplink.UploadInfo
+	item  *uplink.UploadInfo
+}
+
+// Next prepares the next upload for reading with the Item method.
+func (it *keySortedUploads) Next() bool {
+	if len(it.group) == 0 {
+		it.readGroup()
+	}
+
+	if len(it.group) == 0 {
+		it.item = nil
+		return false
+	}
+
+	it.item, it.group = it.group[0], it.group[1:]
+
+	return true
+}
+
+// readGroup reads the next prefix or all consecutive uploads of the next key.
+func (it *keySortedUploads) readGroup() {
+	first := it.next
+	it.next = nil
+
+	if first == nil {
+		if !it.list.Next() {
+			return
+		}
+		first = it.list.Item()
+	}
 
-		if object.IsPrefix {
-			prefixes = append(prefixes, object.Key)
+	it.group = append(it.group, first)
+	if first.IsPrefix {
+		return
+	}
+
+	for it.list.Next() {
+		item := it.list.Item()
+		if item.IsPrefix || item.Key != first.Key {
+			it.next = item
+			break
+		}
+		it.group = append(it.group, item)
+	}
+
+	sort.Slice(it.group, func(i, j int) bool {
+		return lessUpload(it.group[i], it.group[j])
+	})
+}
+
+// Item returns the current upload.
+func (it *keySortedUploads) Item() *uplink.UploadInfo { return it.item }
+
+// Err returns the error, if any, that occurred during iteration.
+func (it *keySortedUploads) Err() error { return it.list.Err() }
+
+// listUploadsExhaustive lists all uploads in the bucket discarding keys that
+// do not begin with the necessary prefix or come before after, just like
+// listObjectsExhaustive does for objects. It sorts the remaining uploads with
+// bounded memory and collapses their keys into common prefixes that share a
+// path between prefix and delimiter. If the listing is truncated, the sorted
+// uploads are kept for the next page.
+func (layer *gatewayLayer) listUploadsExhaustive(
+	ctx context.Context,
+	project *uplink.Project,
+	bucket, prefix string,
+	after uploadCursor,
+	delimiter string,
+	maxUploads int,
+) (
+	uploads []minio.MultipartInfo,
+	prefixes []string,
+	nextKeyMarker string,
+	nextUploadIDMarker string,
+	err error,
+) {
+	defer mon.Task()(&ctx)(&err)
+
+	// Listings are only kept if they can't be taken by other projects.
+	listings := layer.uploadListings
+	listingBucket, ok := projectBucketOf(ctx, bucket)
+	if !ok {
+		listings = nil
+	}
+	listingKey := func(cursor uploadCursor) string {
+		data := appendTokenString(nil, listingBucket.project)
+		data = appendTokenString(data, listingBucket.name)
+		data = appendTokenString(data, prefix)
+		data = appendTokenString(data, delimiter)
+		data = appendTokenString(data, cursor.Key)
+		data = appendTokenString(data, cursor.UploadID)
+		return string(data)
+	}
+
+	listing := listings.take(listingKey(after))
+	if listing == nil {
+		listing, err = layer.sortUploads(ctx, project, bucket, prefix, after, delimiter, maxUploads, listings != nil)
+		if err != nil {
+			return nil, nil, "", "", err
+		}
+	}
+
+	uploads, prefixes, nextKeyMarker, nextUploadIDMarker, err = layer.uploadsToPrefixesAndUploads(listing.items, bucket, prefix, delimiter, maxUploads)
+	if err != nil {
+		return nil, nil, "", "", errs.Combine(err, listing.Close())
+	}
+	if nextKeyMarker == "" {
+		return uploads, prefixes, "", "", listing.Close()
+	}
+
+	// The upload that didn't fit into this page starts the next one.
+	listing.items.Unread()
+	if err = listings.keep(listingKey(uploadCursor{Key: nextKeyMarker, UploadID: nextUploadIDMarker}), listing); err != nil {
+		return nil, nil, "", "", err
+	}
+
+	return uploads, prefixes, nextKeyMarker, nextUploadIDMarker, nil
+}
+
+// sortUploads lists and sorts uploads for listUploadsExhaustive the way
+// sortObjects does for objects.
+func (layer *gatewayLayer) sortUploads(
+	ctx context.Context,
+	project *uplink.Project,
+	bucket, prefix string,
+	after uploadCursor,
+	delimiter string,
+	maxUploads int,
+	kept bool,
+) (_ *sortedListing[uplink.UploadInfo], err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	// See listObjectsExhaustive for why listing from the last forward slash
+	// in prefix is enough.
+	var listPrefix string
+	if i := strings.LastIndex(prefix, "/"); i != -1 {
+		listPrefix = prefix[:i] + "/"
+	}
+
+	list := project.ListUploads(ctx, bucket, &uplink.ListUploadsOptions{
+		Prefix:    listPrefix,
+		Recursive: delimiter != "/",
+		System:    true,
+		Custom:    layer.compatibilityConfig.IncludeCustomMetadataListing,
+	})
+
+	sorter := newSorter(layer.compatibilityConfig.ListingTempDir, layer.compatibilityConfig.MaxKeysExhaustiveLimit, lessUpload)
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, sorter.Close())
+		}
+	}()
+
+	for list.Next() {
+		item := list.Item()
+
+		if !strings.HasPrefix(item.Key, prefix) {
 			continue
 		}
 
-		uploads = append(uploads, minioMultipartInfo(bucket, object))
+		// The key marker could mean the collapsed key, so we compare it with
+		// the collapsed key (see listObjectsExhaustive).
+		key := item.Key
+		commonPrefix, collapsed := collapseKey(prefix, delimiter, item.Key)
+		if collapsed {
+			key = commonPrefix
+		}
+
+		if after.skips(key, item.UploadID, collapsed || item.IsPrefix) {
+			continue
+		}
 
-		nextKeyMarker = object.Key
+		if err = sorter.Add(item); err != nil {
+			return nil, err
+		}
 	}
 	if list.Err() != nil {
-		return result, convertMultipartError(list.Err(), bucket, "", "")
+		return nil, list.Err()
 	}
 
-	more := list.Next()
-	if list.Err() != nil {
-		return result, convertMultipartError(list.Err(), bucket, "", "")
+	if kept && sorter.Len() > limitResults(maxUploads, layer.compatibilityConfig.MaxUploadsLimit) {
+		if err = sorter.Flush(); err != nil {
+			return nil, err
+		}
 	}
 
-	if !more {
-		nextKeyMarker = ""
+	items, err := sorter.Iterate()
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: support NextUploadID (https://github.com/storj/gateway-mt/issues/213)
-	return minio.ListMultipartsInfo{
-		KeyMarker:      keyMarker,
-		NextKeyMarker:  nextKeyMarker,
-		UploadIDMarker: uploadIDMarker,
-		MaxUploads:     maxUploads,
-		IsTruncated:    nextKeyMarker != "",
-		Uploads:        uploads,
-		Prefix:         prefix,
-		Delimiter:      delimiter,
-		CommonPrefixes: prefixes,
-	}, nil
+	return &sortedListing[uplink.UploadInfo]{sorter: sorter, items: items}, nil
+}
+
+// uploadIterator iterates over uploads. It's satisfied by
+// *uplink.UploadIterator, *keySortedUploads and
+// *sortedItems[uplink.UploadInfo].
+type uploadIterator interface {
+	Next() bool
+	Item() *uplink.UploadInfo
+	Err() error
+}
+
+// uploadsToPrefixesAndUploads dispatches items into uploads and prefixes the
+// same way itemsToPrefixesAndObjects does for objects. Items must be sorted by
+// key and upload ID. If there are more items than the limit, nextKeyMarker
+// and nextUploadIDMarker will point at the last non-truncated item
+// (nextUploadIDMarker is empty if it's a prefix).
+func (layer *gatewayLayer) uploadsToPrefixesAndUploads(
+	items uploadIterator,
+	bucket, prefix, delimiter string,
+	maxUploads int,
+) (
+	uploads []minio.MultipartInfo,
+	prefixes []string,
+	nextKeyMarker string,
+	nextUploadIDMarker string,
+	err error,
+) {
+	limit := limitResults(maxUploads, layer.compatibilityConfig.MaxUploadsLimit)
+	prefixesLookup := make(map[string]struct{})
+
+	for items.Next() {
+		item := items.Item()
+
+		if item.IsPrefix {
+			if limit == 0 {
+				return uploads, prefixes, nextKeyMarker, nextUploadIDMarker, nil
+			}
+			prefixes = append(prefixes, item.Key)
+			nextKeyMarker, nextUploadIDMarker = item.Key, ""
+			limit--
+			continue
+		}
+
+		commonPrefix, ok := collapseKey(prefix, delimiter, item.Key)
+		if !ok {
+			if limit == 0 {
+				return uploads, prefixes, nextKeyMarker, nextUploadIDMarker, nil
+			}
+			uploads = append(uploads, minioMultipartInfo(bucket, item))
+			nextKeyMarker, nextUploadIDMarker = item.Key, item.UploadID
+			limit--
+			continue
+		}
+
+		if _, ok := prefixesLookup[commonPrefix]; !ok {
+			if limit == 0 {
+				return uploads, prefixes, nextKeyMarker, nextUploadIDMarker, nil
+			}
+			prefixesLookup[commonPrefix] = struct{}{}
+			prefixes = append(prefixes, commonPrefix)
+			nextKeyMarker, nextUploadIDMarker = commonPrefix, ""
+			limit--
+		}
+	}
+	if items.Err() != nil {
+		return nil, nil, "", "", items.Err()
+	}
+
+	return uploads, prefixes, "", "", nil
 }
 
 func (layer *gatewayLayer) NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (uploadID string, err error) {
@@ -131,14 +485,70 @@ func (layer *gatewayLayer) NewMultipartUpload(ctx context.Context, bucket, objec
 	if err != nil {
 		return "", ErrInvalidTTL
 	}
+
+	if err := setWebsiteRedirectLocation(ctx, opts.UserDefined); err != nil {
+		return "", err
+	}
+
+	// Checksums of parts are kept by the gateway until the upload completes.
+	var checksumAlgorithm string
+	if request, ok := getChecksumRequest(ctx); ok && request.algorithm != "" {
+		if newChecksumHash(request.algorithm) == nil {
+			return "", ErrInvalidChecksum
+		}
+		if layer.metadataStore == nil {
+			return "", minio.NotImplemented{Message: "NewMultipartUpload (checksum)"}
+		}
+		checksumAlgorithm = request.algorithm
+		opts.UserDefined[checksumAlgorithmMetadataKey] = checksumAlgorithm
+	}
+
+	// Sizes of compressed parts are kept by the gateway, so parts are only
+	// compressed if there's a metadata store.
+	compress := layer.metadataStore != nil && layer.compatibilityConfig.Compression.shouldCompress(bucket, opts.UserDefined)
+	if compress {
+		opts.UserDefined[compressionMetadataKey] = compressionZstd
+	}
+
+	retention, err := layer.newObjectRetention(ctx, bucket)
+	if err != nil {
+		return "", ConvertError(err, bucket, object)
+	}
+	// Locked objects are kept regardless of lifecycle rules. The size of the
+	// object isn't known yet, so rules filtering on size don't apply.
+	if retention == nil {
+		expires, err := layer.lifecycleExpiration(ctx, project, bucket, object, opts.UserDefined["s3:tags"], -1)
+		if err != nil {
+			return "", ConvertError(err, bucket, object)
+		}
+		e = earliestExpiration(e, expires)
+	}
 	info, err := multipart.BeginUpload(ctx, project, bucket, object, &multipart.UploadOptions{
 		// TODO: Truncate works around https://github.com/storj/storj-private/issues/84 until fixed on the satellite.
 		Expires:        e.Truncate(time.Microsecond),
 		CustomMetadata: uplink.CustomMetadata(opts.UserDefined).Clone(),
+		Retention:      satelliteRetention(retention),
 	})
 	if err != nil {
 		return "", convertMultipartError(err, bucket, object, "")
 	}
+
+	if compress {
+		if err := layer.metadataStore.put(storeCompressedParts, storeBucket(ctx, bucket), uploadKey(info.UploadID), nil); err != nil {
+			abortErr := project.AbortUpload(ctx, bucket, object, info.UploadID)
+			return "", ConvertError(errs.Combine(err, abortErr), bucket, object)
+		}
+	}
+
+	// Parts uploaded without checksums of their own, like copied ones, get
+	// theirs computed using the algorithm of the upload.
+	if checksumAlgorithm != "" {
+		if err := layer.metadataStore.put(storePartChecksums, storeBucket(ctx, bucket), uploadKey(info.UploadID), []byte(checksumAlgorithm)); err != nil {
+			abortErr := project.AbortUpload(ctx, bucket, object, info.UploadID)
+			return "", ConvertError(errs.Combine(err, abortErr), bucket, object)
+		}
+	}
+
 	return info.UploadID, nil
 }
 
@@ -162,18 +572,60 @@ func (layer *gatewayLayer) PutObjectPart(ctx context.Context, bucket, object, up
 		return minio.PartInfo{}, err
 	}
 
+	checksumAlgorithm, err := layer.uploadChecksumAlgorithm(ctx, bucket, uploadID)
+	if err != nil {
+		return minio.PartInfo{}, ConvertError(err, bucket, object)
+	}
+
+	checksum, err := newPartChecksum(ctx, checksumAlgorithm)
+	if err != nil {
+		return minio.PartInfo{}, err
+	}
+	if checksum != nil && layer.metadataStore == nil {
+		return minio.PartInfo{}, minio.NotImplemented{Message: "PutObjectPart (checksum)"}
+	}
+
+	compressed, err := layer.compressesUpload(ctx, bucket, uploadID)
+	if err != nil {
+		return minio.PartInfo{}, ConvertError(err, bucket, object)
+	}
+
 	partUpload, err := project.UploadPart(ctx, bucket, object, uploadID, uint32(partID))
 	if err != nil {
 		return minio.PartInfo{}, convertMultipartError(err, bucket, object, uploadID)
 	}
 
-	_, err = sync2.Copy(ctx, partUpload, data)
+	var reader io.Reader = data
+	if checksum != nil {
+		reader = io.TeeReader(data, checksum)
+	}
+
+	var size int64
+	if compressed {
+		var frames compressionFrames
+		size, frames, err = copyCompressed(partUpload, reader)
+		if err == nil {
+			err = layer.metadataStore.put(storeCompressedParts, storeBucket(ctx, bucket), uploadPartKey(uploadID, partID), encodePartSize(size, frames))
+		}
+	} else {
+		_, err = sync2.Copy(ctx, partUpload, reader)
+	}
 	if err != nil {
 		abortErr := partUpload.Abort()
 		err = errs.Combine(err, abortErr)
 		return minio.PartInfo{}, convertMultipartError(err, bucket, object, uploadID)
 	}
 
+	if checksum != nil {
+		sum, err := checksum.sum()
+		if err == nil {
+			err = layer.metadataStore.put(storePartChecksums, storeBucket(ctx, bucket), uploadPartKey(uploadID, partID), encodePartChecksum(checksum.algorithm, sum))
+		}
+		if err != nil {
+			return minio.PartInfo{}, errs.Combine(err, partUpload.Abort())
+		}
+	}
+
 	err = partUpload.SetETag([]byte(data.MD5CurrentHexString()))
 	if err != nil {
 		abortErr := partUpload.Abort()
@@ -187,15 +639,93 @@ func (layer *gatewayLayer) PutObjectPart(ctx context.Context, bucket, object, up
 	}
 
 	part := partUpload.Info()
+	if !compressed {
+		size = part.Size
+	}
 	return minio.PartInfo{
 		PartNumber:   int(part.PartNumber),
-		Size:         part.Size,
-		ActualSize:   part.Size,
+		Size:         size,
+		ActualSize:   size,
 		ETag:         string(part.ETag),
 		LastModified: part.Modified,
 	}, nil
 }
 
+// CopyObjectPart uploads length bytes of srcObject starting at startOffset
+// (as requested by x-amz-copy-source-range) as part partID of uploadID. The
+// range is uploaded again by the gateway, as libuplink can't copy parts
+// server-side. minio has already opened the range to check the preconditions
+// and passes it as srcInfo.PutObjReader, so it's only downloaded here if
+// that's missing. PutObjectPart computes its checksum if the upload has a
+// checksum algorithm.
+func (layer *gatewayLayer) CopyObjectPart(ctx context.Context, srcBucket, srcObject, destBucket, destObject, uploadID string, partID int, startOffset, length int64, srcInfo minio.ObjectInfo, srcOpts, destOpts minio.ObjectOptions) (info minio.PartInfo, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if layer.compatibilityConfig.DisableCopyObject {
+		return minio.PartInfo{}, minio.NotImplemented{Message: "UploadPartCopy"}
+	}
+
+	if err := ValidateBucket(ctx, srcBucket); err != nil {
+		return minio.PartInfo{}, minio.BucketNameInvalid{Bucket: srcBucket}
+	}
+	if err := ValidateBucket(ctx, destBucket); err != nil {
+		return minio.PartInfo{}, minio.BucketNameInvalid{Bucket: destBucket}
+	}
+
+	if srcObject == "" {
+		return minio.PartInfo{}, minio.ObjectNameInvalid{Bucket: srcBucket}
+	}
+
+	if startOffset < 0 || length < 0 || startOffset+length > srcInfo.Size {
+		return minio.PartInfo{}, minio.InvalidRange{
+			OffsetBegin:  startOffset,
+			OffsetEnd:    startOffset + length - 1,
+			ResourceSize: srcInfo.Size,
+		}
+	}
+
+	data := srcInfo.PutObjReader
+	if data == nil {
+		project, err := projectFromContext(ctx, srcBucket, srcObject)
+		if err != nil {
+			return minio.PartInfo{}, err
+		}
+
+		// Download the version that minio checked the preconditions against,
+		// so that the part doesn't mix data of two versions if srcObject is
+		// overwritten in the meantime.
+		versionID := srcOpts.VersionID
+		if versionID == "" {
+			versionID = srcInfo.VersionID
+		}
+
+		version, err := decodeVersionID(versionID)
+		if err != nil {
+			return minio.PartInfo{}, ConvertError(err, srcBucket, srcObject)
+		}
+
+		download, _, err := downloadObject(ctx, project, srcBucket, srcObject, version, &uplink.DownloadOptions{
+			Offset: startOffset,
+			Length: length,
+		})
+		if err != nil {
+			// TODO this should be removed and implemented on satellite side
+			err = checkBucketError(ctx, project, srcBucket, srcObject, err)
+			return minio.PartInfo{}, ConvertError(err, srcBucket, srcObject)
+		}
+		defer func() { _ = download.Close() }()
+
+		hashReader, err := hash.NewReader(download, length, "", "", length)
+		if err != nil {
+			return minio.PartInfo{}, ConvertError(err, srcBucket, srcObject)
+		}
+		data = minio.NewPutObjReader(hashReader)
+	}
+
+	// PutObjectPart sets the ETag of the part to the MD5 of the copied range.
+	return layer.PutObjectPart(ctx, destBucket, destObject, uploadID, partID, data, destOpts)
+}
+
 func (layer *gatewayLayer) GetMultipartInfo(ctx context.Context, bucket, object, uploadID string, opts minio.ObjectOptions) (info minio.MultipartInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
 
@@ -250,6 +780,11 @@ func (layer *gatewayLayer) ListObjectParts(ctx context.Context, bucket, object,
 		return minio.ListPartsInfo{}, err
 	}
 
+	sizes, err := layer.uncompressedPartSizes(ctx, bucket, uploadID)
+	if err != nil {
+		return minio.ListPartsInfo{}, ConvertError(err, bucket, object)
+	}
+
 	list := project.ListUploadParts(ctx, bucket, object, uploadID, &uplink.ListUploadPartsOptions{
 		Cursor: uint32(partNumberMarker),
 	})
@@ -260,12 +795,16 @@ func (layer *gatewayLayer) ListObjectParts(ctx context.Context, bucket, object,
 	for (limit > 0 || maxParts == 0) && list.Next() {
 		limit--
 		part := list.Item()
+		size := part.Size
+		if sizes != nil {
+			size = sizes[int(part.PartNumber)]
+		}
 		parts = append(parts, minio.PartInfo{
 			PartNumber:   int(part.PartNumber),
 			LastModified: part.Modified,
 			ETag:         string(part.ETag), // Entity tag returned when the part was initially uploaded.
-			Size:         part.Size,         // Size in bytes of the part.
-			ActualSize:   part.Size,         // Decompressed Size.
+			Size:         size,              // Size in bytes of the part.
+			ActualSize:   size,              // Decompressed Size.
 		})
 	}
 	if list.Err() != nil {
@@ -316,11 +855,15 @@ func (layer *gatewayLayer) AbortMultipartUpload(ctx context.Context, bucket, obj
 		}
 		return convertMultipartError(err, bucket, object, uploadID)
 	}
+
+	layer.forgetUploadParts(ctx, bucket, uploadID)
+
 	return nil
 }
 
 func (layer *gatewayLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
 	defer mon.Task()(&ctx)(&err)
+	defer layer.notifyCreated(ctx, eventObjectCreatedCompleteMultipartUpload, bucket, object, &objInfo, &err)
 
 	if err := ValidateBucket(ctx, bucket); err != nil {
 		return minio.ObjectInfo{}, minio.BucketNameInvalid{Bucket: bucket}
@@ -331,10 +874,47 @@ func (layer *gatewayLayer) CompleteMultipartUpload(ctx context.Context, bucket,
 		return minio.ObjectInfo{}, err
 	}
 
-	var idx int
+	cache, cacheBucket := layer.bucketCache(ctx, bucket)
+	defer cache.invalidateObject(cacheBucket, object)
+
+	sizes, err := layer.uncompressedPartSizes(ctx, bucket, uploadID)
+	if err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
+	}
+
+	partFrames, err := layer.compressedPartFrames(ctx, bucket, uploadID)
+	if err != nil {
+		return minio.ObjectInfo{}, ConvertError(err, bucket, object)
+	}
+
+	var (
+		idx       int
+		parts     []ObjectPart
+		totalSize int64
+		// Parts are compressed independently, so each starts a frame.
+		frames         compressionFrames
+		compressedSize int64
+	)
 	list := project.ListUploadParts(ctx, bucket, object, uploadID, nil)
 	for ; list.Next(); idx++ {
 		part := list.Item()
+		if sizes != nil {
+			size, ok := sizes[int(part.PartNumber)]
+			if !ok {
+				return minio.ObjectInfo{}, minio.InvalidPart{
+					PartNumber: int(part.PartNumber),
+					ExpETag:    string(part.ETag),
+				}
+			}
+			frames = append(frames, partFrames[int(part.PartNumber)].shift(compressionFrame{
+				offset:           totalSize,
+				compressedOffset: compressedSize,
+			})...)
+			compressedSize += part.Size
+			part.Size = size
+		}
+		parts = append(parts, ObjectPart{PartNumber: int(part.PartNumber), Size: part.Size})
+		totalSize += part.Size
 		// Are we listing past what we received?
 		if idx >= len(uploadedParts) {
 			return minio.ObjectInfo{}, minio.InvalidPart{
@@ -409,6 +989,35 @@ func (layer *gatewayLayer) CompleteMultipartUpload(ctx context.Context, bucket,
 	metadata = metadata.Clone()
 	metadata["s3:etag"] = etag
 
+	if isCompressed(metadata) {
+		metadata[uncompressedSizeMetadataKey] = strconv.FormatInt(totalSize, 10)
+		if index := encodeCompressionFrames(frames); index != "" {
+			metadata[compressionFramesMetadataKey] = index
+		}
+	}
+
+	checksumAlgorithm, ok := metadata[checksumAlgorithmMetadataKey]
+	var partChecksums [][]byte
+	if ok {
+		partChecksums, err = layer.partChecksums(ctx, bucket, uploadID, checksumAlgorithm, uploadedParts)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+		checksum, err := compositeChecksum(checksumAlgorithm, partChecksums)
+		if err != nil {
+			return minio.ObjectInfo{}, err
+		}
+		metadata[checksumMetadataKey(checksumAlgorithm)] = checksum
+		delete(metadata, checksumAlgorithmMetadataKey)
+	}
+
+	unlock := layer.lockConditionalWrite(ctx, bucket, object)
+	defer unlock()
+
+	if err := checkWriteConditions(ctx, project, bucket, object); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
 	obj, err := versioned.CommitUpload(context.Background(), project, bucket, object, uploadID, &uplink.CommitUploadOptions{
 		CustomMetadata: metadata,
 	})
@@ -416,9 +1025,66 @@ func (layer *gatewayLayer) CompleteMultipartUpload(ctx context.Context, bucket,
 		return minio.ObjectInfo{}, convertMultipartError(err, bucket, object, uploadID)
 	}
 
+	layer.forgetUploadParts(ctx, bucket, uploadID)
+
+	if obj != nil {
+		layer.indexObject(ctx, bucket, &uplink.Object{Key: obj.Key, System: obj.System, Custom: metadata})
+
+		if err := layer.putObjectParts(ctx, bucket, object, obj.Version, newObjectParts(parts, checksumAlgorithm, partChecksums)); err != nil {
+			layer.logger.Infof("metadata store: recording parts of %q in %q failed: %v", object, bucket, err)
+		}
+	}
+
 	return minioVersionedObjectInfo(bucket, etag, obj), nil
 }
 
+// uploadChecksumAlgorithm returns the checksum algorithm of the multipart
+// upload uploadID or "" if it has none.
+func (layer *gatewayLayer) uploadChecksumAlgorithm(ctx context.Context, bucket, uploadID string) (string, error) {
+	if layer.metadataStore == nil {
+		return "", nil
+	}
+	value, _, err := layer.metadataStore.get(storePartChecksums, storeBucket(ctx, bucket), uploadKey(uploadID))
+	return string(value), err
+}
+
+// partChecksums returns the checksums of uploadedParts of the multipart
+// upload uploadID computed using algorithm.
+func (layer *gatewayLayer) partChecksums(ctx context.Context, bucket, uploadID, algorithm string, uploadedParts []minio.CompletePart) (_ [][]byte, err error) {
+	if layer.metadataStore == nil {
+		return nil, minio.NotImplemented{Message: "CompleteMultipartUpload (checksum)"}
+	}
+
+	partChecksums := make([][]byte, 0, len(uploadedParts))
+	for _, part := range uploadedParts {
+		value, found, err := layer.metadataStore.get(storePartChecksums, storeBucket(ctx, bucket), uploadPartKey(uploadID, part.PartNumber))
+		if err != nil {
+			return nil, err
+		}
+		sum, ok := decodePartChecksum(algorithm, value)
+		if !found || !ok {
+			return nil, minio.InvalidPart{PartNumber: part.PartNumber, GotETag: part.ETag}
+		}
+		partChecksums = append(partChecksums, sum)
+	}
+
+	return partChecksums, nil
+}
+
+// forgetUploadParts removes checksums and sizes of parts of the multipart
+// upload uploadID from the metadata store.
+func (layer *gatewayLayer) forgetUploadParts(ctx context.Context, bucket, uploadID string) {
+	if layer.metadataStore == nil {
+		return
+	}
+
+	for _, kind := range [][]byte{storePartChecksums, storeCompressedParts} {
+		if err := layer.metadataStore.deletePrefix(kind, storeBucket(ctx, bucket), uploadKey(uploadID)); err != nil {
+			layer.logger.Infof("metadata store: removing %s of %q in %q failed: %v", kind, uploadID, bucket, err)
+		}
+	}
+}
+
 func minioMultipartInfo(bucket string, object *uplink.UploadInfo) minio.MultipartInfo {
 	if object == nil {
 		object = &uplink.UploadInfo{}
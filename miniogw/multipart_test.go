@@ -0,0 +1,287 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/hash"
+	"storj.io/uplink"
+)
+
+// sortedUploads returns an iterator over uploads of keys sorted the way
+// listUploadsExhaustive sorts them.
+func sortedUploads(t *testing.T, keys ...string) *sortedItems[uplink.UploadInfo] {
+	sorter := newSorter(t.TempDir(), 2, lessUpload)
+	t.Cleanup(func() { require.NoError(t, sorter.Close()) })
+
+	for _, key := range keys {
+		require.NoError(t, sorter.Add(&uplink.UploadInfo{Key: key, UploadID: "id-" + key}))
+	}
+
+	it, err := sorter.Iterate()
+	require.NoError(t, err)
+
+	return it
+}
+
+func TestUploadsToPrefixesAndUploads(t *testing.T) {
+	layer := &gatewayLayer{compatibilityConfig: S3CompatibilityConfig{MaxUploadsLimit: 1000}}
+
+	keys := []string{"a-b-c", "a-b-d", "a-c", "a-d-e", "b", "a-a"}
+
+	for i, tt := range [...]struct {
+		prefix, delimiter string
+		maxUploads        int
+		uploads           []string
+		prefixes          []string
+		nextKeyMarker     string
+	}{
+		{"", "-", 1000, []string{"b"}, []string{"a-"}, ""},
+		{"a-", "-", 1000, []string{"a-a", "a-c"}, []string{"a-b-", "a-d-"}, ""},
+		{"a-", "-", 2, []string{"a-a"}, []string{"a-b-"}, "a-b-"},
+		{"a-", "-", 3, []string{"a-a", "a-c"}, []string{"a-b-"}, "a-c"},
+		{"a", "", 1000, []string{"a-a", "a-b-c", "a-b-d", "a-c", "a-d-e"}, nil, ""},
+		{"a-b", "-", 1000, nil, []string{"a-b-"}, ""},
+		{"c", "-", 1000, nil, nil, ""},
+	} {
+		var filtered []string
+		for _, key := range keys {
+			if strings.HasPrefix(key, tt.prefix) {
+				filtered = append(filtered, key)
+			}
+		}
+
+		uploads, prefixes, nextKeyMarker, _, err := layer.uploadsToPrefixesAndUploads(
+			sortedUploads(t, filtered...),
+			"bucket", tt.prefix, tt.delimiter,
+			tt.maxUploads)
+		require.NoError(t, err, i)
+
+		var names []string
+		for _, upload := range uploads {
+			assert.Equal(t, "bucket", upload.Bucket, i)
+			assert.Equal(t, "id-"+upload.Object, upload.UploadID, i)
+			names = append(names, upload.Object)
+		}
+
+		assert.Equal(t, tt.uploads, names, i)
+		assert.Equal(t, tt.prefixes, prefixes, i)
+		assert.Equal(t, tt.nextKeyMarker, nextKeyMarker, i)
+	}
+}
+
+// sliceUploads iterates over uploads in a slice.
+type sliceUploads struct {
+	uploads []*uplink.UploadInfo
+	item    *uplink.UploadInfo
+}
+
+func (it *sliceUploads) Next() bool {
+	if len(it.uploads) == 0 {
+		it.item = nil
+		return false
+	}
+	it.item, it.uploads = it.uploads[0], it.uploads[1:]
+	return true
+}
+
+func (it *sliceUploads) Item() *uplink.UploadInfo { return it.item }
+
+func (it *sliceUploads) Err() error { return nil }
+
+func TestListUploadsManyUploadsPerKey(t *testing.T) {
+	layer := &gatewayLayer{compatibilityConfig: S3CompatibilityConfig{MaxUploadsLimit: 1000}}
+
+	// all contains uploads in the order they are expected to be listed.
+	var all []*uplink.UploadInfo
+	for _, key := range []string{"a", "b", "c"} {
+		n := 1
+		if key == "b" {
+			n = 25
+		}
+		for i := 0; i < n; i++ {
+			all = append(all, &uplink.UploadInfo{Key: key, UploadID: fmt.Sprintf("%s-%02d", key, i)})
+		}
+	}
+
+	// shuffled returns uploads after cursor sorted by key only, the way
+	// libuplink might list them.
+	shuffled := func(after uploadCursor) []*uplink.UploadInfo {
+		var uploads []*uplink.UploadInfo
+		for _, i := range rand.Perm(len(all)) {
+			if !after.skips(all[i].Key, all[i].UploadID, false) {
+				uploads = append(uploads, all[i])
+			}
+		}
+		sort.SliceStable(uploads, func(i, j int) bool {
+			return uploads[i].Key < uploads[j].Key
+		})
+		return uploads
+	}
+
+	iterators := map[string]func(after uploadCursor) uploadIterator{
+		"fast": func(after uploadCursor) uploadIterator {
+			var remaining, rest []*uplink.UploadInfo
+			for _, upload := range shuffled(after) {
+				if upload.Key == after.Key {
+					remaining = append(remaining, upload)
+				} else {
+					rest = append(rest, upload)
+				}
+			}
+			sort.Slice(remaining, func(i, j int) bool {
+				return lessUpload(remaining[i], remaining[j])
+			})
+			return &keySortedUploads{list: &sliceUploads{uploads: rest}, group: remaining}
+		},
+		"exhaustive": func(after uploadCursor) uploadIterator {
+			sorter := newSorter(t.TempDir(), 4, lessUpload)
+			t.Cleanup(func() { require.NoError(t, sorter.Close()) })
+			for _, upload := range shuffled(after) {
+				require.NoError(t, sorter.Add(upload))
+			}
+			it, err := sorter.Iterate()
+			require.NoError(t, err)
+			return it
+		},
+	}
+
+	for name, iterate := range iterators {
+		t.Run(name, func(t *testing.T) {
+			for _, maxUploads := range []int{1, 2, 3, 7, 26, 1000} {
+				var (
+					after  uploadCursor
+					listed []*uplink.UploadInfo
+				)
+
+				for page := 0; ; page++ {
+					require.Less(t, page, len(all)+1, maxUploads)
+
+					uploads, prefixes, nextKeyMarker, nextUploadIDMarker, err := layer.uploadsToPrefixesAndUploads(iterate(after), "bucket", "", "", maxUploads)
+					require.NoError(t, err, maxUploads)
+					assert.Empty(t, prefixes, maxUploads)
+
+					for _, upload := range uploads {
+						listed = append(listed, &uplink.UploadInfo{Key: upload.Object, UploadID: upload.UploadID})
+					}
+
+					if nextKeyMarker == "" {
+						assert.Empty(t, nextUploadIDMarker, maxUploads)
+						break
+					}
+
+					require.NotEmpty(t, uploads, maxUploads)
+					last := uploads[len(uploads)-1]
+					assert.Equal(t, last.Object, nextKeyMarker, maxUploads)
+					assert.Equal(t, last.UploadID, nextUploadIDMarker, maxUploads)
+
+					after = uploadCursor{Key: nextKeyMarker, UploadID: nextUploadIDMarker}
+				}
+
+				assert.Equal(t, all, listed, maxUploads)
+			}
+		})
+	}
+}
+
+func TestUploadCursorSkips(t *testing.T) {
+	for i, tt := range [...]struct {
+		after     uploadCursor
+		key, id   string
+		collapsed bool
+		skips     bool
+	}{
+		{uploadCursor{}, "a", "1", false, false},
+		{uploadCursor{Key: "b"}, "a", "1", false, true},
+		{uploadCursor{Key: "b"}, "b", "1", false, true},
+		{uploadCursor{Key: "b"}, "c", "1", false, false},
+		{uploadCursor{Key: "b", UploadID: "2"}, "b", "1", false, true},
+		{uploadCursor{Key: "b", UploadID: "2"}, "b", "2", false, true},
+		{uploadCursor{Key: "b", UploadID: "2"}, "b", "3", false, false},
+		{uploadCursor{Key: "b/", UploadID: "2"}, "b/", "3", true, true},
+		{uploadCursor{Key: "b", UploadID: "2"}, "a", "3", false, true},
+	} {
+		assert.Equal(t, tt.skips, tt.after.skips(tt.key, tt.id, tt.collapsed), i)
+	}
+}
+
+func TestCopyObjectPart(t *testing.T) {
+	satellite, project := newTestProject(t, "bucket")
+	layer, ctx := newTestLayer(t, project)
+
+	data := []byte("0123456789abcdef")
+	source := putTestObject(ctx, t, layer, "bucket", "source", data, map[string]string{})
+
+	uploadID, err := layer.NewMultipartUpload(ctx, "bucket", "copy", minio.ObjectOptions{UserDefined: map[string]string{}})
+	require.NoError(t, err)
+
+	// copyPart copies the range like minio's CopyObjectPartHandler, which
+	// passes the range it opened to check the preconditions.
+	copyPart := func(partID int, start, length int64) (minio.PartInfo, error) {
+		gr, err := layer.GetObjectNInfo(ctx, "bucket", "source", &minio.HTTPRangeSpec{Start: start, End: start + length - 1}, nil, 0, minio.ObjectOptions{})
+		require.NoError(t, err)
+		defer func() { require.NoError(t, gr.Close()) }()
+
+		reader, err := hash.NewReader(gr, length, "", "", length)
+		require.NoError(t, err)
+
+		srcInfo := gr.ObjInfo
+		srcInfo.PutObjReader = minio.NewPutObjReader(reader)
+
+		downloads := satellite.count("DownloadObject")
+		defer func() { assert.Equal(t, downloads, satellite.count("DownloadObject"), "the range is downloaded again") }()
+
+		return layer.CopyObjectPart(ctx, "bucket", "source", "bucket", "copy", uploadID, partID, start, length, srcInfo, minio.ObjectOptions{}, minio.ObjectOptions{})
+	}
+
+	partETag := func(data string) string {
+		sum := md5.Sum([]byte(data))
+		return hex.EncodeToString(sum[:])
+	}
+
+	first, err := copyPart(1, 2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, partETag("2345"), first.ETag)
+	assert.EqualValues(t, 4, first.Size)
+
+	last, err := copyPart(2, 15, 1)
+	require.NoError(t, err)
+	assert.Equal(t, partETag("f"), last.ETag)
+
+	// Without the range opened by minio, it's downloaded by the gateway.
+	fallback, err := layer.CopyObjectPart(ctx, "bucket", "source", "bucket", "copy", uploadID, 3, 0, 2, source, minio.ObjectOptions{}, minio.ObjectOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, partETag("01"), fallback.ETag)
+
+	for _, tt := range []struct{ start, length int64 }{
+		{start: 15, length: 2},
+		{start: 16, length: 1},
+		{start: -1, length: 1},
+	} {
+		_, err := layer.CopyObjectPart(ctx, "bucket", "source", "bucket", "copy", uploadID, 4, tt.start, tt.length, source, minio.ObjectOptions{}, minio.ObjectOptions{})
+		require.ErrorAs(t, err, &minio.InvalidRange{}, "%+v", tt)
+	}
+
+	info, err := layer.CompleteMultipartUpload(ctx, "bucket", "copy", uploadID, []minio.CompletePart{
+		{PartNumber: 1, ETag: first.ETag},
+		{PartNumber: 2, ETag: last.ETag},
+		{PartNumber: 3, ETag: fallback.ETag},
+	}, minio.ObjectOptions{})
+	require.NoError(t, err)
+
+	copied, copiedData := getTestObject(ctx, t, layer, "bucket", "copy", "")
+	assert.Equal(t, "2345f01", string(copiedData))
+	assert.Equal(t, info.ETag, copied.ETag)
+}
@@ -0,0 +1,466 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	minio "storj.io/minio/cmd"
+)
+
+// BucketNotificationLayer is implemented by object layers that support
+// sending bucket event notifications to webhooks.
+type BucketNotificationLayer interface {
+	GetBucketNotification(ctx context.Context, bucket string) (*BucketNotification, error)
+	SetBucketNotification(ctx context.Context, bucket string, config *BucketNotification) error
+	DeleteBucketNotification(ctx context.Context, bucket string) error
+}
+
+var (
+	_ BucketNotificationLayer = (*gatewayLayer)(nil)
+	_ BucketNotificationLayer = (*singleTenancyLayer)(nil)
+)
+
+// ErrMalformedNotification is a custom error for notification
+// configurations that are invalid.
+var ErrMalformedNotification = miniogo.ErrorResponse{
+	Code:       "MalformedXML",
+	StatusCode: http.StatusBadRequest,
+	Message:    "The notification configuration you provided is not well-formed or did not validate.",
+}
+
+// ErrInvalidNotificationTarget is a custom error for notification
+// configurations referring to webhooks that aren't configured.
+var ErrInvalidNotificationTarget = miniogo.ErrorResponse{
+	Code:       "InvalidArgument",
+	StatusCode: http.StatusBadRequest,
+	Message:    "A specified destination ARN does not exist or is not well-formed.",
+}
+
+// storeNotificationKey is the metadata store key of the notification
+// configuration of a bucket.
+var storeNotificationKey = []byte("notification")
+
+// maxNotificationConfigurations is the maximum number of queue
+// configurations of a notification configuration.
+const maxNotificationConfigurations = 100
+
+// Names of events notifications are sent for.
+const (
+	eventObjectCreatedPut                     = "s3:ObjectCreated:Put"
+	eventObjectCreatedCopy                    = "s3:ObjectCreated:Copy"
+	eventObjectCreatedCompleteMultipartUpload = "s3:ObjectCreated:CompleteMultipartUpload"
+	eventObjectRemovedDelete                  = "s3:ObjectRemoved:Delete"
+	eventObjectRemovedDeleteMarkerCreated     = "s3:ObjectRemoved:DeleteMarkerCreated"
+)
+
+// notificationEvents are the events that can be configured, including
+// wildcards.
+var notificationEvents = map[string]struct{}{
+	"s3:ObjectCreated:*":                         {},
+	eventObjectCreatedPut:                        {},
+	eventObjectCreatedCopy:                       {},
+	eventObjectCreatedCompleteMultipartUpload:    {},
+	"s3:ObjectRemoved:*":                         {},
+	eventObjectRemovedDelete:                     {},
+	eventObjectRemovedDeleteMarkerCreated:        {},
+	"s3:LifecycleExpiration:*":                   {},
+	"s3:LifecycleExpiration:Delete":              {},
+	"s3:LifecycleExpiration:DeleteMarkerCreated": {},
+}
+
+// BucketNotification is the notification configuration of a bucket. Events
+// can only be sent to webhooks, which are configured as queues with ARNs of
+// the form arn:minio:sqs::<id>:webhook.
+type BucketNotification struct {
+	XMLName             xml.Name                         `xml:"http://s3.amazonaws.com/doc/2006-03-01/ NotificationConfiguration"`
+	QueueConfigurations []NotificationQueueConfiguration `xml:"QueueConfiguration"`
+}
+
+// NotificationQueueConfiguration sends Events on objects matching Filter to
+// the webhook identified by Queue.
+type NotificationQueueConfiguration struct {
+	ID     string              `xml:"Id,omitempty"`
+	Queue  string              `xml:"Queue"`
+	Events []string            `xml:"Event"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+// NotificationFilter selects objects by the prefix and suffix of their key.
+type NotificationFilter struct {
+	S3Key NotificationS3KeyFilter `xml:"S3Key"`
+}
+
+// NotificationS3KeyFilter holds at most one prefix and one suffix rule.
+type NotificationS3KeyFilter struct {
+	FilterRules []NotificationFilterRule `xml:"FilterRule"`
+}
+
+// NotificationFilterRule is a prefix or suffix rule of a filter.
+type NotificationFilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// ParseBucketNotification parses and validates a notification configuration
+// that can only refer to webhooks.
+func ParseBucketNotification(r io.Reader) (*BucketNotification, error) {
+	var config struct {
+		XMLName             xml.Name
+		QueueConfigurations []NotificationQueueConfiguration `xml:"QueueConfiguration"`
+		// Other kinds of destinations are rejected.
+		TopicConfigurations         []struct{} `xml:"TopicConfiguration"`
+		CloudFunctionConfigurations []struct{} `xml:"CloudFunctionConfiguration"`
+		EventBridgeConfigurations   []struct{} `xml:"EventBridgeConfiguration"`
+	}
+	if err := xml.NewDecoder(r).Decode(&config); err != nil || config.XMLName.Local != "NotificationConfiguration" {
+		return nil, ErrMalformedNotification
+	}
+	if len(config.TopicConfigurations) > 0 || len(config.CloudFunctionConfigurations) > 0 || len(config.EventBridgeConfigurations) > 0 {
+		return nil, ErrInvalidNotificationTarget
+	}
+
+	notification := &BucketNotification{QueueConfigurations: config.QueueConfigurations}
+	if err := notification.Validate(); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// Validate returns an error if config is invalid. Whether the webhooks it
+// refers to are configured isn't checked.
+func (config *BucketNotification) Validate() error {
+	if len(config.QueueConfigurations) > maxNotificationConfigurations {
+		return ErrMalformedNotification
+	}
+
+	ids := make(map[string]struct{}, len(config.QueueConfigurations))
+	for _, queue := range config.QueueConfigurations {
+		if queue.ID != "" {
+			if _, ok := ids[queue.ID]; ok || len(queue.ID) > 255 {
+				return ErrMalformedNotification
+			}
+			ids[queue.ID] = struct{}{}
+		}
+		if err := queue.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (queue NotificationQueueConfiguration) validate() error {
+	if _, ok := notificationTarget(queue.Queue); !ok {
+		return ErrInvalidNotificationTarget
+	}
+
+	if len(queue.Events) == 0 {
+		return ErrMalformedNotification
+	}
+	for _, event := range queue.Events {
+		if _, ok := notificationEvents[event]; !ok {
+			return ErrMalformedNotification
+		}
+	}
+
+	if queue.Filter != nil {
+		var prefix, suffix int
+		for _, rule := range queue.Filter.S3Key.FilterRules {
+			switch strings.ToLower(rule.Name) {
+			case "prefix":
+				prefix++
+			case "suffix":
+				suffix++
+			default:
+				return ErrMalformedNotification
+			}
+		}
+		if prefix > 1 || suffix > 1 {
+			return ErrMalformedNotification
+		}
+	}
+
+	return nil
+}
+
+// notificationTarget returns the ID of the webhook identified by arn.
+func notificationTarget(arn string) (string, bool) {
+	// arn:minio:sqs:<region>:<id>:webhook
+	parts := strings.Split(arn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[1] != "minio" || parts[2] != "sqs" || parts[4] == "" || parts[5] != "webhook" {
+		return "", false
+	}
+	return parts[4], true
+}
+
+// matches returns whether queue sends event on the object at key.
+func (queue NotificationQueueConfiguration) matches(event, key string) bool {
+	found := false
+	for _, configured := range queue.Events {
+		if configured == event || (strings.HasSuffix(configured, ":*") && strings.HasPrefix(event, strings.TrimSuffix(configured, "*"))) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	if queue.Filter != nil {
+		for _, rule := range queue.Filter.S3Key.FilterRules {
+			switch strings.ToLower(rule.Name) {
+			case "prefix":
+				if !strings.HasPrefix(key, rule.Value) {
+					return false
+				}
+			case "suffix":
+				if !strings.HasSuffix(key, rule.Value) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// GetBucketNotification returns the notification configuration of bucket,
+// which is empty if it has none.
+func (layer *gatewayLayer) GetBucketNotification(ctx context.Context, bucketName string) (_ *BucketNotification, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return nil, err
+	}
+
+	config, err := layer.bucketNotification(ctx, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if config == nil {
+		config = &BucketNotification{}
+	}
+
+	return config, nil
+}
+
+// SetBucketNotification sets the notification configuration of bucket. The
+// configuration is kept in the metadata store, and an empty one removes it.
+func (layer *gatewayLayer) SetBucketNotification(ctx context.Context, bucketName string, config *BucketNotification) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "PutBucketNotificationConfiguration"}
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	for _, queue := range config.QueueConfigurations {
+		if target, _ := notificationTarget(queue.Queue); !layer.notifications.hasTarget(target) {
+			return ErrInvalidNotificationTarget
+		}
+	}
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if len(config.QueueConfigurations) == 0 {
+		return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storeNotificationKey), bucketName, "")
+	}
+
+	value, err := xml.Marshal(config)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	return ConvertError(layer.metadataStore.put(storeBucketConfig, storeBucket(ctx, bucketName), storeNotificationKey, value), bucketName, "")
+}
+
+// DeleteBucketNotification removes the notification configuration of
+// bucket. Events that are already queued are still sent.
+func (layer *gatewayLayer) DeleteBucketNotification(ctx context.Context, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storeNotificationKey), bucketName, "")
+}
+
+// bucketNotification returns the notification configuration of bucket or nil
+// if it has none.
+func (layer *gatewayLayer) bucketNotification(ctx context.Context, bucketName string) (*BucketNotification, error) {
+	if layer.metadataStore == nil {
+		return nil, nil
+	}
+
+	value, found, err := layer.metadataStore.get(storeBucketConfig, storeBucket(ctx, bucketName), storeNotificationKey)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var config BucketNotification
+	if err := xml.Unmarshal(value, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+type notificationSourceKey struct{}
+
+// notificationSource determines which notifications the operations of a
+// context send.
+type notificationSource int
+
+const (
+	// notifyRequests sends notifications of operations requested by clients.
+	notifyRequests notificationSource = iota
+	// notifyNothing sends no notifications, e.g., for operations that are
+	// part of another one that's notified of as a whole.
+	notifyNothing
+	// notifyLifecycle sends notifications of deletions as lifecycle
+	// expirations.
+	notifyLifecycle
+)
+
+// withNotificationSource returns ctx in which operations send notifications
+// according to source.
+func withNotificationSource(ctx context.Context, source notificationSource) context.Context {
+	return context.WithValue(ctx, notificationSourceKey{}, source)
+}
+
+// notificationEventName returns the name of event in ctx or "" if no
+// notification should be sent.
+func notificationEventName(ctx context.Context, event string) string {
+	source, _ := ctx.Value(notificationSourceKey{}).(notificationSource)
+	switch source {
+	case notifyNothing:
+		return ""
+	case notifyLifecycle:
+		if !strings.HasPrefix(event, "s3:ObjectRemoved:") {
+			return ""
+		}
+		return "s3:LifecycleExpiration:" + strings.TrimPrefix(event, "s3:ObjectRemoved:")
+	}
+	return event
+}
+
+// notify queues notifications of event on the object at key in bucket
+// described by info for the webhooks the notification configuration of
+// bucket sends it to. Failing to queue them doesn't fail the operation, so
+// errors are only logged.
+func (layer *gatewayLayer) notify(ctx context.Context, event, bucket, key string, info minio.ObjectInfo) {
+	if layer.notifications == nil {
+		return
+	}
+
+	event = notificationEventName(ctx, event)
+	if event == "" {
+		return
+	}
+
+	config, err := layer.bucketNotification(ctx, bucket)
+	if err != nil {
+		layer.logger.Infof("notification: reading configuration of %q failed: %v", bucket, err)
+		return
+	}
+	if config == nil {
+		return
+	}
+
+	now := time.Now()
+	for _, queue := range config.QueueConfigurations {
+		if !queue.matches(event, key) {
+			continue
+		}
+		target, _ := notificationTarget(queue.Queue)
+
+		body, err := json.Marshal(notificationMessage{
+			Records: []notificationRecord{newNotificationRecord(event, queue.ID, bucket, key, info, now)},
+		})
+		if err == nil {
+			err = layer.notifications.enqueue(storeBucket(ctx, bucket), target, body, now)
+		}
+		if err != nil {
+			layer.logger.Infof("notification: queueing %s of %q in %q for %q failed: %v", event, key, bucket, target, err)
+		}
+	}
+}
+
+// notifyCreated queues notifications of event on the object at key in bucket
+// described by *info unless *err is set. It's meant to be deferred by
+// operations creating objects.
+func (layer *gatewayLayer) notifyCreated(ctx context.Context, event, bucket, key string, info *minio.ObjectInfo, err *error) {
+	if *err == nil {
+		layer.notify(ctx, event, bucket, key, *info)
+	}
+}
+
+// notificationMessage is the body of requests sending events to webhooks.
+type notificationMessage struct {
+	Records []notificationRecord `json:"Records"`
+}
+
+// notificationRecord is an event in the format of S3 event notifications.
+type notificationRecord struct {
+	EventVersion string `json:"eventVersion"`
+	EventSource  string `json:"eventSource"`
+	AWSRegion    string `json:"awsRegion"`
+	EventTime    string `json:"eventTime"`
+	EventName    string `json:"eventName"`
+	S3           struct {
+		SchemaVersion   string `json:"s3SchemaVersion"`
+		ConfigurationID string `json:"configurationId"`
+		Bucket          struct {
+			Name string `json:"name"`
+			ARN  string `json:"arn"`
+		} `json:"bucket"`
+		Object struct {
+			Key       string `json:"key"`
+			Size      int64  `json:"size,omitempty"`
+			ETag      string `json:"eTag,omitempty"`
+			VersionID string `json:"versionId,omitempty"`
+			Sequencer string `json:"sequencer"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+func newNotificationRecord(event, configurationID, bucket, key string, info minio.ObjectInfo, now time.Time) notificationRecord {
+	var record notificationRecord
+	record.EventVersion = "2.1"
+	record.EventSource = "aws:s3"
+	record.EventTime = now.UTC().Format("2006-01-02T15:04:05.000Z")
+	record.EventName = strings.TrimPrefix(event, "s3:")
+	record.S3.SchemaVersion = "1.0"
+	record.S3.ConfigurationID = configurationID
+	record.S3.Bucket.Name = bucket
+	record.S3.Bucket.ARN = "arn:aws:s3:::" + bucket
+	// S3 escapes keys the same way as query parameter values.
+	record.S3.Object.Key = url.QueryEscape(key)
+	record.S3.Object.Size = info.Size
+	record.S3.Object.ETag = info.ETag
+	record.S3.Object.VersionID = info.VersionID
+	record.S3.Object.Sequencer = fmt.Sprintf("%016X", now.UnixNano())
+	return record
+}
@@ -0,0 +1,285 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// notificationError is the error class for bucket event notifications.
+var notificationError = errs.Class("notification")
+
+// queuedNotification is an event waiting in the metadata store to be sent to
+// a webhook.
+type queuedNotification struct {
+	Target      string          `json:"target"`
+	Created     time.Time       `json:"created"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	Message     json.RawMessage `json:"message"`
+}
+
+// notificationWorker sends queued bucket event notifications to webhooks in
+// the background. Events are queued in the metadata store, so events that
+// haven't been sent before the gateway stops are sent once it starts again.
+// Failed events are retried until they're older than the maximum age.
+type notificationWorker struct {
+	// targets maps IDs of webhooks to their URLs.
+	targets       map[string]string
+	client        *http.Client
+	retryInterval time.Duration
+	maxAge        time.Duration
+	store         *metadataStore
+	logger        debugLogger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// wake is signaled when an event is queued.
+	wake chan struct{}
+
+	mu       sync.Mutex
+	sequence uint32
+}
+
+// startNotificationWorker starts sending events queued in store to the
+// webhooks configured by config.
+func startNotificationWorker(config NotificationConfig, store *metadataStore, logger debugLogger) (*notificationWorker, error) {
+	targets := make(map[string]string, len(config.Webhooks))
+	for _, webhook := range config.Webhooks {
+		id, target, ok := strings.Cut(webhook, "=")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, notificationError.New("invalid webhook %q", webhook)
+		}
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, notificationError.New("invalid URL of webhook %q", id)
+		}
+		targets[id] = target
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	worker := &notificationWorker{
+		targets:       targets,
+		client:        &http.Client{Timeout: config.Timeout},
+		retryInterval: config.RetryInterval,
+		maxAge:        config.MaxAge,
+		store:         store,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+		wake:          make(chan struct{}, 1),
+	}
+
+	worker.wg.Add(1)
+	go func() {
+		defer worker.wg.Done()
+		worker.run()
+	}()
+
+	return worker, nil
+}
+
+// Close stops sending events. Events that haven't been sent stay queued.
+func (worker *notificationWorker) Close() {
+	if worker == nil {
+		return
+	}
+
+	worker.cancel()
+	worker.wg.Wait()
+}
+
+// hasTarget returns whether the webhook with id is configured.
+func (worker *notificationWorker) hasTarget(id string) bool {
+	if worker == nil {
+		return false
+	}
+
+	_, ok := worker.targets[id]
+	return ok
+}
+
+// enqueue queues message about an event in bucket that happened at now to be
+// sent to the webhook target.
+func (worker *notificationWorker) enqueue(bucket projectBucket, target string, message []byte, now time.Time) error {
+	value, err := json.Marshal(queuedNotification{
+		Target:      target,
+		Created:     now,
+		NextAttempt: now,
+		Message:     message,
+	})
+	if err != nil {
+		return notificationError.Wrap(err)
+	}
+
+	// Keys sort in the order events are queued.
+	worker.mu.Lock()
+	worker.sequence++
+	key := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint64(nil, uint64(now.UnixNano())), worker.sequence)
+	worker.mu.Unlock()
+
+	if err := worker.store.put(storeNotificationQueue, bucket, key, value); err != nil {
+		return err
+	}
+
+	select {
+	case worker.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (worker *notificationWorker) run() {
+	for {
+		next := worker.sendDue(time.Now())
+
+		var (
+			timer *time.Timer
+			retry <-chan time.Time
+		)
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			retry = timer.C
+		}
+
+		select {
+		case <-worker.ctx.Done():
+		case <-worker.wake:
+		case <-retry:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if worker.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// sendDue sends the queued events due at now and returns when the next event
+// is due, or zero time if there are none.
+func (worker *notificationWorker) sendDue(now time.Time) (next time.Time) {
+	type entry struct {
+		bucket       projectBucket
+		key          []byte
+		notification queuedNotification
+		// invalid is set for entries that can't be read.
+		invalid bool
+	}
+
+	var due []entry
+	err := worker.store.forEach(storeNotificationQueue, func(bucket projectBucket, key, value []byte) error {
+		var notification queuedNotification
+		if err := json.Unmarshal(value, &notification); err != nil {
+			due = append(due, entry{bucket: bucket, key: bytes.Clone(key), invalid: true})
+			return nil
+		}
+		if notification.NextAttempt.After(now) {
+			if next.IsZero() || notification.NextAttempt.Before(next) {
+				next = notification.NextAttempt
+			}
+			return nil
+		}
+		due = append(due, entry{bucket: bucket, key: bytes.Clone(key), notification: notification})
+		return nil
+	})
+	if err != nil {
+		worker.logger.Infof("notification: reading queue failed: %v", err)
+		return now.Add(worker.retryInterval)
+	}
+
+	// Events are sent in the order they were queued, regardless of their
+	// bucket.
+	sort.Slice(due, func(i, j int) bool {
+		return bytes.Compare(due[i].key, due[j].key) < 0
+	})
+
+	for _, entry := range due {
+		if worker.ctx.Err() != nil {
+			return time.Time{}
+		}
+
+		notification := entry.notification
+		if entry.invalid {
+			worker.logger.Infof("notification: dropping invalid event in %q", entry.bucket.name)
+			if err := worker.store.delete(storeNotificationQueue, entry.bucket, entry.key); err != nil {
+				worker.logger.Infof("notification: removing event in %q from queue failed: %v", entry.bucket.name, err)
+			}
+			continue
+		}
+
+		err := worker.send(notification)
+		switch {
+		case worker.ctx.Err() != nil:
+			// Events interrupted by closing the worker are sent again
+			// after a restart.
+			return time.Time{}
+		case err == nil:
+		case now.Sub(notification.Created) >= worker.maxAge:
+			worker.logger.Infof("notification: dropping event in %q for %q after %d attempts: %v", entry.bucket.name, notification.Target, notification.Attempts+1, err)
+		default:
+			notification.Attempts++
+			notification.NextAttempt = now.Add(worker.retryInterval)
+			if next.IsZero() || notification.NextAttempt.Before(next) {
+				next = notification.NextAttempt
+			}
+
+			value, err := json.Marshal(notification)
+			if err == nil {
+				err = worker.store.put(storeNotificationQueue, entry.bucket, entry.key, value)
+			}
+			if err != nil {
+				worker.logger.Infof("notification: rescheduling event in %q for %q failed: %v", entry.bucket.name, notification.Target, err)
+			}
+			continue
+		}
+
+		if err := worker.store.delete(storeNotificationQueue, entry.bucket, entry.key); err != nil {
+			worker.logger.Infof("notification: removing event in %q for %q from queue failed: %v", entry.bucket.name, notification.Target, err)
+		}
+	}
+
+	return next
+}
+
+// send sends the message of notification to its webhook.
+func (worker *notificationWorker) send(notification queuedNotification) error {
+	target, ok := worker.targets[notification.Target]
+	if !ok {
+		return notificationError.New("webhook %q isn't configured", notification.Target)
+	}
+
+	req, err := http.NewRequestWithContext(worker.ctx, http.MethodPost, target, bytes.NewReader(notification.Message))
+	if err != nil {
+		return notificationError.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := worker.client.Do(req)
+	if err != nil {
+		return notificationError.Wrap(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return notificationError.New("webhook %q responded with %s", notification.Target, resp.Status)
+	}
+
+	return nil
+}
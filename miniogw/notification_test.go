@@ -0,0 +1,325 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/notification"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+)
+
+func TestParseBucketNotification(t *testing.T) {
+	config, err := ParseBucketNotification(strings.NewReader(`<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+		<QueueConfiguration>
+			<Id>uploads</Id>
+			<Queue>arn:minio:sqs::pipeline:webhook</Queue>
+			<Event>s3:ObjectCreated:*</Event>
+			<Event>s3:ObjectRemoved:Delete</Event>
+			<Filter><S3Key>
+				<FilterRule><Name>prefix</Name><Value>incoming/</Value></FilterRule>
+				<FilterRule><Name>suffix</Name><Value>.csv</Value></FilterRule>
+			</S3Key></Filter>
+		</QueueConfiguration>
+	</NotificationConfiguration>`))
+	require.NoError(t, err)
+	require.Len(t, config.QueueConfigurations, 1)
+	assert.Equal(t, "uploads", config.QueueConfigurations[0].ID)
+	assert.Equal(t, []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:Delete"}, config.QueueConfigurations[0].Events)
+	assert.Len(t, config.QueueConfigurations[0].Filter.S3Key.FilterRules, 2)
+
+	// An empty configuration disables notifications.
+	config, err = ParseBucketNotification(strings.NewReader(`<NotificationConfiguration></NotificationConfiguration>`))
+	require.NoError(t, err)
+	assert.Empty(t, config.QueueConfigurations)
+
+	for _, body := range []string{
+		`not xml`,
+		`<Other></Other>`,
+		// no events
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:minio:sqs::a:webhook</Queue></QueueConfiguration></NotificationConfiguration>`,
+		// unknown event
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:minio:sqs::a:webhook</Queue><Event>s3:ObjectRestore:*</Event></QueueConfiguration></NotificationConfiguration>`,
+		// duplicate IDs
+		`<NotificationConfiguration><QueueConfiguration><Id>a</Id><Queue>arn:minio:sqs::a:webhook</Queue><Event>s3:ObjectCreated:*</Event></QueueConfiguration>` +
+			`<QueueConfiguration><Id>a</Id><Queue>arn:minio:sqs::a:webhook</Queue><Event>s3:ObjectRemoved:*</Event></QueueConfiguration></NotificationConfiguration>`,
+		// two prefix rules
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:minio:sqs::a:webhook</Queue><Event>s3:ObjectCreated:*</Event><Filter><S3Key>` +
+			`<FilterRule><Name>prefix</Name><Value>a</Value></FilterRule><FilterRule><Name>prefix</Name><Value>b</Value></FilterRule></S3Key></Filter></QueueConfiguration></NotificationConfiguration>`,
+		// unknown rule
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:minio:sqs::a:webhook</Queue><Event>s3:ObjectCreated:*</Event><Filter><S3Key>` +
+			`<FilterRule><Name>contains</Name><Value>a</Value></FilterRule></S3Key></Filter></QueueConfiguration></NotificationConfiguration>`,
+	} {
+		_, err := ParseBucketNotification(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrMalformedNotification, body)
+	}
+
+	for _, body := range []string{
+		`<NotificationConfiguration><QueueConfiguration><Queue>arn:aws:sqs:us-east-1:123:queue</Queue><Event>s3:ObjectCreated:*</Event></QueueConfiguration></NotificationConfiguration>`,
+		`<NotificationConfiguration><TopicConfiguration><Topic>arn:aws:sns:us-east-1:123:topic</Topic><Event>s3:ObjectCreated:*</Event></TopicConfiguration></NotificationConfiguration>`,
+	} {
+		_, err := ParseBucketNotification(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrInvalidNotificationTarget, body)
+	}
+}
+
+func TestNotificationQueueConfigurationMatches(t *testing.T) {
+	queue := NotificationQueueConfiguration{
+		Events: []string{"s3:ObjectCreated:*", eventObjectRemovedDelete},
+		Filter: &NotificationFilter{S3Key: NotificationS3KeyFilter{FilterRules: []NotificationFilterRule{
+			{Name: "Prefix", Value: "incoming/"},
+			{Name: "Suffix", Value: ".csv"},
+		}}},
+	}
+
+	assert.True(t, queue.matches(eventObjectCreatedPut, "incoming/a.csv"))
+	assert.True(t, queue.matches(eventObjectCreatedCompleteMultipartUpload, "incoming/b.csv"))
+	assert.True(t, queue.matches(eventObjectRemovedDelete, "incoming/a.csv"))
+	assert.False(t, queue.matches(eventObjectRemovedDeleteMarkerCreated, "incoming/a.csv"))
+	assert.False(t, queue.matches(eventObjectCreatedPut, "outgoing/a.csv"))
+	assert.False(t, queue.matches(eventObjectCreatedPut, "incoming/a.json"))
+
+	ctx := context.Background()
+	assert.Equal(t, eventObjectCreatedPut, notificationEventName(ctx, eventObjectCreatedPut))
+	assert.Empty(t, notificationEventName(withNotificationSource(ctx, notifyNothing), eventObjectCreatedPut))
+
+	lifecycleCtx := withNotificationSource(ctx, notifyLifecycle)
+	assert.Equal(t, "s3:LifecycleExpiration:Delete", notificationEventName(lifecycleCtx, eventObjectRemovedDelete))
+	assert.Equal(t, "s3:LifecycleExpiration:DeleteMarkerCreated", notificationEventName(lifecycleCtx, eventObjectRemovedDeleteMarkerCreated))
+}
+
+// notificationTestWebhook records the messages it receives. It fails
+// requests while failing is set.
+type notificationTestWebhook struct {
+	mu       sync.Mutex
+	failing  bool
+	attempts int
+	messages []notificationMessage
+}
+
+func (webhook *notificationTestWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+
+	webhook.attempts++
+	if webhook.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var message notificationMessage
+	if err := json.Unmarshal(body, &message); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	webhook.messages = append(webhook.messages, message)
+}
+
+func (webhook *notificationTestWebhook) received() []notificationMessage {
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	return append([]notificationMessage(nil), webhook.messages...)
+}
+
+func TestNotificationDelivery(t *testing.T) {
+	webhook := &notificationTestWebhook{failing: true}
+	server := httptest.NewServer(webhook)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "metadata.db")
+	store, err := openMetadataStore(path)
+	require.NoError(t, err)
+
+	config := NotificationConfig{
+		Webhooks:      []string{"pipeline=" + server.URL},
+		Timeout:       time.Second,
+		RetryInterval: 10 * time.Millisecond,
+		MaxAge:        time.Hour,
+	}
+	logger := zap.NewNop().Sugar()
+
+	worker, err := startNotificationWorker(config, store, logger)
+	require.NoError(t, err)
+
+	layer := &gatewayLayer{logger: logger, metadataStore: store, notifications: worker}
+
+	value, err := xml.Marshal(&BucketNotification{QueueConfigurations: []NotificationQueueConfiguration{{
+		ID:     "uploads",
+		Queue:  "arn:minio:sqs::pipeline:webhook",
+		Events: []string{"s3:ObjectCreated:*"},
+	}}})
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeBucketConfig, projectBucket{name: "bucket"}, storeNotificationKey, value))
+
+	ctx := context.Background()
+	layer.notify(ctx, eventObjectCreatedPut, "bucket", "docs/a b.txt", minio.ObjectInfo{Size: 42, ETag: "etag", VersionID: "v1"})
+	// Events that don't match any configuration and operations that are
+	// part of others aren't sent.
+	layer.notify(ctx, eventObjectRemovedDelete, "bucket", "docs/a b.txt", minio.ObjectInfo{})
+	layer.notify(withNotificationSource(ctx, notifyNothing), eventObjectCreatedPut, "bucket", "docs/c.txt", minio.ObjectInfo{})
+
+	// Events are kept while the webhook fails.
+	require.Eventually(t, func() bool {
+		webhook.mu.Lock()
+		defer webhook.mu.Unlock()
+		return webhook.attempts >= 2
+	}, 5*time.Second, 5*time.Millisecond)
+
+	webhook.mu.Lock()
+	webhook.failing = false
+	webhook.mu.Unlock()
+
+	require.Eventually(t, func() bool { return len(webhook.received()) == 1 }, 5*time.Second, 5*time.Millisecond)
+
+	record := webhook.received()[0].Records[0]
+	assert.Equal(t, "aws:s3", record.EventSource)
+	assert.Equal(t, "ObjectCreated:Put", record.EventName)
+	assert.Equal(t, "uploads", record.S3.ConfigurationID)
+	assert.Equal(t, "bucket", record.S3.Bucket.Name)
+	assert.Equal(t, "arn:aws:s3:::bucket", record.S3.Bucket.ARN)
+	assert.Equal(t, "docs%2Fa+b.txt", record.S3.Object.Key)
+	assert.EqualValues(t, 42, record.S3.Object.Size)
+	assert.Equal(t, "etag", record.S3.Object.ETag)
+	assert.Equal(t, "v1", record.S3.Object.VersionID)
+
+	// Sent events are removed from the queue.
+	require.Eventually(t, func() bool { return queuedNotifications(t, store) == 0 }, 5*time.Second, 5*time.Millisecond)
+
+	// Events that haven't been sent before the gateway stops are sent once
+	// it starts again.
+	worker.Close()
+	layer.notify(ctx, eventObjectCreatedCopy, "bucket", "docs/d.txt", minio.ObjectInfo{})
+	require.NoError(t, store.Close())
+	assert.Len(t, webhook.received(), 1)
+
+	store, err = openMetadataStore(path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+	require.Equal(t, 1, queuedNotifications(t, store))
+
+	worker, err = startNotificationWorker(config, store, logger)
+	require.NoError(t, err)
+	defer worker.Close()
+
+	require.Eventually(t, func() bool { return len(webhook.received()) == 2 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "ObjectCreated:Copy", webhook.received()[1].Records[0].EventName)
+}
+
+func TestNotificationWorkerConfig(t *testing.T) {
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	for _, webhook := range []string{"pipeline", "=http://localhost", "a:b=http://localhost", "pipeline=localhost:8080", "pipeline=ftp://localhost"} {
+		_, err := startNotificationWorker(NotificationConfig{Webhooks: []string{webhook}}, store, zap.NewNop().Sugar())
+		assert.Error(t, err, webhook)
+	}
+
+	var nilWorker *notificationWorker
+	assert.False(t, nilWorker.hasTarget("pipeline"))
+	nilWorker.Close()
+}
+
+func queuedNotifications(t *testing.T, store *metadataStore) (count int) {
+	require.NoError(t, store.forEach(storeNotificationQueue, func(bucket projectBucket, key, value []byte) error {
+		count++
+		return nil
+	}))
+	return count
+}
+
+// notificationTestLayer is an object layer keeping notification
+// configurations in memory.
+type notificationTestLayer struct {
+	minio.ObjectLayer
+
+	configs map[string]*BucketNotification
+}
+
+func (layer *notificationTestLayer) GetBucketNotification(ctx context.Context, bucket string) (*BucketNotification, error) {
+	config, ok := layer.configs[bucket]
+	if !ok {
+		return &BucketNotification{}, nil
+	}
+	return config, nil
+}
+
+func (layer *notificationTestLayer) SetBucketNotification(ctx context.Context, bucket string, config *BucketNotification) error {
+	if len(config.QueueConfigurations) == 0 {
+		delete(layer.configs, bucket)
+		return nil
+	}
+	layer.configs[bucket] = config
+	return nil
+}
+
+func (layer *notificationTestLayer) DeleteBucketNotification(ctx context.Context, bucket string) error {
+	delete(layer.configs, bucket)
+	return nil
+}
+
+func TestBucketNotificationAPI(t *testing.T) {
+	layer := &notificationTestLayer{configs: make(map[string]*BucketNotification)}
+	client, url := startAPITestServer(t, layer)
+
+	ctx := context.Background()
+
+	config, err := client.GetBucketNotification(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Empty(t, config.QueueConfigs)
+
+	queue := notification.NewConfig(notification.NewArn("minio", "sqs", "", "pipeline", "webhook"))
+	queue.AddEvents(notification.ObjectCreatedAll)
+	queue.AddFilterPrefix("incoming/")
+	config = notification.Configuration{}
+	require.True(t, config.AddQueue(queue))
+	require.NoError(t, client.SetBucketNotification(ctx, "bucket", config))
+
+	require.Contains(t, layer.configs, "bucket")
+	assert.Equal(t, "arn:minio:sqs::pipeline:webhook", layer.configs["bucket"].QueueConfigurations[0].Queue)
+
+	config, err = client.GetBucketNotification(ctx, "bucket")
+	require.NoError(t, err)
+	require.Len(t, config.QueueConfigs, 1)
+	assert.Equal(t, "arn:minio:sqs::pipeline:webhook", config.QueueConfigs[0].Queue)
+	assert.Equal(t, []notification.EventType{notification.ObjectCreatedAll}, config.QueueConfigs[0].Events)
+	assert.Equal(t, "incoming/", config.QueueConfigs[0].Filter.S3Key.FilterRules[0].Value)
+
+	// Only webhooks are supported.
+	topic := notification.NewConfig(notification.NewArn("aws", "sns", "us-east-1", "123", "topic"))
+	topic.AddEvents(notification.ObjectCreatedAll)
+	invalid := notification.Configuration{}
+	require.True(t, invalid.AddTopic(topic))
+	err = client.SetBucketNotification(ctx, "bucket", invalid)
+	assert.Equal(t, ErrInvalidNotificationTarget.Code, miniogo.ToErrorResponse(err).Code)
+
+	require.NoError(t, client.RemoveAllBucketNotification(ctx, "bucket"))
+	assert.Empty(t, layer.configs)
+
+	other, err := miniogo.New(strings.TrimPrefix(url, "http://"), &miniogo.Options{
+		Creds:  credentials.NewStaticV4("other", "secret", ""),
+		Region: "us-east-1",
+	})
+	require.NoError(t, err)
+	_, err = other.GetBucketNotification(ctx, "bucket")
+	assert.Equal(t, "AccessDenied", miniogo.ToErrorResponse(err).Code)
+}
@@ -0,0 +1,305 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/cmd/config/storageclass"
+	versioned "storj.io/uplink/private/object"
+)
+
+// ObjectAttributesLayer is implemented by object layers that support
+// GetObjectAttributes.
+type ObjectAttributesLayer interface {
+	GetObjectAttributes(ctx context.Context, bucket, object string, opts ObjectAttributesOptions) (ObjectAttributes, error)
+}
+
+var (
+	_ ObjectAttributesLayer = (*gatewayLayer)(nil)
+	_ ObjectAttributesLayer = (*singleTenancyLayer)(nil)
+)
+
+// maxObjectAttributesParts is the default and maximum number of parts
+// returned by GetObjectAttributes.
+const maxObjectAttributesParts = 1000
+
+// ObjectAttributesOptions are the options of GetObjectAttributes.
+type ObjectAttributesOptions struct {
+	VersionID string
+	// MaxParts is the maximum number of parts to list. It defaults to
+	// maxObjectAttributesParts.
+	MaxParts int
+	// PartNumberMarker is the part number after which parts are listed.
+	PartNumberMarker int
+}
+
+// ObjectAttributes are the attributes of an object version, as returned in
+// the body of GetObjectAttributes responses.
+type ObjectAttributes struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ GetObjectAttributesResponse"`
+
+	// VersionID and LastModified are returned as headers.
+	VersionID    string    `xml:"-"`
+	LastModified time.Time `xml:"-"`
+
+	ETag         string                 `xml:"ETag,omitempty"`
+	Checksum     *ObjectChecksum        `xml:"Checksum,omitempty"`
+	ObjectParts  *ObjectAttributesParts `xml:"ObjectParts,omitempty"`
+	StorageClass string                 `xml:"StorageClass,omitempty"`
+	ObjectSize   int64                  `xml:"ObjectSize"`
+}
+
+// objectAttributesResponse is the body of GetObjectAttributes responses,
+// which hold only the requested attributes.
+type objectAttributesResponse struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ GetObjectAttributesResponse"`
+
+	ETag         string                 `xml:"ETag,omitempty"`
+	Checksum     *ObjectChecksum        `xml:"Checksum,omitempty"`
+	ObjectParts  *ObjectAttributesParts `xml:"ObjectParts,omitempty"`
+	StorageClass string                 `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64                 `xml:"ObjectSize,omitempty"`
+}
+
+// selectObjectAttributes returns the body of a GetObjectAttributes response
+// with the attributes named in names, the values of the
+// X-Amz-Object-Attributes header.
+func selectObjectAttributes(attributes ObjectAttributes, names []string) (objectAttributesResponse, error) {
+	var response objectAttributesResponse
+	var selected bool
+	for _, value := range names {
+		for _, name := range strings.Split(value, ",") {
+			switch strings.TrimSpace(name) {
+			case "":
+				continue
+			case "ETag":
+				response.ETag = attributes.ETag
+			case "Checksum":
+				response.Checksum = attributes.Checksum
+			case "ObjectParts":
+				response.ObjectParts = attributes.ObjectParts
+			case "StorageClass":
+				response.StorageClass = attributes.StorageClass
+			case "ObjectSize":
+				size := attributes.ObjectSize
+				response.ObjectSize = &size
+			default:
+				return objectAttributesResponse{}, errors.New("invalid attribute name: " + strings.TrimSpace(name))
+			}
+			selected = true
+		}
+	}
+	if !selected {
+		return objectAttributesResponse{}, errors.New("x-amz-object-attributes header is required")
+	}
+	return response, nil
+}
+
+// ObjectChecksum holds the checksums of an object or one of its parts.
+type ObjectChecksum struct {
+	ChecksumCRC32  string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumSHA1   string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256,omitempty"`
+}
+
+// set sets the base64-encoded checksum computed using algorithm.
+func (checksum *ObjectChecksum) set(algorithm, value string) {
+	switch algorithm {
+	case checksumCRC32:
+		checksum.ChecksumCRC32 = value
+	case checksumCRC32C:
+		checksum.ChecksumCRC32C = value
+	case checksumSHA1:
+		checksum.ChecksumSHA1 = value
+	case checksumSHA256:
+		checksum.ChecksumSHA256 = value
+	}
+}
+
+// ObjectAttributesParts is the listing of parts of an object uploaded using
+// multipart upload.
+type ObjectAttributesParts struct {
+	TotalPartsCount      int          `xml:"TotalPartsCount"`
+	PartNumberMarker     int          `xml:"PartNumberMarker,omitempty"`
+	NextPartNumberMarker int          `xml:"NextPartNumberMarker,omitempty"`
+	MaxParts             int          `xml:"MaxParts,omitempty"`
+	IsTruncated          bool         `xml:"IsTruncated"`
+	Parts                []ObjectPart `xml:"Part"`
+}
+
+// ObjectPart is a part of an object uploaded using multipart upload.
+type ObjectPart struct {
+	ObjectChecksum
+	PartNumber int   `xml:"PartNumber"`
+	Size       int64 `xml:"Size"`
+}
+
+// GetObjectAttributes returns the ETag, size, storage class, checksum and
+// parts of the object version. Parts are listed only if they were recorded
+// in the metadata store when the multipart upload completed; otherwise only
+// their count is known.
+func (layer *gatewayLayer) GetObjectAttributes(ctx context.Context, bucketName, objectPath string, opts ObjectAttributesOptions) (_ ObjectAttributes, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return ObjectAttributes{}, minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if opts.MaxParts < 0 || opts.PartNumberMarker < 0 {
+		return ObjectAttributes{}, minio.InvalidArgument{Bucket: bucketName, Object: objectPath}
+	}
+	if opts.MaxParts == 0 || opts.MaxParts > maxObjectAttributesParts {
+		opts.MaxParts = maxObjectAttributesParts
+	}
+
+	project, err := projectFromContext(ctx, bucketName, objectPath)
+	if err != nil {
+		return ObjectAttributes{}, err
+	}
+
+	version, err := decodeVersionID(opts.VersionID)
+	if err != nil {
+		return ObjectAttributes{}, ConvertError(err, bucketName, objectPath)
+	}
+
+	object, err := versioned.StatObject(ctx, project, bucketName, objectPath, version)
+	if err != nil {
+		err = checkBucketError(ctx, project, bucketName, objectPath, err)
+		return ObjectAttributes{}, ConvertError(err, bucketName, objectPath)
+	}
+	if object.IsDeleteMarker {
+		if version != nil {
+			return ObjectAttributes{}, minio.MethodNotAllowed{Bucket: bucketName, Object: objectPath}
+		}
+		return ObjectAttributes{}, minio.ObjectNotFound{Bucket: bucketName, Object: objectPath}
+	}
+
+	etag := object.Custom["s3:etag"]
+
+	attributes := ObjectAttributes{
+		VersionID:    encodeVersionID(object.Version),
+		LastModified: object.System.Created,
+		ETag:         etag,
+		StorageClass: storageclass.STANDARD,
+		ObjectSize:   objectSize(&object.Object),
+	}
+
+	var checksum ObjectChecksum
+	for _, algorithm := range checksumAlgorithms {
+		if value, ok := object.Custom[checksumMetadataKey(algorithm)]; ok {
+			checksum.set(algorithm, value)
+		}
+	}
+	if checksum != (ObjectChecksum{}) {
+		attributes.Checksum = &checksum
+	}
+
+	parts, err := layer.getObjectParts(ctx, bucketName, objectPath, object.Version)
+	if err != nil {
+		return ObjectAttributes{}, ConvertError(err, bucketName, objectPath)
+	}
+	if parts != nil {
+		attributes.ObjectParts = paginateObjectParts(parts, opts.PartNumberMarker, opts.MaxParts)
+	} else if count, ok := multipartETagPartCount(etag); ok {
+		attributes.ObjectParts = &ObjectAttributesParts{TotalPartsCount: count}
+	}
+
+	return attributes, nil
+}
+
+// multipartETagPartCount returns the number of parts of an object with etag
+// if it was uploaded using multipart upload.
+func multipartETagPartCount(etag string) (int, bool) {
+	i := strings.LastIndexByte(etag, '-')
+	if i < 0 {
+		return 0, false
+	}
+	count, err := strconv.Atoi(etag[i+1:])
+	if err != nil || count < 1 {
+		return 0, false
+	}
+	return count, true
+}
+
+// paginateObjectParts returns at most maxParts of parts after the part
+// numbered partNumberMarker.
+func paginateObjectParts(parts []ObjectPart, partNumberMarker, maxParts int) *ObjectAttributesParts {
+	result := &ObjectAttributesParts{
+		TotalPartsCount:  len(parts),
+		PartNumberMarker: partNumberMarker,
+		MaxParts:         maxParts,
+	}
+
+	i := sort.Search(len(parts), func(i int) bool {
+		return parts[i].PartNumber > partNumberMarker
+	})
+	parts = parts[i:]
+
+	if len(parts) > maxParts {
+		parts = parts[:maxParts]
+		result.IsTruncated = true
+	}
+	if len(parts) > 0 {
+		result.NextPartNumberMarker = parts[len(parts)-1].PartNumber
+	}
+	result.Parts = parts
+
+	return result
+}
+
+// newObjectParts returns the parts of an object uploaded using multipart
+// upload in parts with partChecksums computed using algorithm, if any.
+func newObjectParts(parts []ObjectPart, algorithm string, partChecksums [][]byte) []ObjectPart {
+	for i := range parts {
+		if i < len(partChecksums) {
+			parts[i].set(algorithm, base64.StdEncoding.EncodeToString(partChecksums[i]))
+		}
+	}
+	return parts
+}
+
+// putObjectParts records parts of version of the object at key in the
+// metadata store, if there's one.
+func (layer *gatewayLayer) putObjectParts(ctx context.Context, bucketName, key string, version []byte, parts []ObjectPart) error {
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	value, err := xml.Marshal(ObjectAttributesParts{TotalPartsCount: len(parts), Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	return layer.metadataStore.put(storeObjectParts, storeBucket(ctx, bucketName), objectVersionKey(key, version), value)
+}
+
+// getObjectParts returns parts of version of the object at key recorded in
+// the metadata store, or nil if there are none.
+func (layer *gatewayLayer) getObjectParts(ctx context.Context, bucketName, key string, version []byte) ([]ObjectPart, error) {
+	if layer.metadataStore == nil {
+		return nil, nil
+	}
+
+	value, found, err := layer.metadataStore.get(storeObjectParts, storeBucket(ctx, bucketName), objectVersionKey(key, version))
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var parts ObjectAttributesParts
+	if err := xml.Unmarshal(value, &parts); err != nil {
+		return nil, err
+	}
+
+	return parts.Parts, nil
+}
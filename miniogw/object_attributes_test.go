@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	minio "storj.io/minio/cmd"
+)
+
+func TestMultipartETagPartCount(t *testing.T) {
+	for _, tt := range []struct {
+		etag  string
+		count int
+		ok    bool
+	}{
+		{etag: "d41d8cd98f00b204e9800998ecf8427e"},
+		{etag: ""},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-3", count: 3, ok: true},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-0"},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-x"},
+	} {
+		count, ok := multipartETagPartCount(tt.etag)
+		assert.Equal(t, tt.ok, ok, tt.etag)
+		assert.Equal(t, tt.count, count, tt.etag)
+	}
+}
+
+func TestPaginateObjectParts(t *testing.T) {
+	parts := []ObjectPart{{PartNumber: 1}, {PartNumber: 2}, {PartNumber: 4}}
+
+	result := paginateObjectParts(parts, 0, 2)
+	assert.Equal(t, &ObjectAttributesParts{
+		TotalPartsCount:      3,
+		MaxParts:             2,
+		NextPartNumberMarker: 2,
+		IsTruncated:          true,
+		Parts:                parts[:2],
+	}, result)
+
+	result = paginateObjectParts(parts, 2, 2)
+	assert.Equal(t, &ObjectAttributesParts{
+		TotalPartsCount:      3,
+		PartNumberMarker:     2,
+		MaxParts:             2,
+		NextPartNumberMarker: 4,
+		Parts:                parts[2:],
+	}, result)
+
+	result = paginateObjectParts(parts, 4, 2)
+	assert.False(t, result.IsTruncated)
+	assert.Empty(t, result.Parts)
+}
+
+func TestObjectParts(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer := &gatewayLayer{metadataStore: store}
+
+	parts, err := layer.getObjectParts(ctx, "bucket", "a", []byte{1})
+	require.NoError(t, err)
+	assert.Nil(t, parts)
+
+	parts = newObjectParts([]ObjectPart{{PartNumber: 1, Size: 10}, {PartNumber: 2, Size: 5}}, checksumCRC32, [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}})
+	assert.Equal(t, "AQIDBA==", parts[0].ChecksumCRC32)
+	assert.Equal(t, "BQYHCA==", parts[1].ChecksumCRC32)
+
+	require.NoError(t, layer.putObjectParts(ctx, "bucket", "a", []byte{1}, parts))
+
+	stored, err := layer.getObjectParts(ctx, "bucket", "a", []byte{1})
+	require.NoError(t, err)
+	assert.Equal(t, parts, stored)
+
+	layer.forgetObjectVersion(ctx, "bucket", "a", []byte{1})
+
+	stored, err = layer.getObjectParts(ctx, "bucket", "a", []byte{1})
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Without a metadata store nothing is recorded.
+	require.NoError(t, (&gatewayLayer{}).putObjectParts(ctx, "bucket", "a", []byte{1}, parts))
+}
+
+func TestObjectAttributesXML(t *testing.T) {
+	attributes := ObjectAttributes{
+		VersionID:    "01",
+		ETag:         "abc-2",
+		Checksum:     &ObjectChecksum{ChecksumSHA256: "sum-2"},
+		StorageClass: "STANDARD",
+		ObjectSize:   15,
+		ObjectParts: &ObjectAttributesParts{
+			TotalPartsCount: 1,
+			Parts:           []ObjectPart{{ObjectChecksum: ObjectChecksum{ChecksumSHA256: "sum"}, PartNumber: 1, Size: 15}},
+		},
+	}
+
+	body, err := xml.Marshal(attributes)
+	require.NoError(t, err)
+	assert.Equal(t, `<GetObjectAttributesResponse xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<ETag>abc-2</ETag>`+
+		`<Checksum><ChecksumSHA256>sum-2</ChecksumSHA256></Checksum>`+
+		`<ObjectParts><TotalPartsCount>1</TotalPartsCount><IsTruncated>false</IsTruncated>`+
+		`<Part><ChecksumSHA256>sum</ChecksumSHA256><PartNumber>1</PartNumber><Size>15</Size></Part></ObjectParts>`+
+		`<StorageClass>STANDARD</StorageClass>`+
+		`<ObjectSize>15</ObjectSize>`+
+		`</GetObjectAttributesResponse>`, string(body))
+}
+
+// objectAttributesTestLayer is an object layer returning the attributes of
+// the objects it has.
+type objectAttributesTestLayer struct {
+	minio.ObjectLayer
+
+	objects map[string]ObjectAttributes
+	opts    ObjectAttributesOptions
+}
+
+func (layer *objectAttributesTestLayer) GetObjectAttributes(ctx context.Context, bucket, object string, opts ObjectAttributesOptions) (ObjectAttributes, error) {
+	layer.opts = opts
+	attributes, ok := layer.objects[bucket+"/"+object]
+	if !ok {
+		return ObjectAttributes{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	return attributes, nil
+}
+
+func TestGetObjectAttributesAPI(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	layer := &objectAttributesTestLayer{objects: map[string]ObjectAttributes{
+		"bucket/dir/key": {
+			VersionID:    "01",
+			LastModified: modified,
+			ETag:         "abc-2",
+			Checksum:     &ObjectChecksum{ChecksumSHA256: "sum-2"},
+			StorageClass: "STANDARD",
+			ObjectParts:  &ObjectAttributesParts{TotalPartsCount: 2},
+		},
+	}}
+	// minio-go has no GetObjectAttributes, so requests are signed directly.
+	_, url := startAPITestServer(t, layer)
+
+	resp := signedRequest(t, http.MethodGet, url+"/bucket/dir/key?attributes&versionId=01", map[string]string{
+		"X-Amz-Object-Attributes":  "ETag, ObjectSize,StorageClass",
+		"X-Amz-Max-Parts":          "10",
+		"X-Amz-Part-Number-Marker": "1",
+	}, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, modified.Format(http.TimeFormat), resp.Header.Get("Last-Modified"))
+	assert.Equal(t, "01", resp.Header.Get("X-Amz-Version-Id"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	// Only the requested attributes are returned, including zero sizes.
+	assert.Contains(t, string(body), `<GetObjectAttributesResponse xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`+
+		`<ETag>abc-2</ETag><StorageClass>STANDARD</StorageClass><ObjectSize>0</ObjectSize>`+
+		`</GetObjectAttributesResponse>`)
+	assert.Equal(t, ObjectAttributesOptions{VersionID: "01", MaxParts: 10, PartNumberMarker: 1}, layer.opts)
+
+	resp = signedRequest(t, http.MethodGet, url+"/bucket/dir/key?attributes", map[string]string{
+		"X-Amz-Object-Attributes": "Checksum,ObjectParts",
+	}, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var response objectAttributesResponse
+	require.NoError(t, xml.NewDecoder(resp.Body).Decode(&response))
+	assert.Equal(t, &ObjectChecksum{ChecksumSHA256: "sum-2"}, response.Checksum)
+	assert.Equal(t, &ObjectAttributesParts{TotalPartsCount: 2}, response.ObjectParts)
+	assert.Empty(t, response.ETag)
+	assert.Nil(t, response.ObjectSize)
+
+	for _, header := range []map[string]string{
+		nil,
+		{"X-Amz-Object-Attributes": "ETag,Owner"},
+		{"X-Amz-Object-Attributes": "ETag", "X-Amz-Max-Parts": "many"},
+	} {
+		resp = signedRequest(t, http.MethodGet, url+"/bucket/dir/key?attributes", header, "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, header)
+	}
+
+	resp = signedRequest(t, http.MethodGet, url+"/bucket/missing?attributes", map[string]string{
+		"X-Amz-Object-Attributes": "ETag",
+	}, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// The API needs a valid signature.
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url+"/bucket/dir/key?attributes", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Amz-Object-Attributes", "ETag")
+	resp, err = http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -0,0 +1,440 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"storj.io/common/storj"
+	minio "storj.io/minio/cmd"
+	objectlock "storj.io/minio/pkg/bucket/object/lock"
+	"storj.io/uplink"
+	"storj.io/uplink/private/bucket"
+	"storj.io/uplink/private/metaclient"
+	versioned "storj.io/uplink/private/object"
+)
+
+// ObjectLockLayer is implemented by object layers that support Object Lock.
+type ObjectLockLayer interface {
+	GetObjectLockConfig(ctx context.Context, bucket string) (*objectlock.Config, error)
+	SetObjectLockConfig(ctx context.Context, bucket string, config *objectlock.Config) error
+	GetObjectRetention(ctx context.Context, bucket, object, versionID string) (*objectlock.ObjectRetention, error)
+	SetObjectRetention(ctx context.Context, bucket, object, versionID string, retention *objectlock.ObjectRetention) error
+	GetObjectLegalHold(ctx context.Context, bucket, object, versionID string) (*objectlock.ObjectLegalHold, error)
+	SetObjectLegalHold(ctx context.Context, bucket, object, versionID string, legalHold *objectlock.ObjectLegalHold) error
+}
+
+var (
+	_ ObjectLockLayer = (*gatewayLayer)(nil)
+	_ ObjectLockLayer = (*singleTenancyLayer)(nil)
+)
+
+// ObjectLockHeadersHandler is a middleware rejecting uploads and copies
+// that request Object Lock using headers. minio can't pass them down, as it
+// verifies them against its own Object Lock configuration, which gateways
+// don't have.
+func ObjectLockHeadersHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["object"] != "" && (r.Method == http.MethodPut || r.Method == http.MethodPost) && objectlock.IsObjectLockRequested(r.Header) {
+			ctx := r.Context()
+			minio.WriteErrorResponse(ctx, w, minio.ToAPIError(ctx, ErrObjectLockHeadersNotSupported), r.URL, false)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// storeDefaultRetentionKey is the metadata store key of the default
+// retention rule of a bucket.
+var storeDefaultRetentionKey = []byte("default-retention")
+
+// objectLock is the Object Lock state of an object version. The satellite
+// keeps it and only supports retention in compliance mode, so retention in
+// governance mode and legal holds are rejected.
+type objectLock struct {
+	retention objectlock.ObjectRetention
+}
+
+// protects returns whether the lock keeps the version from being deleted at
+// now.
+func (lock objectLock) protects(now time.Time) bool {
+	return lock.retention.Mode == objectlock.RetCompliance && lock.retention.RetainUntilDate.After(now)
+}
+
+// allowsRetention returns whether retention may replace the retention of the
+// version at now. Retention in force can only be extended.
+func (lock objectLock) allowsRetention(retention objectlock.ObjectRetention, now time.Time) bool {
+	if !lock.protects(now) {
+		return true
+	}
+	return retention.Mode == objectlock.RetCompliance && !retention.RetainUntilDate.Before(lock.retention.RetainUntilDate.Time)
+}
+
+// bucketObjectLockEnabled returns whether Object Lock is enabled for bucket.
+func bucketObjectLockEnabled(ctx context.Context, project *uplink.Project, bucketName string) (_ bool, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	enabled, err := bucket.GetBucketObjectLockConfiguration(ctx, project, bucketName)
+	if errors.Is(err, bucket.ErrBucketObjectLockConfigurationNotFound) {
+		return false, nil
+	}
+	return enabled, err
+}
+
+// createBucketWithObjectLock creates bucket with Object Lock enabled.
+func createBucketWithObjectLock(ctx context.Context, project *uplink.Project, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, err = bucket.CreateBucketWithObjectLock(ctx, project, bucket.CreateBucketWithObjectLockParams{
+		Name:              bucketName,
+		ObjectLockEnabled: true,
+	})
+	return err
+}
+
+// GetObjectLockConfig returns the Object Lock configuration of bucket.
+func (layer *gatewayLayer) GetObjectLockConfig(ctx context.Context, bucketName string) (_ *objectlock.Config, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return nil, minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := bucketObjectLockEnabled(ctx, project, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if !enabled {
+		return nil, minio.BucketObjectLockConfigNotFound{Bucket: bucketName}
+	}
+
+	config := objectlock.NewObjectLockConfig()
+
+	rule, err := layer.defaultRetention(ctx, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if rule != nil {
+		config.Rule = &struct {
+			DefaultRetention objectlock.DefaultRetention `xml:"DefaultRetention"`
+		}{DefaultRetention: *rule}
+	}
+
+	return config, nil
+}
+
+// SetObjectLockConfig sets the default retention rule of bucket, which can
+// only be in compliance mode. Object Lock can only be enabled when the bucket
+// is created.
+func (layer *gatewayLayer) SetObjectLockConfig(ctx context.Context, bucketName string, config *objectlock.Config) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if config.Rule != nil && config.Rule.DefaultRetention.Mode != objectlock.RetCompliance {
+		return ErrObjectLockModeNotSupported
+	}
+
+	project, err := projectFromContext(ctx, bucketName, "")
+	if err != nil {
+		return err
+	}
+
+	enabled, err := bucketObjectLockEnabled(ctx, project, bucketName)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+	if !enabled {
+		return minio.NotImplemented{Message: "Enabling Object Lock on existing buckets"}
+	}
+
+	if config.Rule == nil {
+		if layer.metadataStore == nil {
+			return nil
+		}
+		return ConvertError(layer.metadataStore.delete(storeObjectLock, storeBucket(ctx, bucketName), storeDefaultRetentionKey), bucketName, "")
+	}
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "Default retention requires the metadata store"}
+	}
+
+	value, err := xml.Marshal(config.Rule.DefaultRetention)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	return ConvertError(layer.metadataStore.put(storeObjectLock, storeBucket(ctx, bucketName), storeDefaultRetentionKey, value), bucketName, "")
+}
+
+// defaultRetention returns the default retention rule of bucket or nil if it
+// has none.
+func (layer *gatewayLayer) defaultRetention(ctx context.Context, bucketName string) (*objectlock.DefaultRetention, error) {
+	if layer.metadataStore == nil {
+		return nil, nil
+	}
+
+	value, found, err := layer.metadataStore.get(storeObjectLock, storeBucket(ctx, bucketName), storeDefaultRetentionKey)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var rule objectlock.DefaultRetention
+	if err := xml.Unmarshal(value, &rule); err != nil {
+		return nil, metadataStoreError.Wrap(err)
+	}
+
+	return &rule, nil
+}
+
+// retentionFromRule returns the retention that rule gives to objects created
+// at now.
+func retentionFromRule(rule objectlock.DefaultRetention, now time.Time) objectlock.ObjectRetention {
+	retainUntil := now
+	if rule.Days != nil {
+		retainUntil = retainUntil.AddDate(0, 0, int(*rule.Days))
+	} else if rule.Years != nil {
+		retainUntil = retainUntil.AddDate(int(*rule.Years), 0, 0)
+	}
+
+	return objectlock.ObjectRetention{
+		Mode:            rule.Mode,
+		RetainUntilDate: objectlock.RetentionDate{Time: retainUntil.UTC()},
+	}
+}
+
+// newObjectRetention returns the retention that new objects in bucket get by
+// default or nil if the bucket has no default retention rule in compliance
+// mode.
+func (layer *gatewayLayer) newObjectRetention(ctx context.Context, bucketName string) (*objectlock.ObjectRetention, error) {
+	rule, err := layer.defaultRetention(ctx, bucketName)
+	if err != nil || rule == nil || rule.Mode != objectlock.RetCompliance {
+		return nil, err
+	}
+
+	retention := retentionFromRule(*rule, time.Now())
+	return &retention, nil
+}
+
+// satelliteRetention returns retention as kept by the satellite, which only
+// supports retention in compliance mode. Callers pass it with uploads and
+// copies.
+func satelliteRetention(retention *objectlock.ObjectRetention) metaclient.Retention {
+	if retention == nil || retention.Mode != objectlock.RetCompliance {
+		return metaclient.Retention{}
+	}
+	return metaclient.Retention{
+		Mode:        storj.ComplianceMode,
+		RetainUntil: retention.RetainUntilDate.Time,
+	}
+}
+
+// getObjectLock returns the Object Lock state of object.
+func getObjectLock(object *versioned.VersionedObject) (lock objectLock) {
+	if object.Retention != nil && object.Retention.Mode == storj.ComplianceMode {
+		lock.retention = objectlock.ObjectRetention{
+			Mode:            objectlock.RetCompliance,
+			RetainUntilDate: objectlock.RetentionDate{Time: object.Retention.RetainUntil},
+		}
+	}
+	return lock
+}
+
+// statLockedObject returns version of the object at objectPath (or its latest
+// version if version is nil) in a bucket with Object Lock enabled.
+func statLockedObject(ctx context.Context, project *uplink.Project, bucketName, objectPath string, version []byte) (_ *versioned.VersionedObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	enabled, err := bucketObjectLockEnabled(ctx, project, bucketName)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, objectPath)
+	}
+	if !enabled {
+		return nil, minio.BucketObjectLockConfigNotFound{Bucket: bucketName}
+	}
+
+	object, err := versioned.StatObject(ctx, project, bucketName, objectPath, version)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, objectPath)
+	}
+	if object.IsDeleteMarker {
+		return nil, minio.MethodNotAllowed{Bucket: bucketName, Object: objectPath}
+	}
+
+	return object, nil
+}
+
+// GetObjectRetention returns the retention of the object version.
+func (layer *gatewayLayer) GetObjectRetention(ctx context.Context, bucketName, objectPath, versionID string) (_ *objectlock.ObjectRetention, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return nil, minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, objectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := decodeVersionID(versionID)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, objectPath)
+	}
+
+	object, err := statLockedObject(ctx, project, bucketName, objectPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := getObjectLock(object)
+	if !lock.retention.Mode.Valid() {
+		return nil, ErrNoSuchObjectLockConfiguration
+	}
+
+	return &lock.retention, nil
+}
+
+// SetObjectRetention sets the retention of the object version, which can
+// only be in compliance mode. Retention in force can only be extended.
+func (layer *gatewayLayer) SetObjectRetention(ctx context.Context, bucketName, objectPath, versionID string, retention *objectlock.ObjectRetention) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	if retention.Mode.Valid() && retention.Mode != objectlock.RetCompliance {
+		return ErrObjectLockModeNotSupported
+	}
+
+	project, err := projectFromContext(ctx, bucketName, objectPath)
+	if err != nil {
+		return err
+	}
+
+	version, err := decodeVersionID(versionID)
+	if err != nil {
+		return ConvertError(err, bucketName, objectPath)
+	}
+
+	now := time.Now()
+	if retention.Mode.Valid() && !retention.RetainUntilDate.After(now) {
+		return objectlock.ErrPastObjectLockRetainDate
+	}
+
+	object, err := statLockedObject(ctx, project, bucketName, objectPath, version)
+	if err != nil {
+		return err
+	}
+
+	if !getObjectLock(object).allowsRetention(*retention, now) {
+		return ErrObjectLocked
+	}
+
+	err = versioned.SetObjectRetention(ctx, project, bucketName, objectPath, object.Version, satelliteRetention(retention))
+	return ConvertError(err, bucketName, objectPath)
+}
+
+// GetObjectLegalHold returns the legal hold status of the object version,
+// which is always off, as legal holds aren't supported.
+func (layer *gatewayLayer) GetObjectLegalHold(ctx context.Context, bucketName, objectPath, versionID string) (_ *objectlock.ObjectLegalHold, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkLegalHoldRequest(ctx, bucketName, objectPath, versionID); err != nil {
+		return nil, err
+	}
+
+	return &objectlock.ObjectLegalHold{Status: objectlock.LegalHoldOff}, nil
+}
+
+// SetObjectLegalHold removes the legal hold of the object version. Placing
+// legal holds isn't supported, as the satellite can't enforce them.
+func (layer *gatewayLayer) SetObjectLegalHold(ctx context.Context, bucketName, objectPath, versionID string, legalHold *objectlock.ObjectLegalHold) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if legalHold.Status == objectlock.LegalHoldOn {
+		return ErrLegalHoldNotSupported
+	}
+
+	return layer.checkLegalHoldRequest(ctx, bucketName, objectPath, versionID)
+}
+
+// checkLegalHoldRequest returns an error if a request for the legal hold of
+// the object version can't proceed, e.g., if the version doesn't exist.
+func (layer *gatewayLayer) checkLegalHoldRequest(ctx context.Context, bucketName, objectPath, versionID string) error {
+	if err := ValidateBucket(ctx, bucketName); err != nil {
+		return minio.BucketNameInvalid{Bucket: bucketName}
+	}
+
+	project, err := projectFromContext(ctx, bucketName, objectPath)
+	if err != nil {
+		return err
+	}
+
+	version, err := decodeVersionID(versionID)
+	if err != nil {
+		return ConvertError(err, bucketName, objectPath)
+	}
+
+	_, err = statLockedObject(ctx, project, bucketName, objectPath, version)
+	return err
+}
+
+// checkObjectLock returns ErrObjectLocked if version of the object at
+// objectPath is protected from deletion. Deleting without a version in a
+// bucket with Object Lock enabled (which is always versioned) only creates a
+// delete marker, so it's always allowed.
+func (layer *gatewayLayer) checkObjectLock(ctx context.Context, project *uplink.Project, bucketName, objectPath string, version []byte) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if version == nil {
+		return nil
+	}
+
+	object, err := versioned.StatObject(ctx, project, bucketName, objectPath, version)
+	if err != nil {
+		if errors.Is(err, uplink.ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	if object.IsDeleteMarker {
+		return nil
+	}
+
+	if getObjectLock(object).protects(time.Now()) {
+		return ErrObjectLocked
+	}
+
+	return nil
+}
+
+// forgetObjectVersion removes everything kept in the metadata store about
+// the deleted version of the object at key.
+func (layer *gatewayLayer) forgetObjectVersion(ctx context.Context, bucketName, key string, version []byte) {
+	if layer.metadataStore == nil {
+		return
+	}
+
+	storeKey := objectVersionKey(key, version)
+	for _, kind := range [][]byte{storeObjectTags, storeObjectParts} {
+		if err := layer.metadataStore.delete(kind, storeBucket(ctx, bucketName), storeKey); err != nil {
+			layer.logger.Infof("metadata store: removing %s of %q in %q failed: %v", kind, key, bucketName, err)
+		}
+	}
+}
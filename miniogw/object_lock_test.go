@@ -0,0 +1,242 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/storj"
+	minio "storj.io/minio/cmd"
+	objectlock "storj.io/minio/pkg/bucket/object/lock"
+	"storj.io/uplink"
+	"storj.io/uplink/private/metaclient"
+	versioned "storj.io/uplink/private/object"
+)
+
+func TestObjectLockProtects(t *testing.T) {
+	now := time.Now()
+
+	retention := func(mode objectlock.RetMode, until time.Time) objectlock.ObjectRetention {
+		return objectlock.ObjectRetention{Mode: mode, RetainUntilDate: objectlock.RetentionDate{Time: until}}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		lock     objectLock
+		protects bool
+	}{
+		{name: "none", lock: objectLock{}},
+		{name: "compliance", lock: objectLock{retention: retention(objectlock.RetCompliance, now.Add(time.Hour))}, protects: true},
+		{name: "expired compliance", lock: objectLock{retention: retention(objectlock.RetCompliance, now.Add(-time.Hour))}},
+	} {
+		assert.Equal(t, tt.protects, tt.lock.protects(now), tt.name)
+	}
+}
+
+func TestObjectLockAllowsRetention(t *testing.T) {
+	now := time.Now()
+
+	retention := func(mode objectlock.RetMode, until time.Time) objectlock.ObjectRetention {
+		return objectlock.ObjectRetention{Mode: mode, RetainUntilDate: objectlock.RetentionDate{Time: until}}
+	}
+
+	compliance := objectLock{retention: retention(objectlock.RetCompliance, now.Add(time.Hour))}
+	expired := objectLock{retention: retention(objectlock.RetCompliance, now.Add(-time.Hour))}
+
+	longer := now.Add(2 * time.Hour)
+	shorter := now.Add(time.Minute)
+
+	assert.True(t, objectLock{}.allowsRetention(retention(objectlock.RetCompliance, shorter), now))
+	assert.True(t, expired.allowsRetention(objectlock.ObjectRetention{}, now))
+
+	assert.True(t, compliance.allowsRetention(retention(objectlock.RetCompliance, longer), now))
+	assert.False(t, compliance.allowsRetention(retention(objectlock.RetCompliance, shorter), now))
+	assert.False(t, compliance.allowsRetention(retention(objectlock.RetGovernance, longer), now))
+	assert.False(t, compliance.allowsRetention(objectlock.ObjectRetention{}, now))
+}
+
+func TestRetentionFromRule(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	days, years := uint64(30), uint64(7)
+
+	retention := retentionFromRule(objectlock.DefaultRetention{Mode: objectlock.RetGovernance, Days: &days}, now)
+	assert.Equal(t, objectlock.RetGovernance, retention.Mode)
+	assert.Equal(t, now.AddDate(0, 0, 30), retention.RetainUntilDate.Time)
+	assert.Equal(t, metaclient.Retention{}, satelliteRetention(&retention))
+
+	retention = retentionFromRule(objectlock.DefaultRetention{Mode: objectlock.RetCompliance, Years: &years}, now)
+	assert.Equal(t, now.AddDate(7, 0, 0), retention.RetainUntilDate.Time)
+	assert.Equal(t, metaclient.Retention{
+		Mode:        storj.ComplianceMode,
+		RetainUntil: now.AddDate(7, 0, 0),
+	}, satelliteRetention(&retention))
+
+	assert.Equal(t, metaclient.Retention{}, satelliteRetention(nil))
+}
+
+func TestGetObjectLock(t *testing.T) {
+	retainUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	object := &versioned.VersionedObject{Object: uplink.Object{Key: "a"}, Version: []byte{1}}
+	assert.Equal(t, objectLock{}, getObjectLock(object))
+
+	object.Retention = &metaclient.Retention{Mode: storj.ComplianceMode, RetainUntil: retainUntil}
+	lock := getObjectLock(object)
+	assert.Equal(t, objectlock.RetCompliance, lock.retention.Mode)
+	assert.True(t, retainUntil.Equal(lock.retention.RetainUntilDate.Time))
+}
+
+func TestObjectLockNotSupported(t *testing.T) {
+	ctx := context.Background()
+	layer := &gatewayLayer{}
+
+	days := uint64(1)
+	config := objectlock.NewObjectLockConfig()
+	config.Rule = &struct {
+		DefaultRetention objectlock.DefaultRetention `xml:"DefaultRetention"`
+	}{DefaultRetention: objectlock.DefaultRetention{Mode: objectlock.RetGovernance, Days: &days}}
+
+	// Only what the satellite enforces is accepted.
+	assert.ErrorIs(t, layer.SetObjectLockConfig(ctx, "bucket", config), ErrObjectLockModeNotSupported)
+	governance := &objectlock.ObjectRetention{
+		Mode:            objectlock.RetGovernance,
+		RetainUntilDate: objectlock.RetentionDate{Time: time.Now().Add(time.Hour)},
+	}
+	assert.ErrorIs(t, layer.SetObjectRetention(ctx, "bucket", "a", "", governance), ErrObjectLockModeNotSupported)
+	assert.ErrorIs(t, layer.SetObjectLegalHold(ctx, "bucket", "a", "", &objectlock.ObjectLegalHold{Status: objectlock.LegalHoldOn}), ErrLegalHoldNotSupported)
+
+	store, err := openMetadataStore(filepath.Join(t.TempDir(), "metadata.db"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, store.Close()) }()
+
+	layer.metadataStore = store
+	// Default retention rules that aren't in compliance mode aren't applied.
+	value, err := xml.Marshal(config.Rule.DefaultRetention)
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeObjectLock, projectBucket{name: "bucket"}, storeDefaultRetentionKey, value))
+	retention, err := layer.newObjectRetention(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Nil(t, retention)
+}
+
+// objectLockTestLayer is an object layer keeping Object Lock state in
+// memory. Like gatewayLayer, it rejects what the satellite can't enforce.
+type objectLockTestLayer struct {
+	minio.ObjectLayer
+
+	config     *objectlock.Config
+	retentions map[string]*objectlock.ObjectRetention
+}
+
+func (layer *objectLockTestLayer) GetObjectLockConfig(ctx context.Context, bucket string) (*objectlock.Config, error) {
+	if layer.config == nil {
+		return nil, minio.BucketObjectLockConfigNotFound{Bucket: bucket}
+	}
+	return layer.config, nil
+}
+
+func (layer *objectLockTestLayer) SetObjectLockConfig(ctx context.Context, bucket string, config *objectlock.Config) error {
+	if config.Rule != nil && config.Rule.DefaultRetention.Mode != objectlock.RetCompliance {
+		return ErrObjectLockModeNotSupported
+	}
+	layer.config = config
+	return nil
+}
+
+func (layer *objectLockTestLayer) GetObjectRetention(ctx context.Context, bucket, object, versionID string) (*objectlock.ObjectRetention, error) {
+	retention, ok := layer.retentions[bucket+"/"+object+"@"+versionID]
+	if !ok {
+		return nil, ErrNoSuchObjectLockConfiguration
+	}
+	return retention, nil
+}
+
+func (layer *objectLockTestLayer) SetObjectRetention(ctx context.Context, bucket, object, versionID string, retention *objectlock.ObjectRetention) error {
+	if retention.Mode != objectlock.RetCompliance {
+		return ErrObjectLockModeNotSupported
+	}
+	layer.retentions[bucket+"/"+object+"@"+versionID] = retention
+	return nil
+}
+
+func (layer *objectLockTestLayer) GetObjectLegalHold(ctx context.Context, bucket, object, versionID string) (*objectlock.ObjectLegalHold, error) {
+	return &objectlock.ObjectLegalHold{Status: objectlock.LegalHoldOff}, nil
+}
+
+func (layer *objectLockTestLayer) SetObjectLegalHold(ctx context.Context, bucket, object, versionID string, legalHold *objectlock.ObjectLegalHold) error {
+	if legalHold.Status == objectlock.LegalHoldOn {
+		return ErrLegalHoldNotSupported
+	}
+	return nil
+}
+
+func TestObjectLockAPI(t *testing.T) {
+	layer := &objectLockTestLayer{retentions: make(map[string]*objectlock.ObjectRetention)}
+	client, _ := startAPITestServer(t, layer)
+
+	ctx := context.Background()
+
+	_, _, _, _, err := client.GetObjectLockConfig(ctx, "bucket")
+	assert.Equal(t, "ObjectLockConfigurationNotFoundError", miniogo.ToErrorResponse(err).Code)
+
+	compliance, governance := miniogo.Compliance, miniogo.Governance
+	validity, unit := uint(30), miniogo.Days
+	require.NoError(t, client.SetObjectLockConfig(ctx, "bucket", &compliance, &validity, &unit))
+
+	enabled, mode, gotValidity, gotUnit, err := client.GetObjectLockConfig(ctx, "bucket")
+	require.NoError(t, err)
+	assert.Equal(t, "Enabled", enabled)
+	assert.Equal(t, compliance, *mode)
+	assert.Equal(t, validity, *gotValidity)
+	assert.Equal(t, unit, *gotUnit)
+
+	err = client.SetObjectLockConfig(ctx, "bucket", &governance, &validity, &unit)
+	assert.Equal(t, "NotImplemented", miniogo.ToErrorResponse(err).Code)
+
+	retainUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, client.PutObjectRetention(ctx, "bucket", "dir/key", miniogo.PutObjectRetentionOptions{
+		Mode:            &compliance,
+		RetainUntilDate: &retainUntil,
+		VersionID:       "01",
+	}))
+
+	mode, gotRetainUntil, err := client.GetObjectRetention(ctx, "bucket", "dir/key", "01")
+	require.NoError(t, err)
+	assert.Equal(t, compliance, *mode)
+	assert.True(t, retainUntil.Equal(*gotRetainUntil))
+
+	_, _, err = client.GetObjectRetention(ctx, "bucket", "dir/key", "")
+	assert.Equal(t, "NoSuchObjectLockConfiguration", miniogo.ToErrorResponse(err).Code)
+
+	err = client.PutObjectRetention(ctx, "bucket", "dir/key", miniogo.PutObjectRetentionOptions{
+		Mode:            &governance,
+		RetainUntilDate: &retainUntil,
+	})
+	assert.Equal(t, "NotImplemented", miniogo.ToErrorResponse(err).Code)
+
+	status, err := client.GetObjectLegalHold(ctx, "bucket", "dir/key", miniogo.GetObjectLegalHoldOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, miniogo.LegalHoldDisabled, *status)
+
+	on := miniogo.LegalHoldEnabled
+	err = client.PutObjectLegalHold(ctx, "bucket", "dir/key", miniogo.PutObjectLegalHoldOptions{Status: &on})
+	assert.Equal(t, "NotImplemented", miniogo.ToErrorResponse(err).Code)
+
+	// Uploads requesting Object Lock using headers are rejected before they
+	// reach minio.
+	_, err = client.PutObject(ctx, "bucket", "dir/key", strings.NewReader("data"), 4, miniogo.PutObjectOptions{
+		Mode:            compliance,
+		RetainUntilDate: retainUntil,
+	})
+	assert.Equal(t, ErrObjectLockHeadersNotSupported.Message, miniogo.ToErrorResponse(err).Message)
+}
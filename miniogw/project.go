@@ -5,8 +5,12 @@ package miniogw
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 
 	"storj.io/uplink"
+	privateAccess "storj.io/uplink/private/access"
 )
 
 type uplinkProjectKey struct{}
@@ -23,3 +27,71 @@ func GetUplinkProject(ctx context.Context) (*uplink.Project, bool) {
 	project, ok := ctx.Value(uplinkProjectKey{}).(*uplink.Project)
 	return project, ok
 }
+
+type projectIDKey struct{}
+
+// WithProjectID injects the ID of the project injected by WithUplinkProject
+// into ctx. The gateway keeps local state about buckets, like the listing
+// index and cached listings, separately for each project ID, and doesn't use
+// it for requests without one, as bucket names are only unique within a
+// project.
+func WithProjectID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, projectIDKey{}, id)
+}
+
+// GetProjectID retrieves the ID of the project injected by WithProjectID from
+// ctx and reports whether it was successful.
+func GetProjectID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(projectIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// withProjectOf injects the project and its ID in from into ctx, so that work
+// started by a request can outlive it.
+func withProjectOf(ctx, from context.Context) context.Context {
+	if project, ok := GetUplinkProject(from); ok {
+		ctx = WithUplinkProject(ctx, project)
+	}
+	if id, ok := GetProjectID(from); ok {
+		ctx = WithProjectID(ctx, id)
+	}
+	return ctx
+}
+
+// accessProjectID returns the ID of the project of access. Keys derived from
+// the same root API key of the same satellite have the same ID.
+func accessProjectID(access *uplink.Access) string {
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(access.SatelliteAddress()))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(privateAccess.APIKey(access).Head())
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// projectBucket identifies a bucket of a project.
+type projectBucket struct {
+	project string
+	name    string
+}
+
+// projectBucketOf returns bucket of the project in ctx and reports whether ctx
+// identifies the project.
+func projectBucketOf(ctx context.Context, bucket string) (projectBucket, bool) {
+	project, ok := GetProjectID(ctx)
+	if !ok {
+		return projectBucket{}, false
+	}
+	return projectBucket{project: project, name: bucket}, true
+}
+
+// key returns bucket as bytes. Bucket names can't contain slashes, so keys of
+// different projects never collide.
+func (bucket projectBucket) key() []byte {
+	return []byte(bucket.project + "/" + bucket.name)
+}
+
+// parseProjectBucket returns the bucket with key.
+func parseProjectBucket(key []byte) projectBucket {
+	project, name, _ := strings.Cut(string(key), "/")
+	return projectBucket{project: project, name: name}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"sort"
+	"strings"
+
+	"storj.io/minio/pkg/bucket/policy"
+	"storj.io/minio/pkg/bucket/policy/condition"
+)
+
+// publicAccess is the parsed form of PublicAccessConfig.
+type publicAccess struct {
+	// prefixes maps public buckets to their public prefixes. An empty
+	// prefix makes the whole bucket public.
+	prefixes map[string][]string
+	listing  bool
+}
+
+func newPublicAccess(config PublicAccessConfig) publicAccess {
+	access := publicAccess{
+		prefixes: make(map[string][]string),
+		listing:  config.Listing,
+	}
+
+	for _, entry := range config.Buckets {
+		bucket, prefix, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		if bucket == "" {
+			continue
+		}
+		access.prefixes[bucket] = append(access.prefixes[bucket], prefix)
+	}
+
+	for bucket, prefixes := range access.prefixes {
+		sort.Strings(prefixes)
+		// The whole bucket is public, which makes the other prefixes moot.
+		if prefixes[0] == "" {
+			access.prefixes[bucket] = prefixes[:1]
+		}
+	}
+
+	return access
+}
+
+// policy returns the policy allowing anyone to read objects from the public
+// prefixes of bucket and, if listing is allowed, to list them. It returns nil
+// if bucket isn't public.
+func (access publicAccess) policy(bucket string) (*policy.Policy, error) {
+	prefixes, ok := access.prefixes[bucket]
+	if !ok {
+		return nil, nil
+	}
+
+	objects := policy.NewResourceSet()
+	for _, prefix := range prefixes {
+		objects.Add(policy.NewResource(bucket, prefix+"*"))
+	}
+
+	statements := []policy.Statement{
+		policy.NewStatement(
+			policy.Allow,
+			policy.NewPrincipal("*"),
+			policy.NewActionSet(policy.GetObjectAction),
+			objects,
+			condition.NewFunctions(),
+		),
+	}
+
+	if access.listing {
+		conditions := condition.NewFunctions()
+		if prefixes[0] != "" {
+			patterns := make([]string, 0, len(prefixes))
+			for _, prefix := range prefixes {
+				patterns = append(patterns, prefix+"*")
+			}
+
+			prefixLike, err := condition.NewStringLikeFunc(condition.S3Prefix, patterns...)
+			if err != nil {
+				return nil, err
+			}
+			conditions = condition.NewFunctions(prefixLike)
+		}
+
+		statements = append(statements,
+			policy.NewStatement(
+				policy.Allow,
+				policy.NewPrincipal("*"),
+				policy.NewActionSet(policy.GetBucketLocationAction),
+				policy.NewResourceSet(policy.NewResource(bucket, "")),
+				condition.NewFunctions(),
+			),
+			policy.NewStatement(
+				policy.Allow,
+				policy.NewPrincipal("*"),
+				policy.NewActionSet(policy.ListBucketAction),
+				policy.NewResourceSet(policy.NewResource(bucket, "")),
+				conditions,
+			),
+		)
+	}
+
+	return &policy.Policy{
+		Version:    policy.DefaultVersion,
+		Statements: statements,
+	}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+func TestPublicAccess(t *testing.T) {
+	access := newPublicAccess(PublicAccessConfig{
+		Buckets: []string{"marketing", "docs/public/", "docs/shared/", "assets/img/", "assets"},
+	})
+
+	allowed := func(p *policy.Policy, action policy.Action, bucket, object, prefix string) bool {
+		args := policy.Args{Action: action, BucketName: bucket, ObjectName: object}
+		if prefix != "" {
+			args.ConditionValues = map[string][]string{"prefix": {prefix}}
+		}
+		return p.IsAllowed(args)
+	}
+
+	private, err := access.policy("private")
+	require.NoError(t, err)
+	assert.Nil(t, private)
+
+	marketing, err := access.policy("marketing")
+	require.NoError(t, err)
+	assert.True(t, allowed(marketing, policy.GetObjectAction, "marketing", "index.html", ""))
+	assert.False(t, allowed(marketing, policy.ListBucketAction, "marketing", "", ""))
+	assert.False(t, allowed(marketing, policy.PutObjectAction, "marketing", "index.html", ""))
+
+	docs, err := access.policy("docs")
+	require.NoError(t, err)
+	assert.True(t, allowed(docs, policy.GetObjectAction, "docs", "public/a.pdf", ""))
+	assert.True(t, allowed(docs, policy.GetObjectAction, "docs", "shared/b.pdf", ""))
+	assert.False(t, allowed(docs, policy.GetObjectAction, "docs", "internal/c.pdf", ""))
+
+	// The whole bucket is public if any entry names it without a prefix.
+	assets, err := access.policy("assets")
+	require.NoError(t, err)
+	assert.True(t, allowed(assets, policy.GetObjectAction, "assets", "css/site.css", ""))
+
+	access = newPublicAccess(PublicAccessConfig{Buckets: []string{"marketing", "docs/public/"}, Listing: true})
+
+	marketing, err = access.policy("marketing")
+	require.NoError(t, err)
+	assert.True(t, allowed(marketing, policy.ListBucketAction, "marketing", "", ""))
+	assert.True(t, allowed(marketing, policy.GetBucketLocationAction, "marketing", "", ""))
+
+	docs, err = access.policy("docs")
+	require.NoError(t, err)
+	assert.True(t, allowed(docs, policy.ListBucketAction, "docs", "", "public/2026/"))
+	assert.False(t, allowed(docs, policy.ListBucketAction, "docs", "", "internal/"))
+	assert.False(t, allowed(docs, policy.ListBucketAction, "docs", "", ""))
+}
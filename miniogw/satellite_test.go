@@ -0,0 +1,789 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/common/grant"
+	"storj.io/common/identity/testidentity"
+	"storj.io/common/macaroon"
+	"storj.io/common/pb"
+	"storj.io/common/peertls/tlsopts"
+	"storj.io/common/rpc/rpcstatus"
+	"storj.io/common/storj"
+	"storj.io/drpc/drpcmigrate"
+	"storj.io/drpc/drpcmux"
+	"storj.io/drpc/drpcserver"
+	"storj.io/minio/pkg/auth"
+	"storj.io/uplink"
+)
+
+// testSatellite is a satellite keeping everything in memory, so that tests
+// can use the object layer with a real project. It supports what uplink
+// needs for objects with inline segments only, i.e., objects smaller than
+// 4 KiB per segment, and all of its buckets are versioned.
+type testSatellite struct {
+	pb.DRPCMetainfoUnimplementedServer
+
+	mu       sync.Mutex
+	nextID   int64
+	buckets  map[string]time.Time
+	objects  map[string][]*testObject // versions by bucket and encrypted key, oldest first
+	uploads  map[string]*testObject   // pending objects by stream ID
+	copies   map[string]*testObject   // sources of copies by stream ID
+	requests map[string]int
+	ranges   []*pb.Range // of downloads
+}
+
+// testObject is an object, a delete marker or a pending object.
+type testObject struct {
+	bucket     []byte
+	key        []byte
+	version    []byte
+	streamID   storj.StreamID
+	status     pb.Object_Status
+	created    time.Time
+	expires    time.Time
+	retention  *pb.Retention
+	encryption *pb.EncryptionParameters
+
+	metadataNonce storj.Nonce
+	metadata      []byte
+	metadataKey   []byte
+
+	segments []testSegment
+}
+
+// testSegment is an inline segment of a testObject.
+type testSegment struct {
+	position  pb.SegmentPosition
+	keyNonce  storj.Nonce
+	key       []byte
+	data      []byte
+	plainSize int64
+	etag      []byte
+}
+
+// newTestProject starts a testSatellite with buckets and returns it with a
+// project of it.
+func newTestProject(t *testing.T, buckets ...string) (*testSatellite, *uplink.Project) {
+	satellite := &testSatellite{
+		buckets:  map[string]time.Time{},
+		objects:  map[string][]*testObject{},
+		uploads:  map[string]*testObject{},
+		copies:   map[string]*testObject{},
+		requests: map[string]int{},
+	}
+	for _, bucket := range buckets {
+		satellite.buckets[bucket] = time.Now()
+	}
+
+	identity := testidentity.MustPregeneratedSignedIdentity(0, storj.LatestIDVersion())
+	tlsOptions, err := tlsopts.NewOptions(identity, tlsopts.Config{PeerIDVersions: "latest"}, nil)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	mux := drpcmux.New()
+	require.NoError(t, pb.DRPCRegisterMetainfo(mux, satellite))
+
+	// uplink sends a header announcing DRPC before the TLS handshake.
+	listenMux := drpcmigrate.NewListenMux(listener, len(drpcmigrate.DRPCHeader))
+	drpcListener := tls.NewListener(listenMux.Route(drpcmigrate.DRPCHeader), tlsOptions.ServerTLSConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_ = listenMux.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		_ = drpcserver.New(mux).Serve(ctx, drpcListener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	apiKey, err := macaroon.NewAPIKey([]byte("secret"))
+	require.NoError(t, err)
+
+	encryptionKey := storj.Key{1}
+	serialized, err := (&grant.Access{
+		SatelliteAddress: storj.NodeURL{ID: identity.ID, Address: listener.Addr().String()}.String(),
+		APIKey:           apiKey,
+		EncAccess:        grant.NewEncryptionAccessWithDefaultKey(&encryptionKey),
+	}).Serialize()
+	require.NoError(t, err)
+
+	access, err := uplink.ParseAccess(serialized)
+	require.NoError(t, err)
+
+	project, err := uplink.Config{}.OpenProject(context.Background(), access)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, project.Close()) })
+
+	return satellite, project
+}
+
+// newTestLayer returns a layer of a gateway with a metadata store, which
+// serves requests with project in the returned context.
+func newTestLayer(t *testing.T, project *uplink.Project) (*gatewayLayer, context.Context) {
+	return newTestLayerWithConfig(t, project, S3CompatibilityConfig{})
+}
+
+// newTestLayerWithConfig is like newTestLayer, but the gateway uses config
+// with a metadata store.
+func newTestLayerWithConfig(t *testing.T, project *uplink.Project, config S3CompatibilityConfig) (*gatewayLayer, context.Context) {
+	config.MetadataStorePath = filepath.Join(t.TempDir(), "metadata.db")
+
+	gateway := NewStorjGateway(config)
+	t.Cleanup(func() { require.NoError(t, gateway.Close()) })
+
+	layer, err := gateway.NewGatewayLayer(zap.NewNop().Sugar(), auth.Credentials{AccessKey: "access", SecretKey: "secret"})
+	require.NoError(t, err)
+
+	return layer.(*gatewayLayer), WithUplinkProject(context.Background(), project)
+}
+
+// count returns how many requests of method the satellite has served.
+// Requests in batches are counted separately.
+func (satellite *testSatellite) count(method string) int {
+	satellite.mu.Lock()
+	defer satellite.mu.Unlock()
+
+	return satellite.requests[method]
+}
+
+// lastDownloadStart returns the offset of the start of the range of the
+// last download, or 0 if it downloaded a suffix or the whole object.
+func (satellite *testSatellite) lastDownloadStart() int64 {
+	satellite.mu.Lock()
+	defer satellite.mu.Unlock()
+
+	if len(satellite.ranges) == 0 {
+		return 0
+	}
+	switch r := satellite.ranges[len(satellite.ranges)-1].GetRange().(type) {
+	case *pb.Range_StartLimit:
+		return r.StartLimit.PlainStart
+	case *pb.Range_Start:
+		return r.Start.PlainStart
+	}
+	return 0
+}
+
+// serve counts a request of method and locks the satellite until the
+// returned function is called.
+func (satellite *testSatellite) serve(method string) (unlock func()) {
+	satellite.mu.Lock()
+	satellite.requests[method]++
+	return satellite.mu.Unlock
+}
+
+// newStreamID returns a new stream ID, which uplink expects to be an
+// encoded pb.SatStreamID.
+func (satellite *testSatellite) newStreamID() (storj.StreamID, error) {
+	satellite.nextID++
+	return pb.Marshal(&pb.SatStreamID{CreationDate: time.Unix(satellite.nextID, 0)})
+}
+
+// commit makes object the latest version of its key.
+func (satellite *testSatellite) commit(object *testObject, status pb.Object_Status) {
+	satellite.nextID++
+	object.version = binary.BigEndian.AppendUint64(nil, uint64(satellite.nextID))
+	object.status = status
+
+	key := testObjectKey(object.bucket, object.key)
+	satellite.objects[key] = append(satellite.objects[key], object)
+}
+
+// find returns the version of the object at key in bucket or its latest
+// version if version is empty.
+func (satellite *testSatellite) find(bucket, key, version []byte) (*testObject, error) {
+	if _, ok := satellite.buckets[string(bucket)]; !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+
+	versions := satellite.objects[testObjectKey(bucket, key)]
+	if len(version) == 0 {
+		if len(versions) == 0 || versions[len(versions)-1].status == pb.Object_DELETE_MARKER_VERSIONED {
+			return nil, rpcstatus.Error(rpcstatus.NotFound, "object not found")
+		}
+		return versions[len(versions)-1], nil
+	}
+
+	for _, object := range versions {
+		if bytes.Equal(object.version, version) {
+			if object.status == pb.Object_DELETE_MARKER_VERSIONED {
+				return nil, rpcstatus.Error(rpcstatus.MethodNotAllowed, "object is a delete marker")
+			}
+			return object, nil
+		}
+	}
+	return nil, rpcstatus.Error(rpcstatus.NotFound, "object not found")
+}
+
+// findStream returns the pending or committed object with streamID.
+func (satellite *testSatellite) findStream(streamID storj.StreamID) (*testObject, error) {
+	if object, ok := satellite.uploads[string(streamID)]; ok {
+		return object, nil
+	}
+	for _, versions := range satellite.objects {
+		for _, object := range versions {
+			if bytes.Equal(object.streamID, streamID) {
+				return object, nil
+			}
+		}
+	}
+	return nil, rpcstatus.Error(rpcstatus.NotFound, "stream not found")
+}
+
+func testObjectKey(bucket, key []byte) string {
+	return string(bucket) + "\x00" + string(key)
+}
+
+func samePosition(a, b pb.SegmentPosition) bool {
+	return a.PartNumber == b.PartNumber && a.Index == b.Index
+}
+
+// proto returns object as the satellite returns it. Like the satellite, it
+// completes the stream metadata of the object with what uplink leaves to
+// it.
+func (object *testObject) proto() *pb.Object {
+	var plainSize, totalSize int64
+	for _, segment := range object.segments {
+		plainSize += segment.plainSize
+		totalSize += int64(len(segment.data))
+	}
+
+	metadata := object.metadata
+	if object.status != pb.Object_DELETE_MARKER_VERSIONED {
+		var streamMeta pb.StreamMeta
+		if err := pb.Unmarshal(object.metadata, &streamMeta); err == nil {
+			if streamMeta.NumberOfSegments == 0 {
+				streamMeta.NumberOfSegments = int64(len(object.segments))
+			}
+			if streamMeta.EncryptionType == 0 && object.encryption != nil {
+				streamMeta.EncryptionType = int32(object.encryption.CipherSuite)
+				streamMeta.EncryptionBlockSize = int32(object.encryption.BlockSize)
+			}
+			if streamMeta.LastSegmentMeta == nil {
+				streamMeta.LastSegmentMeta = &pb.SegmentMeta{
+					EncryptedKey: object.metadataKey,
+					KeyNonce:     object.metadataNonce[:],
+				}
+			}
+			metadata, _ = pb.Marshal(&streamMeta)
+		}
+	}
+
+	return &pb.Object{
+		Bucket:                        object.bucket,
+		EncryptedObjectKey:            object.key,
+		ObjectVersion:                 object.version,
+		Status:                        object.status,
+		StreamId:                      object.streamID,
+		CreatedAt:                     object.created,
+		ExpiresAt:                     object.expires,
+		EncryptedMetadataNonce:        object.metadataNonce,
+		EncryptedMetadata:             metadata,
+		EncryptedMetadataEncryptedKey: object.metadataKey,
+		EncryptionParameters:          object.encryption,
+		Retention:                     object.retention,
+		TotalSize:                     totalSize,
+		InlineSize:                    totalSize,
+		PlainSize:                     plainSize,
+	}
+}
+
+// sortSegments sorts the segments of object by their position.
+func (object *testObject) sortSegments() {
+	sort.Slice(object.segments, func(i, k int) bool {
+		a, b := object.segments[i].position, object.segments[k].position
+		if a.PartNumber != b.PartNumber {
+			return a.PartNumber < b.PartNumber
+		}
+		return a.Index < b.Index
+	})
+}
+
+// GetBucket implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) GetBucket(ctx context.Context, req *pb.GetBucketRequest) (*pb.GetBucketResponse, error) {
+	defer satellite.serve("GetBucket")()
+
+	created, ok := satellite.buckets[string(req.Name)]
+	if !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+	return &pb.GetBucketResponse{Bucket: &pb.Bucket{Name: req.Name, CreatedAt: created}}, nil
+}
+
+// BeginObject implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) BeginObject(ctx context.Context, req *pb.BeginObjectRequest) (*pb.BeginObjectResponse, error) {
+	defer satellite.serve("BeginObject")()
+
+	if _, ok := satellite.buckets[string(req.Bucket)]; !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+
+	streamID, err := satellite.newStreamID()
+	if err != nil {
+		return nil, rpcstatus.Error(rpcstatus.Internal, err.Error())
+	}
+
+	object := &testObject{
+		bucket:        req.Bucket,
+		key:           req.EncryptedObjectKey,
+		streamID:      streamID,
+		status:        pb.Object_UPLOADING,
+		created:       time.Now(),
+		expires:       req.ExpiresAt,
+		encryption:    req.EncryptionParameters,
+		metadataNonce: req.EncryptedMetadataNonce,
+		metadata:      req.EncryptedMetadata,
+		metadataKey:   req.EncryptedMetadataEncryptedKey,
+	}
+	if req.Retention != nil && req.Retention.Mode != pb.Retention_INVALID {
+		object.retention = req.Retention
+	}
+	satellite.uploads[string(streamID)] = object
+
+	return &pb.BeginObjectResponse{
+		Bucket:               req.Bucket,
+		EncryptedObjectKey:   req.EncryptedObjectKey,
+		StreamId:             streamID,
+		EncryptionParameters: req.EncryptionParameters,
+	}, nil
+}
+
+// MakeInlineSegment implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) MakeInlineSegment(ctx context.Context, req *pb.MakeInlineSegmentRequest) (*pb.MakeInlineSegmentResponse, error) {
+	defer satellite.serve("MakeInlineSegment")()
+
+	object, ok := satellite.uploads[string(req.StreamId)]
+	if !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "upload not found")
+	}
+
+	segment := testSegment{
+		keyNonce:  req.EncryptedKeyNonce,
+		key:       req.EncryptedKey,
+		data:      req.EncryptedInlineData,
+		plainSize: req.PlainSize,
+		etag:      req.EncryptedETag,
+	}
+	if req.Position != nil {
+		segment.position = *req.Position
+	}
+
+	// Uploading a part again replaces it.
+	for i, existing := range object.segments {
+		if samePosition(existing.position, segment.position) {
+			object.segments[i] = segment
+			return &pb.MakeInlineSegmentResponse{}, nil
+		}
+	}
+	object.segments = append(object.segments, segment)
+
+	return &pb.MakeInlineSegmentResponse{}, nil
+}
+
+// CommitObject implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) CommitObject(ctx context.Context, req *pb.CommitObjectRequest) (*pb.CommitObjectResponse, error) {
+	defer satellite.serve("CommitObject")()
+
+	object, ok := satellite.uploads[string(req.StreamId)]
+	if !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "upload not found")
+	}
+	delete(satellite.uploads, string(req.StreamId))
+
+	if !req.SkipOverrideEncryptedMetadata {
+		object.metadataNonce = req.EncryptedMetadataNonce
+		object.metadata = req.EncryptedMetadata
+		object.metadataKey = req.EncryptedMetadataEncryptedKey
+	}
+	object.sortSegments()
+	satellite.commit(object, pb.Object_COMMITTED_VERSIONED)
+
+	return &pb.CommitObjectResponse{Object: object.proto()}, nil
+}
+
+// GetObject implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) GetObject(ctx context.Context, req *pb.GetObjectRequest) (*pb.GetObjectResponse, error) {
+	defer satellite.serve("GetObject")()
+
+	object, err := satellite.find(req.Bucket, req.EncryptedObjectKey, req.ObjectVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetObjectResponse{Object: object.proto()}, nil
+}
+
+// DownloadObject implements pb.DRPCMetainfoServer. It returns all segments
+// of the object regardless of the requested range, which uplink handles.
+func (satellite *testSatellite) DownloadObject(ctx context.Context, req *pb.DownloadObjectRequest) (*pb.DownloadObjectResponse, error) {
+	defer satellite.serve("DownloadObject")()
+
+	satellite.ranges = append(satellite.ranges, req.Range)
+
+	object, err := satellite.find(req.Bucket, req.EncryptedObjectKey, req.ObjectVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset int64
+	downloads := make([]*pb.SegmentDownloadResponse, 0, len(object.segments))
+	for _, segment := range object.segments {
+		position := segment.position
+		downloads = append(downloads, &pb.SegmentDownloadResponse{
+			Position:            &position,
+			PlainOffset:         offset,
+			PlainSize:           segment.plainSize,
+			SegmentSize:         int64(len(segment.data)),
+			EncryptedInlineData: segment.data,
+			EncryptedKeyNonce:   segment.keyNonce,
+			EncryptedKey:        segment.key,
+		})
+		offset += segment.plainSize
+	}
+
+	return &pb.DownloadObjectResponse{
+		Object:          object.proto(),
+		SegmentDownload: downloads,
+		SegmentList:     &pb.ListSegmentsResponse{EncryptionParameters: object.encryption},
+	}, nil
+}
+
+// ListSegments implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) ListSegments(ctx context.Context, req *pb.ListSegmentsRequest) (*pb.ListSegmentsResponse, error) {
+	defer satellite.serve("ListSegments")()
+
+	object, err := satellite.findStream(req.StreamId)
+	if err != nil {
+		return nil, err
+	}
+	object.sortSegments()
+
+	var cursor pb.SegmentPosition
+	if req.CursorPosition != nil {
+		cursor = *req.CursorPosition
+	}
+
+	response := &pb.ListSegmentsResponse{EncryptionParameters: object.encryption}
+	var offset int64
+	for _, segment := range object.segments {
+		position := segment.position
+		if req.CursorPosition != nil && (position.PartNumber < cursor.PartNumber ||
+			position.PartNumber == cursor.PartNumber && position.Index <= cursor.Index) {
+			offset += segment.plainSize
+			continue
+		}
+		response.Items = append(response.Items, &pb.SegmentListItem{
+			Position:          &position,
+			PlainSize:         segment.plainSize,
+			PlainOffset:       offset,
+			CreatedAt:         object.created,
+			EncryptedETag:     segment.etag,
+			EncryptedKeyNonce: segment.keyNonce,
+			EncryptedKey:      segment.key,
+		})
+		offset += segment.plainSize
+	}
+
+	return response, nil
+}
+
+// ListPendingObjectStreams implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) ListPendingObjectStreams(ctx context.Context, req *pb.ListPendingObjectStreamsRequest) (*pb.ListPendingObjectStreamsResponse, error) {
+	defer satellite.serve("ListPendingObjectStreams")()
+
+	if _, ok := satellite.buckets[string(req.Bucket)]; !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+
+	var uploads []*testObject
+	for _, object := range satellite.uploads {
+		if bytes.Equal(object.bucket, req.Bucket) && bytes.Equal(object.key, req.EncryptedObjectKey) &&
+			bytes.Compare(object.streamID, req.StreamIdCursor) > 0 {
+			uploads = append(uploads, object)
+		}
+	}
+	sort.Slice(uploads, func(i, k int) bool {
+		return bytes.Compare(uploads[i].streamID, uploads[k].streamID) < 0
+	})
+
+	response := &pb.ListPendingObjectStreamsResponse{}
+	if req.Limit > 0 && len(uploads) > int(req.Limit) {
+		uploads, response.More = uploads[:req.Limit], true
+	}
+	for _, object := range uploads {
+		object := object.proto()
+		response.Items = append(response.Items, &pb.ObjectListItem{
+			EncryptedObjectKey:            object.EncryptedObjectKey,
+			Status:                        object.Status,
+			CreatedAt:                     object.CreatedAt,
+			ExpiresAt:                     object.ExpiresAt,
+			EncryptedMetadataNonce:        object.EncryptedMetadataNonce,
+			EncryptedMetadataEncryptedKey: object.EncryptedMetadataEncryptedKey,
+			EncryptedMetadata:             object.EncryptedMetadata,
+			StreamId:                      &object.StreamId,
+		})
+	}
+
+	return response, nil
+}
+
+// BeginCopyObject implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) BeginCopyObject(ctx context.Context, req *pb.BeginCopyObjectRequest) (*pb.BeginCopyObjectResponse, error) {
+	defer satellite.serve("BeginCopyObject")()
+
+	source, err := satellite.find(req.Bucket, req.EncryptedObjectKey, req.ObjectVersion)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := satellite.buckets[string(req.NewBucket)]; !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+
+	streamID, err := satellite.newStreamID()
+	if err != nil {
+		return nil, rpcstatus.Error(rpcstatus.Internal, err.Error())
+	}
+	satellite.copies[string(streamID)] = source
+
+	keys := make([]*pb.EncryptedKeyAndNonce, 0, len(source.segments))
+	for _, segment := range source.segments {
+		position := segment.position
+		keys = append(keys, &pb.EncryptedKeyAndNonce{
+			Position:          &position,
+			EncryptedKeyNonce: segment.keyNonce,
+			EncryptedKey:      segment.key,
+		})
+	}
+
+	return &pb.BeginCopyObjectResponse{
+		StreamId:                  streamID,
+		EncryptedMetadataKeyNonce: source.metadataNonce,
+		EncryptedMetadataKey:      source.metadataKey,
+		SegmentKeys:               keys,
+		EncryptionParameters:      source.encryption,
+	}, nil
+}
+
+// FinishCopyObject implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) FinishCopyObject(ctx context.Context, req *pb.FinishCopyObjectRequest) (*pb.FinishCopyObjectResponse, error) {
+	defer satellite.serve("FinishCopyObject")()
+
+	source, ok := satellite.copies[string(req.StreamId)]
+	if !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "copy not found")
+	}
+	delete(satellite.copies, string(req.StreamId))
+
+	streamID, err := satellite.newStreamID()
+	if err != nil {
+		return nil, rpcstatus.Error(rpcstatus.Internal, err.Error())
+	}
+
+	object := &testObject{
+		bucket:        req.NewBucket,
+		key:           req.NewEncryptedObjectKey,
+		streamID:      streamID,
+		created:       time.Now(),
+		expires:       source.expires,
+		encryption:    source.encryption,
+		metadataNonce: req.NewEncryptedMetadataKeyNonce,
+		metadata:      source.metadata,
+		metadataKey:   req.NewEncryptedMetadataKey,
+	}
+	if req.OverrideMetadata {
+		object.metadata = req.NewEncryptedMetadata
+	}
+	if req.Retention != nil && req.Retention.Mode != pb.Retention_INVALID {
+		object.retention = req.Retention
+	}
+
+	for _, segment := range source.segments {
+		for _, key := range req.NewSegmentKeys {
+			if key.Position != nil && samePosition(*key.Position, segment.position) {
+				segment.keyNonce = key.EncryptedKeyNonce
+				segment.key = key.EncryptedKey
+			}
+		}
+		object.segments = append(object.segments, segment)
+	}
+	satellite.commit(object, pb.Object_COMMITTED_VERSIONED)
+
+	return &pb.FinishCopyObjectResponse{Object: object.proto()}, nil
+}
+
+// UpdateObjectMetadata implements pb.DRPCMetainfoServer.
+func (satellite *testSatellite) UpdateObjectMetadata(ctx context.Context, req *pb.UpdateObjectMetadataRequest) (*pb.UpdateObjectMetadataResponse, error) {
+	defer satellite.serve("UpdateObjectMetadata")()
+
+	for _, object := range satellite.objects[testObjectKey(req.Bucket, req.EncryptedObjectKey)] {
+		if bytes.Equal(object.streamID, req.StreamId) {
+			object.metadataNonce = req.EncryptedMetadataNonce
+			object.metadata = req.EncryptedMetadata
+			object.metadataKey = req.EncryptedMetadataEncryptedKey
+			return &pb.UpdateObjectMetadataResponse{}, nil
+		}
+	}
+	return nil, rpcstatus.Error(rpcstatus.NotFound, "object not found")
+}
+
+// BeginDeleteObject implements pb.DRPCMetainfoServer. Without a version, it
+// creates a delete marker, as all buckets are versioned.
+func (satellite *testSatellite) BeginDeleteObject(ctx context.Context, req *pb.BeginDeleteObjectRequest) (*pb.BeginDeleteObjectResponse, error) {
+	defer satellite.serve("BeginDeleteObject")()
+
+	if _, ok := satellite.buckets[string(req.Bucket)]; !ok {
+		return nil, rpcstatus.Error(rpcstatus.NotFound, "bucket not found")
+	}
+
+	// Pending objects are deleted by uplink when uploads fail.
+	if req.StreamId != nil {
+		if object, ok := satellite.uploads[string(*req.StreamId)]; ok {
+			delete(satellite.uploads, string(*req.StreamId))
+			return &pb.BeginDeleteObjectResponse{Object: object.proto()}, nil
+		}
+	}
+
+	key := testObjectKey(req.Bucket, req.EncryptedObjectKey)
+	if len(req.ObjectVersion) == 0 {
+		marker := &testObject{
+			bucket:  req.Bucket,
+			key:     req.EncryptedObjectKey,
+			created: time.Now(),
+		}
+		satellite.commit(marker, pb.Object_DELETE_MARKER_VERSIONED)
+		return &pb.BeginDeleteObjectResponse{Object: marker.proto()}, nil
+	}
+
+	versions := satellite.objects[key]
+	for i, object := range versions {
+		if bytes.Equal(object.version, req.ObjectVersion) {
+			satellite.objects[key] = append(versions[:i:i], versions[i+1:]...)
+			return &pb.BeginDeleteObjectResponse{Object: object.proto()}, nil
+		}
+	}
+	return nil, rpcstatus.Error(rpcstatus.NotFound, "object not found")
+}
+
+// Batch implements pb.DRPCMetainfoServer. Like the satellite, it passes the
+// stream ID of an object begun in the batch to requests without one.
+func (satellite *testSatellite) Batch(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	response := &pb.BatchResponse{}
+	var streamID storj.StreamID
+
+	for _, item := range req.Requests {
+		var result pb.BatchResponseItem
+		var err error
+
+		switch request := item.Request.(type) {
+		case *pb.BatchRequestItem_BucketGet:
+			var r *pb.GetBucketResponse
+			r, err = satellite.GetBucket(ctx, request.BucketGet)
+			result.Response = &pb.BatchResponseItem_BucketGet{BucketGet: r}
+		case *pb.BatchRequestItem_ObjectBegin:
+			var r *pb.BeginObjectResponse
+			r, err = satellite.BeginObject(ctx, request.ObjectBegin)
+			if err == nil {
+				streamID = r.StreamId
+			}
+			result.Response = &pb.BatchResponseItem_ObjectBegin{ObjectBegin: r}
+		case *pb.BatchRequestItem_SegmentMakeInline:
+			if request.SegmentMakeInline.StreamId.IsZero() {
+				request.SegmentMakeInline.StreamId = streamID
+			}
+			var r *pb.MakeInlineSegmentResponse
+			r, err = satellite.MakeInlineSegment(ctx, request.SegmentMakeInline)
+			result.Response = &pb.BatchResponseItem_SegmentMakeInline{SegmentMakeInline: r}
+		case *pb.BatchRequestItem_ObjectCommit:
+			if request.ObjectCommit.StreamId.IsZero() {
+				request.ObjectCommit.StreamId = streamID
+			}
+			var r *pb.CommitObjectResponse
+			r, err = satellite.CommitObject(ctx, request.ObjectCommit)
+			result.Response = &pb.BatchResponseItem_ObjectCommit{ObjectCommit: r}
+		case *pb.BatchRequestItem_ObjectGet:
+			var r *pb.GetObjectResponse
+			r, err = satellite.GetObject(ctx, request.ObjectGet)
+			result.Response = &pb.BatchResponseItem_ObjectGet{ObjectGet: r}
+		case *pb.BatchRequestItem_ObjectDownload:
+			var r *pb.DownloadObjectResponse
+			r, err = satellite.DownloadObject(ctx, request.ObjectDownload)
+			result.Response = &pb.BatchResponseItem_ObjectDownload{ObjectDownload: r}
+		case *pb.BatchRequestItem_SegmentList:
+			var r *pb.ListSegmentsResponse
+			r, err = satellite.ListSegments(ctx, request.SegmentList)
+			result.Response = &pb.BatchResponseItem_SegmentList{SegmentList: r}
+		case *pb.BatchRequestItem_ObjectBeginCopy:
+			var r *pb.BeginCopyObjectResponse
+			r, err = satellite.BeginCopyObject(ctx, request.ObjectBeginCopy)
+			result.Response = &pb.BatchResponseItem_ObjectBeginCopy{ObjectBeginCopy: r}
+		case *pb.BatchRequestItem_ObjectFinishCopy:
+			var r *pb.FinishCopyObjectResponse
+			r, err = satellite.FinishCopyObject(ctx, request.ObjectFinishCopy)
+			result.Response = &pb.BatchResponseItem_ObjectFinishCopy{ObjectFinishCopy: r}
+		case *pb.BatchRequestItem_ObjectUpdateMetadata:
+			var r *pb.UpdateObjectMetadataResponse
+			r, err = satellite.UpdateObjectMetadata(ctx, request.ObjectUpdateMetadata)
+			result.Response = &pb.BatchResponseItem_ObjectUpdateMetadata{ObjectUpdateMetadata: r}
+		case *pb.BatchRequestItem_ObjectBeginDelete:
+			var r *pb.BeginDeleteObjectResponse
+			r, err = satellite.BeginDeleteObject(ctx, request.ObjectBeginDelete)
+			result.Response = &pb.BatchResponseItem_ObjectBeginDelete{ObjectBeginDelete: r}
+		default:
+			err = rpcstatus.Error(rpcstatus.Unimplemented, fmt.Sprintf("%T", request))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		response.Responses = append(response.Responses, &result)
+	}
+
+	return response, nil
+}
+
+// CompressedBatch implements pb.DRPCMetainfoServer. It doesn't compress
+// responses.
+func (satellite *testSatellite) CompressedBatch(ctx context.Context, req *pb.CompressedBatchRequest) (*pb.CompressedBatchResponse, error) {
+	var batch pb.BatchRequest
+	if err := pb.Unmarshal(req.Data, &batch); err != nil {
+		return nil, rpcstatus.Error(rpcstatus.InvalidArgument, err.Error())
+	}
+
+	response, err := satellite.Batch(ctx, &batch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := pb.Marshal(response)
+	if err != nil {
+		return nil, rpcstatus.Error(rpcstatus.Internal, err.Error())
+	}
+
+	return &pb.CompressedBatchResponse{Selected: pb.CompressedBatchRequest_NONE, Data: data}, nil
+}
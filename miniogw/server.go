@@ -0,0 +1,206 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"crypto/tls"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+)
+
+// serverError is the error class for serving requests in front of minio.
+var serverError = errs.Class("server")
+
+// requestHandlers are the middlewares passing request headers minio doesn't
+// pass down to the object layer or rejecting the ones it can't.
+var requestHandlers = []func(http.Handler) http.Handler{
+	WriteConditionsHandler,
+	ChecksumHandler,
+	MetadataDirectiveHandler,
+	WebsiteRedirectLocationHandler,
+	ObjectLockHeadersHandler,
+}
+
+// RegisterHandlers adds the middlewares serving requests to gateway to
+// minio.GlobalHandlers. It has to be called before minio.StartGateway.
+func RegisterHandlers(gateway minio.Gateway) {
+	for _, handler := range requestHandlers {
+		minio.GlobalHandlers = append(minio.GlobalHandlers, handler)
+	}
+	if server, ok := gateway.(APIServer); ok {
+		minio.GlobalHandlers = append(minio.GlobalHandlers, server.APIHandler)
+	}
+}
+
+// serverHandler returns the handler of requests to gateway that serves the
+// ones minio answers before its routes or can't verify and passes the rest to
+// next.
+func serverHandler(gateway minio.Gateway, next http.Handler) http.Handler {
+	if server, ok := gateway.(TrailerServer); ok {
+		next = server.TrailerHandler(next)
+	}
+	if server, ok := gateway.(CORSServer); ok {
+		next = server.CORSHandler(next)
+	}
+	if server, ok := gateway.(WebsiteServer); ok {
+		next = server.WebsiteHandler(next)
+	}
+	return next
+}
+
+// Server serves the S3 API of a gateway at the configured address. minio
+// listens at an internal loopback address, and Server passes it the requests
+// it doesn't serve itself.
+type Server struct {
+	log          *zap.Logger
+	listener     net.Listener
+	tlsConfig    *tls.Config
+	minioAddress string
+
+	mu          sync.Mutex
+	reservation net.Listener
+	server      *http.Server
+}
+
+// NewServer returns a server listening at the address of config. If
+// certsDir, the directory minio loads its certificate from, has a
+// certificate, the server uses TLS with it too.
+func NewServer(log *zap.Logger, config ServerConfig, certsDir string) (_ *Server, err error) {
+	var tlsConfig *tls.Config
+	cert, err := tls.LoadX509KeyPair(filepath.Join(certsDir, "public.crt"), filepath.Join(certsDir, "private.key"))
+	switch {
+	case err == nil:
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, serverError.Wrap(err)
+	}
+
+	// The address of minio stays reserved until minio is about to listen at
+	// it, so that no other process takes it in the meantime.
+	reservation, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, serverError.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, serverError.Wrap(reservation.Close()))
+		}
+	}()
+
+	listener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return nil, serverError.Wrap(err)
+	}
+
+	return &Server{
+		log:          log,
+		listener:     listener,
+		tlsConfig:    tlsConfig,
+		minioAddress: reservation.Addr().String(),
+		reservation:  reservation,
+	}, nil
+}
+
+// MinioAddress returns the address minio has to listen at. It's reserved
+// until ReleaseMinioAddress is called.
+func (server *Server) MinioAddress() string {
+	return server.minioAddress
+}
+
+// ReleaseMinioAddress releases the address minio has to listen at. It has to
+// be called right before minio.StartGateway. minio takes an address rather
+// than a listener, so another process can still take the address in between,
+// but minio then fails to listen and exits along with the gateway.
+func (server *Server) ReleaseMinioAddress() error {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.reservation == nil {
+		return nil
+	}
+	err := server.reservation.Close()
+	server.reservation = nil
+	return serverError.Wrap(err)
+}
+
+// Serve serves requests to gateway until the server is closed, passing the
+// ones it doesn't serve itself to minio. RegisterHandlers has to be called
+// for minio to serve the rest.
+func (server *Server) Serve(gateway minio.Gateway) error {
+	target := &url.URL{Scheme: "http", Host: server.minioAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if server.tlsConfig != nil {
+		// minio uses the same certificate, which is only valid for the
+		// public address.
+		target.Scheme = "https"
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // minio listens at a loopback address.
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+			// Signatures cover the host requests are sent to.
+			r.Out.Host = r.In.Host
+		},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			// Uploads decoded by TrailerHandler fail while they're passed on
+			// if they don't match their trailing checksum.
+			if apiErr, ok := trailerAPIError(err); ok {
+				minio.WriteErrorResponse(r.Context(), w, apiErr, r.URL, false)
+				return
+			}
+			server.log.Debug("passing request to minio failed", zap.Error(err))
+			minio.WriteErrorResponse(r.Context(), w, minio.ToAPIError(r.Context(), minio.BackendDown{}), r.URL, false)
+		},
+	}
+
+	httpServer := &http.Server{
+		Handler:           serverHandler(gateway, proxy),
+		TLSConfig:         server.tlsConfig,
+		ReadHeaderTimeout: time.Minute,
+	}
+
+	server.mu.Lock()
+	server.server = httpServer
+	server.mu.Unlock()
+
+	var err error
+	if server.tlsConfig != nil {
+		err = httpServer.ServeTLS(server.listener, "", "")
+	} else {
+		err = httpServer.Serve(server.listener)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return serverError.Wrap(err)
+}
+
+// Close stops serving requests and releases the address of minio if it's
+// still reserved.
+func (server *Server) Close() error {
+	err := server.ReleaseMinioAddress()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.server == nil {
+		return errs.Combine(err, serverError.Wrap(server.listener.Close()))
+	}
+	return errs.Combine(err, serverError.Wrap(server.server.Close()))
+}
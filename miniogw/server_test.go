@@ -0,0 +1,276 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+	"storj.io/uplink"
+)
+
+// testMinio stands in for minio behind Server. Its routes use the
+// middlewares added to minio.GlobalHandlers by RegisterHandlers, like the
+// routes of minio do, and record the requests that reach them.
+type testMinio struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (m *testMinio) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The body is read like minio reads uploads, and kept for tests.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	m.mu.Lock()
+	m.requests = append(m.requests, r)
+	m.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// last returns the last request that reached minio or nil if there's none.
+func (m *testMinio) last() *http.Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.requests) == 0 {
+		return nil
+	}
+	return m.requests[len(m.requests)-1]
+}
+
+// startTestServer serves gateway through Server in front of a testMinio and
+// returns the URL of the server.
+func startTestServer(t *testing.T, gateway minio.Gateway) (string, *testMinio) {
+	handlers := minio.GlobalHandlers
+	minio.GlobalHandlers = nil
+	RegisterHandlers(gateway)
+	registered := minio.GlobalHandlers
+	minio.GlobalHandlers = handlers
+
+	m := &testMinio{}
+	router := mux.NewRouter().SkipClean(true).UseEncodedPath()
+	bucketRouter := router.PathPrefix("/{bucket}").Subrouter()
+	bucketRouter.Path("/{object:.+}").Handler(m)
+	bucketRouter.NewRoute().Handler(m)
+	router.Use(registered...)
+
+	minioServer := httptest.NewServer(router)
+	t.Cleanup(minioServer.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &Server{
+		log:          zap.NewNop(),
+		listener:     listener,
+		minioAddress: strings.TrimPrefix(minioServer.URL, "http://"),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, server.Serve(gateway))
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, server.Close())
+		wg.Wait()
+	})
+
+	return "http://" + listener.Addr().String(), m
+}
+
+// testAccessKey is the access key of requests startAPITestServer allows.
+const testAccessKey = "access"
+
+// startAPITestServer serves layer through Server and returns an S3 client of
+// it and the URL of the server. Only requests signed with testAccessKey are
+// allowed.
+func startAPITestServer(t *testing.T, layer minio.ObjectLayer) (*miniogo.Client, string) {
+	gateway := &singleTenantGateway{}
+	gateway.layer.Store(&singleTenancyLayer{logger: zap.NewNop(), layer: layer})
+	return startGatewayAPITestServer(t, gateway)
+}
+
+// startGatewayAPITestServer is like startAPITestServer, but serves gateway,
+// which has its layer set already.
+func startGatewayAPITestServer(t *testing.T, gateway *singleTenantGateway) (*miniogo.Client, string) {
+	gateway.authorize = func(ctx context.Context, r *http.Request, action policy.Action, bucket, object string) minio.APIErrorCode {
+		if !strings.Contains(r.Header.Get("Authorization"), "Credential="+testAccessKey+"/") {
+			return minio.ErrAccessDenied
+		}
+		return minio.ErrNone
+	}
+
+	url, _ := startTestServer(t, gateway)
+
+	client, err := miniogo.New(strings.TrimPrefix(url, "http://"), &miniogo.Options{
+		Creds:  credentials.NewStaticV4(testAccessKey, "secret", ""),
+		Region: "us-east-1",
+	})
+	require.NoError(t, err)
+
+	return client, url
+}
+
+// signedRequest sends a request signed with testAccessKey.
+func signedRequest(t *testing.T, method, url string, header map[string]string, body string) *http.Response {
+	r, err := http.NewRequestWithContext(context.Background(), method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	r = signer.SignV4(*r, testAccessKey, "secret", "", "us-east-1")
+
+	resp, err := http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServer(t *testing.T) {
+	storjGateway := NewStorjGateway(S3CompatibilityConfig{MetadataStorePath: filepath.Join(t.TempDir(), "metadata.db")})
+	defer func() { require.NoError(t, storjGateway.Close()) }()
+
+	store, err := storjGateway.openMetadataStore()
+	require.NoError(t, err)
+
+	value, err := xml.Marshal(&BucketCORS{Rules: []CORSRule{
+		{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"PUT"}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, store.put(storeBucketConfig, projectBucket{project: "project", name: "web"}, storeCORSKey, value))
+
+	gateway := NewSingleTenantGateway(zap.NewNop(), nil, uplink.Config{}, storjGateway.WithLogger(zap.NewNop().Sugar()),
+		PublicAccessConfig{Buckets: []string{"site"}}, WebsiteConfig{Endpoint: "website.test"}).(*singleTenantGateway)
+	gateway.layer.Store(&singleTenancyLayer{
+		logger:    zap.NewNop(),
+		projectID: "project",
+		layer: &websiteTestLayer{
+			websites: map[string]*BucketWebsite{
+				"site": {IndexDocument: &WebsiteIndexDocument{Suffix: "index.html"}},
+			},
+			objects: map[string]minio.ObjectInfo{
+				"site/index.html": {Bucket: "site", Name: "index.html", Size: 4, ETag: "etag", UserDefined: map[string]string{}},
+			},
+			data: map[string]string{"site/index.html": "home"},
+		},
+		public: newPublicAccess(PublicAccessConfig{Buckets: []string{"site"}}),
+	})
+
+	url, m := startTestServer(t, gateway)
+
+	request := func(method, path, host string, header map[string]string) *http.Response {
+		r, err := http.NewRequestWithContext(context.Background(), method, url+path, nil)
+		require.NoError(t, err)
+		if host != "" {
+			r.Host = host
+		}
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	// Headers minio doesn't pass down reach the object layer through the
+	// request context.
+	resp := request(http.MethodPut, "/bucket/key", "s3.test", map[string]string{
+		"If-Match":                    `"abc"`,
+		"X-Amz-Checksum-Crc32":        "AAAAAA==",
+		websiteRedirectLocationHeader: "/other.html",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	r := m.last()
+	require.NotNil(t, r)
+	// Signatures cover the host requests are sent to.
+	assert.Equal(t, "s3.test", r.Host)
+	assert.Equal(t, "/bucket/key", r.URL.Path)
+
+	conditions, ok := getWriteConditions(r.Context())
+	require.True(t, ok)
+	assert.Equal(t, writeConditions{ifMatch: `"abc"`}, conditions)
+
+	checksums, ok := getChecksumRequest(r.Context())
+	require.True(t, ok)
+	assert.Equal(t, checksumRequest{algorithm: checksumCRC32, expected: "AAAAAA=="}, checksums)
+
+	metadata := map[string]string{}
+	require.NoError(t, setWebsiteRedirectLocation(r.Context(), metadata))
+	assert.Equal(t, "/other.html", metadata[websiteRedirectLocationHeader])
+
+	resp = request(http.MethodPut, "/bucket/key", "", map[string]string{
+		"X-Amz-Copy-Source":        "bucket/key",
+		"X-Amz-Metadata-Directive": "REPLACE",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	replace, ok := getMetadataDirective(m.last().Context())
+	require.True(t, ok)
+	assert.True(t, replace)
+
+	// CORS preflight requests are answered in front of minio, which answers
+	// them before its routes.
+	before := m.last()
+	resp = request(http.MethodOptions, "/web/index.html", "", map[string]string{
+		"Origin":                        "https://app.example.com",
+		"Access-Control-Request-Method": "PUT",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Same(t, before, m.last())
+
+	// So are requests to the website endpoint.
+	resp = request(http.MethodGet, "/site/", "website.test", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "home", string(body))
+	assert.Same(t, before, m.last())
+}
+
+func TestServerReservesMinioAddress(t *testing.T) {
+	server, err := NewServer(zap.NewNop(), ServerConfig{Address: "127.0.0.1:0"}, filepath.Join(t.TempDir(), "certs"))
+	require.NoError(t, err)
+
+	// No one else can listen at the address of minio until it's released.
+	_, err = net.Listen("tcp", server.MinioAddress())
+	require.Error(t, err)
+
+	require.NoError(t, server.ReleaseMinioAddress())
+	listener, err := net.Listen("tcp", server.MinioAddress())
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	require.NoError(t, server.ReleaseMinioAddress())
+	require.NoError(t, server.Close())
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 
 	miniogo "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/tags"
@@ -17,6 +18,7 @@ import (
 	"storj.io/common/errs2"
 	minio "storj.io/minio/cmd"
 	"storj.io/minio/pkg/auth"
+	objectlock "storj.io/minio/pkg/bucket/object/lock"
 	"storj.io/minio/pkg/bucket/policy"
 	"storj.io/uplink"
 )
@@ -26,18 +28,45 @@ type singleTenantGateway struct {
 	access  *uplink.Access
 	config  uplink.Config
 	gateway minio.Gateway
-	website bool
+	public  publicAccess
+	website WebsiteConfig
+	domains []websiteDomain
+
+	// authorize checks requests to APIs served by APIHandler.
+	authorize func(ctx context.Context, r *http.Request, action policy.Action, bucket, object string) minio.APIErrorCode
+
+	// layer is the layer created by NewGatewayLayer, which serves websites.
+	layer atomic.Pointer[singleTenancyLayer]
+	// creds are the credentials passed to NewGatewayLayer, which uploads
+	// with trailing checksums are verified and signed again with.
+	creds atomic.Pointer[auth.Credentials]
 }
 
 // NewSingleTenantGateway returns a wrapper of minio.Gateway that logs responses
-// and makes gateway single-tenant.
-func NewSingleTenantGateway(log *zap.Logger, access *uplink.Access, config uplink.Config, gateway minio.Gateway, website bool) minio.Gateway {
+// and makes gateway single-tenant. Buckets named by public are readable by
+// anyone unless they have a policy of their own. The returned gateway is a
+// WebsiteServer serving websites as configured by website.
+func NewSingleTenantGateway(log *zap.Logger, access *uplink.Access, config uplink.Config, gateway minio.Gateway, public PublicAccessConfig, website WebsiteConfig) minio.Gateway {
+	var domains []websiteDomain
+	for _, entry := range website.Domains {
+		domain, err := parseWebsiteDomain(entry)
+		if err != nil {
+			log.Warn("ignoring website domain", zap.String("domain", entry), zap.Error(err))
+			continue
+		}
+		domains = append(domains, domain)
+	}
+
 	return &singleTenantGateway{
 		log:     log,
 		access:  access,
 		config:  config,
 		gateway: gateway,
+		public:  newPublicAccess(public),
 		website: website,
+		domains: sortWebsiteDomains(domains),
+
+		authorize: authorizeRequest,
 	}
 }
 
@@ -51,12 +80,19 @@ func (g *singleTenantGateway) NewGatewayLayer(creds auth.Credentials) (minio.Obj
 
 	layer, err := g.gateway.NewGatewayLayer(creds)
 
-	return &singleTenancyLayer{
-		logger:  g.log,
-		project: project,
-		layer:   layer,
-		website: g.website,
-	}, err
+	single := &singleTenancyLayer{
+		logger:    g.log,
+		project:   project,
+		projectID: accessProjectID(g.access),
+		layer:     layer,
+		public:    g.public,
+	}
+	if err == nil {
+		g.layer.Store(single)
+		g.creds.Store(&creds)
+	}
+
+	return single, err
 }
 
 func (g *singleTenantGateway) Production() bool { return g.gateway.Production() }
@@ -64,11 +100,17 @@ func (g *singleTenantGateway) Production() bool { return g.gateway.Production()
 type singleTenancyLayer struct {
 	minio.GatewayUnsupported
 
-	logger  *zap.Logger
-	project *uplink.Project
-	layer   minio.ObjectLayer
+	logger    *zap.Logger
+	project   *uplink.Project
+	projectID string
+	layer     minio.ObjectLayer
+
+	public publicAccess
+}
 
-	website bool
+// withProject injects the project of l and its ID into ctx.
+func (l *singleTenancyLayer) withProject(ctx context.Context) context.Context {
+	return WithProjectID(WithUplinkProject(ctx, l.project), l.projectID)
 }
 
 // minioError checks if the given error is a minio error.
@@ -108,7 +150,7 @@ func (l *singleTenancyLayer) Shutdown(ctx context.Context) error {
 }
 
 func (l *singleTenancyLayer) StorageInfo(ctx context.Context) (minio.StorageInfo, []error) {
-	info, errors := l.layer.StorageInfo(WithUplinkProject(ctx, l.project))
+	info, errors := l.layer.StorageInfo(l.withProject(ctx))
 
 	for _, err := range errors {
 		_ = l.log(err)
@@ -118,65 +160,65 @@ func (l *singleTenancyLayer) StorageInfo(ctx context.Context) (minio.StorageInfo
 }
 
 func (l *singleTenancyLayer) MakeBucketWithLocation(ctx context.Context, bucket string, opts minio.BucketOptions) error {
-	return l.log(l.layer.MakeBucketWithLocation(WithUplinkProject(ctx, l.project), bucket, opts))
+	return l.log(l.layer.MakeBucketWithLocation(l.withProject(ctx), bucket, opts))
 }
 
 func (l *singleTenancyLayer) GetBucketInfo(ctx context.Context, bucket string) (bucketInfo minio.BucketInfo, err error) {
-	bucketInfo, err = l.layer.GetBucketInfo(WithUplinkProject(ctx, l.project), bucket)
+	bucketInfo, err = l.layer.GetBucketInfo(l.withProject(ctx), bucket)
 	return bucketInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) ListBuckets(ctx context.Context) (buckets []minio.BucketInfo, err error) {
-	buckets, err = l.layer.ListBuckets(WithUplinkProject(ctx, l.project))
+	buckets, err = l.layer.ListBuckets(l.withProject(ctx))
 	return buckets, l.log(err)
 }
 
 func (l *singleTenancyLayer) DeleteBucket(ctx context.Context, bucket string, forceDelete bool) error {
-	return l.log(l.layer.DeleteBucket(WithUplinkProject(ctx, l.project), bucket, forceDelete))
+	return l.log(l.layer.DeleteBucket(l.withProject(ctx), bucket, forceDelete))
 }
 
 func (l *singleTenancyLayer) ListObjects(ctx context.Context, bucket, prefix, marker, delimiter string, maxKeys int) (result minio.ListObjectsInfo, err error) {
-	result, err = l.layer.ListObjects(WithUplinkProject(ctx, l.project), bucket, prefix, marker, delimiter, maxKeys)
+	result, err = l.layer.ListObjects(l.withProject(ctx), bucket, prefix, marker, delimiter, maxKeys)
 	return result, l.log(err)
 }
 
 func (l *singleTenancyLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (result minio.ListObjectsV2Info, err error) {
-	result, err = l.layer.ListObjectsV2(WithUplinkProject(ctx, l.project), bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
+	result, err = l.layer.ListObjectsV2(l.withProject(ctx), bucket, prefix, continuationToken, delimiter, maxKeys, fetchOwner, startAfter)
 	return result, l.log(err)
 }
 
 func (l *singleTenancyLayer) ListObjectVersions(ctx context.Context, bucket, prefix, marker, versionMarker, delimiter string, maxKeys int) (result minio.ListObjectVersionsInfo, err error) {
-	result, err = l.layer.ListObjectVersions(WithUplinkProject(ctx, l.project), bucket, prefix, marker, versionMarker, delimiter, maxKeys)
+	result, err = l.layer.ListObjectVersions(l.withProject(ctx), bucket, prefix, marker, versionMarker, delimiter, maxKeys)
 	return result, l.log(err)
 }
 
 func (l *singleTenancyLayer) GetObjectNInfo(ctx context.Context, bucket, object string, rs *minio.HTTPRangeSpec, h http.Header, lockType minio.LockType, opts minio.ObjectOptions) (reader *minio.GetObjectReader, err error) {
-	reader, err = l.layer.GetObjectNInfo(WithUplinkProject(ctx, l.project), bucket, object, rs, h, lockType, opts)
+	reader, err = l.layer.GetObjectNInfo(l.withProject(ctx), bucket, object, rs, h, lockType, opts)
 	return reader, l.log(err)
 }
 
 func (l *singleTenancyLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	objInfo, err = l.layer.GetObjectInfo(WithUplinkProject(ctx, l.project), bucket, object, opts)
+	objInfo, err = l.layer.GetObjectInfo(l.withProject(ctx), bucket, object, opts)
 	return objInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) PutObject(ctx context.Context, bucket, object string, data *minio.PutObjReader, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	objInfo, err = l.layer.PutObject(WithUplinkProject(ctx, l.project), bucket, object, data, opts)
+	objInfo, err = l.layer.PutObject(l.withProject(ctx), bucket, object, data, opts)
 	return objInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) CopyObject(ctx context.Context, srcBucket, srcObject, destBucket, destObject string, srcInfo minio.ObjectInfo, srcOpts, destOpts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	objInfo, err = l.layer.CopyObject(WithUplinkProject(ctx, l.project), srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, destOpts)
+	objInfo, err = l.layer.CopyObject(l.withProject(ctx), srcBucket, srcObject, destBucket, destObject, srcInfo, srcOpts, destOpts)
 	return objInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) DeleteObject(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	objInfo, err = l.layer.DeleteObject(WithUplinkProject(ctx, l.project), bucket, object, opts)
+	objInfo, err = l.layer.DeleteObject(l.withProject(ctx), bucket, object, opts)
 	return objInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) DeleteObjects(ctx context.Context, bucket string, objects []minio.ObjectToDelete, opts minio.ObjectOptions) (deleted []minio.DeletedObject, errors []error) {
-	deleted, errors = l.layer.DeleteObjects(WithUplinkProject(ctx, l.project), bucket, objects, opts)
+	deleted, errors = l.layer.DeleteObjects(l.withProject(ctx), bucket, objects, opts)
 
 	for _, err := range errors {
 		_ = l.log(err)
@@ -186,71 +228,69 @@ func (l *singleTenancyLayer) DeleteObjects(ctx context.Context, bucket string, o
 }
 
 func (l *singleTenancyLayer) ListMultipartUploads(ctx context.Context, bucket, prefix, keyMarker, uploadIDMarker, delimiter string, maxUploads int) (result minio.ListMultipartsInfo, err error) {
-	result, err = l.layer.ListMultipartUploads(WithUplinkProject(ctx, l.project), bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
+	result, err = l.layer.ListMultipartUploads(l.withProject(ctx), bucket, prefix, keyMarker, uploadIDMarker, delimiter, maxUploads)
 	return result, l.log(err)
 }
 
 func (l *singleTenancyLayer) NewMultipartUpload(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (uploadID string, err error) {
-	uploadID, err = l.layer.NewMultipartUpload(WithUplinkProject(ctx, l.project), bucket, object, opts)
+	uploadID, err = l.layer.NewMultipartUpload(l.withProject(ctx), bucket, object, opts)
 	return uploadID, l.log(err)
 }
 
 func (l *singleTenancyLayer) PutObjectPart(ctx context.Context, bucket, object, uploadID string, partID int, data *minio.PutObjReader, opts minio.ObjectOptions) (info minio.PartInfo, err error) {
-	info, err = l.layer.PutObjectPart(WithUplinkProject(ctx, l.project), bucket, object, uploadID, partID, data, opts)
+	info, err = l.layer.PutObjectPart(l.withProject(ctx), bucket, object, uploadID, partID, data, opts)
+	return info, l.log(err)
+}
+
+func (l *singleTenancyLayer) CopyObjectPart(ctx context.Context, srcBucket, srcObject, destBucket, destObject, uploadID string, partID int, startOffset, length int64, srcInfo minio.ObjectInfo, srcOpts, destOpts minio.ObjectOptions) (info minio.PartInfo, err error) {
+	info, err = l.layer.CopyObjectPart(l.withProject(ctx), srcBucket, srcObject, destBucket, destObject, uploadID, partID, startOffset, length, srcInfo, srcOpts, destOpts)
 	return info, l.log(err)
 }
 
 func (l *singleTenancyLayer) GetMultipartInfo(ctx context.Context, bucket string, object string, uploadID string, opts minio.ObjectOptions) (info minio.MultipartInfo, err error) {
-	info, err = l.layer.GetMultipartInfo(WithUplinkProject(ctx, l.project), bucket, object, uploadID, opts)
+	info, err = l.layer.GetMultipartInfo(l.withProject(ctx), bucket, object, uploadID, opts)
 	return info, l.log(err)
 }
 
 func (l *singleTenancyLayer) ListObjectParts(ctx context.Context, bucket, object, uploadID string, partNumberMarker int, maxParts int, opts minio.ObjectOptions) (result minio.ListPartsInfo, err error) {
-	result, err = l.layer.ListObjectParts(WithUplinkProject(ctx, l.project), bucket, object, uploadID, partNumberMarker, maxParts, opts)
+	result, err = l.layer.ListObjectParts(l.withProject(ctx), bucket, object, uploadID, partNumberMarker, maxParts, opts)
 	return result, l.log(err)
 }
 
 func (l *singleTenancyLayer) AbortMultipartUpload(ctx context.Context, bucket, object, uploadID string, opts minio.ObjectOptions) error {
-	return l.log(l.layer.AbortMultipartUpload(WithUplinkProject(ctx, l.project), bucket, object, uploadID, opts))
+	return l.log(l.layer.AbortMultipartUpload(l.withProject(ctx), bucket, object, uploadID, opts))
 }
 
 func (l *singleTenancyLayer) CompleteMultipartUpload(ctx context.Context, bucket, object, uploadID string, uploadedParts []minio.CompletePart, opts minio.ObjectOptions) (objInfo minio.ObjectInfo, err error) {
-	objInfo, err = l.layer.CompleteMultipartUpload(WithUplinkProject(ctx, l.project), bucket, object, uploadID, uploadedParts, opts)
+	objInfo, err = l.layer.CompleteMultipartUpload(l.withProject(ctx), bucket, object, uploadID, uploadedParts, opts)
 	return objInfo, l.log(err)
 }
 
+func (l *singleTenancyLayer) SetBucketPolicy(ctx context.Context, bucket string, bucketPolicy *policy.Policy) error {
+	return l.log(l.layer.SetBucketPolicy(l.withProject(ctx), bucket, bucketPolicy))
+}
+
+// GetBucketPolicy returns the policy of bucket. Public buckets without a
+// policy of their own get the policy of their public access.
 func (l *singleTenancyLayer) GetBucketPolicy(ctx context.Context, bucket string) (*policy.Policy, error) {
-	if !l.website {
-		return nil, minio.NotImplemented{}
+	bucketPolicy, err := l.layer.GetBucketPolicy(l.withProject(ctx), bucket)
+	if err == nil || !errors.As(err, &minio.BucketPolicyNotFound{}) {
+		return bucketPolicy, l.log(err)
+	}
+
+	publicPolicy, publicErr := l.public.policy(bucket)
+	if publicErr != nil {
+		return nil, l.log(publicErr)
 	}
+	if publicPolicy == nil {
+		return nil, l.log(err)
+	}
+
+	return publicPolicy, nil
+}
 
-	// Allow reading any file from any bucket
-	return &policy.Policy{
-		Version: "2012-10-17",
-		Statements: []policy.Statement{
-			{
-				Effect:    policy.Allow,
-				Principal: policy.NewPrincipal("*"),
-				Actions: policy.NewActionSet(
-					policy.GetBucketLocationAction,
-					policy.ListBucketAction,
-				),
-				Resources: policy.NewResourceSet(
-					policy.NewResource(bucket, ""),
-				),
-			},
-			{
-				Effect:    policy.Allow,
-				Principal: policy.NewPrincipal("*"),
-				Actions: policy.NewActionSet(
-					policy.GetObjectAction,
-				),
-				Resources: policy.NewResourceSet(
-					policy.NewResource(bucket, "*"),
-				),
-			},
-		},
-	}, nil
+func (l *singleTenancyLayer) DeleteBucketPolicy(ctx context.Context, bucket string) error {
+	return l.log(l.layer.DeleteBucketPolicy(l.withProject(ctx), bucket))
 }
 
 func (l *singleTenancyLayer) IsTaggingSupported() bool {
@@ -258,16 +298,184 @@ func (l *singleTenancyLayer) IsTaggingSupported() bool {
 }
 
 func (l *singleTenancyLayer) PutObjectTags(ctx context.Context, bucketName, objectPath string, tags string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
-	objInfo, err := l.layer.PutObjectTags(WithUplinkProject(ctx, l.project), bucketName, objectPath, tags, opts)
+	objInfo, err := l.layer.PutObjectTags(l.withProject(ctx), bucketName, objectPath, tags, opts)
 	return objInfo, l.log(err)
 }
 
 func (l *singleTenancyLayer) GetObjectTags(ctx context.Context, bucketName, objectPath string, opts minio.ObjectOptions) (t *tags.Tags, err error) {
-	t, err = l.layer.GetObjectTags(WithUplinkProject(ctx, l.project), bucketName, objectPath, opts)
+	t, err = l.layer.GetObjectTags(l.withProject(ctx), bucketName, objectPath, opts)
 	return t, l.log(err)
 }
 
 func (l *singleTenancyLayer) DeleteObjectTags(ctx context.Context, bucketName, objectPath string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
-	objInfo, err := l.layer.DeleteObjectTags(WithUplinkProject(ctx, l.project), bucketName, objectPath, opts)
+	objInfo, err := l.layer.DeleteObjectTags(l.withProject(ctx), bucketName, objectPath, opts)
 	return objInfo, l.log(err)
 }
+
+func (l *singleTenancyLayer) objectLockLayer() (ObjectLockLayer, error) {
+	layer, ok := l.layer.(ObjectLockLayer)
+	if !ok {
+		return nil, minio.NotImplemented{}
+	}
+	return layer, nil
+}
+
+func (l *singleTenancyLayer) GetObjectLockConfig(ctx context.Context, bucketName string) (config *objectlock.Config, err error) {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return nil, err
+	}
+	config, err = layer.GetObjectLockConfig(l.withProject(ctx), bucketName)
+	return config, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetObjectLockConfig(ctx context.Context, bucketName string, config *objectlock.Config) error {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return err
+	}
+	return l.log(layer.SetObjectLockConfig(l.withProject(ctx), bucketName, config))
+}
+
+func (l *singleTenancyLayer) GetObjectRetention(ctx context.Context, bucketName, objectPath, versionID string) (retention *objectlock.ObjectRetention, err error) {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return nil, err
+	}
+	retention, err = layer.GetObjectRetention(l.withProject(ctx), bucketName, objectPath, versionID)
+	return retention, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetObjectRetention(ctx context.Context, bucketName, objectPath, versionID string, retention *objectlock.ObjectRetention) error {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return err
+	}
+	return l.log(layer.SetObjectRetention(l.withProject(ctx), bucketName, objectPath, versionID, retention))
+}
+
+func (l *singleTenancyLayer) GetObjectLegalHold(ctx context.Context, bucketName, objectPath, versionID string) (legalHold *objectlock.ObjectLegalHold, err error) {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return nil, err
+	}
+	legalHold, err = layer.GetObjectLegalHold(l.withProject(ctx), bucketName, objectPath, versionID)
+	return legalHold, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetObjectLegalHold(ctx context.Context, bucketName, objectPath, versionID string, legalHold *objectlock.ObjectLegalHold) error {
+	layer, err := l.objectLockLayer()
+	if err != nil {
+		return err
+	}
+	return l.log(layer.SetObjectLegalHold(l.withProject(ctx), bucketName, objectPath, versionID, legalHold))
+}
+
+func (l *singleTenancyLayer) GetObjectAttributes(ctx context.Context, bucketName, objectPath string, opts ObjectAttributesOptions) (ObjectAttributes, error) {
+	layer, ok := l.layer.(ObjectAttributesLayer)
+	if !ok {
+		return ObjectAttributes{}, minio.NotImplemented{}
+	}
+	attributes, err := layer.GetObjectAttributes(l.withProject(ctx), bucketName, objectPath, opts)
+	return attributes, l.log(err)
+}
+
+func (l *singleTenancyLayer) GetBucketLifecycle(ctx context.Context, bucketName string) (*BucketLifecycle, error) {
+	layer, ok := l.layer.(BucketLifecycleLayer)
+	if !ok {
+		return nil, minio.NotImplemented{}
+	}
+	config, err := layer.GetBucketLifecycle(l.withProject(ctx), bucketName)
+	return config, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetBucketLifecycle(ctx context.Context, bucketName string, config *BucketLifecycle) error {
+	layer, ok := l.layer.(BucketLifecycleLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.SetBucketLifecycle(l.withProject(ctx), bucketName, config))
+}
+
+func (l *singleTenancyLayer) DeleteBucketLifecycle(ctx context.Context, bucketName string) error {
+	layer, ok := l.layer.(BucketLifecycleLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.DeleteBucketLifecycle(l.withProject(ctx), bucketName))
+}
+
+func (l *singleTenancyLayer) GetBucketCORS(ctx context.Context, bucketName string) (*BucketCORS, error) {
+	layer, ok := l.layer.(BucketCORSLayer)
+	if !ok {
+		return nil, minio.NotImplemented{}
+	}
+	config, err := layer.GetBucketCORS(l.withProject(ctx), bucketName)
+	return config, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetBucketCORS(ctx context.Context, bucketName string, config *BucketCORS) error {
+	layer, ok := l.layer.(BucketCORSLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.SetBucketCORS(l.withProject(ctx), bucketName, config))
+}
+
+func (l *singleTenancyLayer) DeleteBucketCORS(ctx context.Context, bucketName string) error {
+	layer, ok := l.layer.(BucketCORSLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.DeleteBucketCORS(l.withProject(ctx), bucketName))
+}
+
+func (l *singleTenancyLayer) GetBucketWebsite(ctx context.Context, bucketName string) (*BucketWebsite, error) {
+	layer, ok := l.layer.(BucketWebsiteLayer)
+	if !ok {
+		return nil, minio.NotImplemented{}
+	}
+	config, err := layer.GetBucketWebsite(l.withProject(ctx), bucketName)
+	return config, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetBucketWebsite(ctx context.Context, bucketName string, config *BucketWebsite) error {
+	layer, ok := l.layer.(BucketWebsiteLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.SetBucketWebsite(l.withProject(ctx), bucketName, config))
+}
+
+func (l *singleTenancyLayer) DeleteBucketWebsite(ctx context.Context, bucketName string) error {
+	layer, ok := l.layer.(BucketWebsiteLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.DeleteBucketWebsite(l.withProject(ctx), bucketName))
+}
+
+func (l *singleTenancyLayer) GetBucketNotification(ctx context.Context, bucketName string) (*BucketNotification, error) {
+	layer, ok := l.layer.(BucketNotificationLayer)
+	if !ok {
+		return nil, minio.NotImplemented{}
+	}
+	config, err := layer.GetBucketNotification(l.withProject(ctx), bucketName)
+	return config, l.log(err)
+}
+
+func (l *singleTenancyLayer) SetBucketNotification(ctx context.Context, bucketName string, config *BucketNotification) error {
+	layer, ok := l.layer.(BucketNotificationLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.SetBucketNotification(l.withProject(ctx), bucketName, config))
+}
+
+func (l *singleTenancyLayer) DeleteBucketNotification(ctx context.Context, bucketName string) error {
+	layer, ok := l.layer.(BucketNotificationLayer)
+	if !ok {
+		return minio.NotImplemented{}
+	}
+	return l.log(layer.DeleteBucketNotification(l.withProject(ctx), bucketName))
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// sortedListings keeps exhaustive listings that have been sorted and
+// partially consumed between pages, so that listing a bucket page by page
+// lists and sorts it only once. A listing is kept under the state of its next
+// page until that page is requested or ttl passes, whichever comes first, and
+// the least recently kept listing makes room for new ones once max listings
+// are kept. Pages after the first one don't reflect changes made after it was
+// listed. Kept listings don't hold their run files open.
+//
+// All methods are safe to call on a nil *sortedListings, which keeps nothing.
+type sortedListings[T any] struct {
+	ttl time.Duration
+	max int
+
+	mu       sync.Mutex
+	closed   bool
+	listings map[string]*sortedListing[T]
+}
+
+// sortedListing is an exhaustive listing whose sorted items are consumed page
+// by page.
+type sortedListing[T any] struct {
+	sorter *sorter[T]
+	items  *sortedItems[T]
+
+	kept  time.Time
+	timer *time.Timer
+}
+
+// Close removes the temporary files of listing.
+func (listing *sortedListing[T]) Close() error {
+	return listing.sorter.Close()
+}
+
+// newSortedListings returns a new sortedListings keeping at most max listings
+// for ttl or nil if either is not positive.
+func newSortedListings[T any](ttl time.Duration, max int) *sortedListings[T] {
+	if ttl <= 0 || max <= 0 {
+		return nil
+	}
+
+	return &sortedListings[T]{
+		ttl:      ttl,
+		max:      max,
+		listings: make(map[string]*sortedListing[T]),
+	}
+}
+
+// take returns the listing kept under key, if any, and stops keeping it.
+func (listings *sortedListings[T]) take(key string) *sortedListing[T] {
+	if listings == nil {
+		return nil
+	}
+
+	listings.mu.Lock()
+	defer listings.mu.Unlock()
+
+	listing, ok := listings.listings[key]
+	if !ok {
+		return nil
+	}
+
+	delete(listings.listings, key)
+	listing.timer.Stop()
+
+	return listing
+}
+
+// keep keeps listing under key. If it can't be kept, it's closed.
+func (listings *sortedListings[T]) keep(key string, listing *sortedListing[T]) error {
+	if listings == nil {
+		return listing.Close()
+	}
+
+	if err := listing.sorter.Release(); err != nil {
+		return errs.Combine(err, listing.Close())
+	}
+
+	listings.mu.Lock()
+	defer listings.mu.Unlock()
+
+	if listings.closed {
+		return listing.Close()
+	}
+
+	if previous, ok := listings.listings[key]; ok {
+		listings.evict(key, previous)
+	}
+	for len(listings.listings) >= listings.max {
+		var oldestKey string
+		var oldest *sortedListing[T]
+		for key, listing := range listings.listings {
+			if oldest == nil || listing.kept.Before(oldest.kept) {
+				oldestKey, oldest = key, listing
+			}
+		}
+		listings.evict(oldestKey, oldest)
+	}
+
+	listing.kept = time.Now()
+	listing.timer = time.AfterFunc(listings.ttl, func() {
+		listings.expire(key, listing)
+	})
+	listings.listings[key] = listing
+
+	return nil
+}
+
+// evict stops keeping listing under key and closes it. There's no one to
+// report errors of closing it to, so they're only counted. The caller must
+// hold the lock.
+func (listings *sortedListings[T]) evict(key string, listing *sortedListing[T]) {
+	delete(listings.listings, key)
+	listing.timer.Stop()
+	if err := listing.Close(); err != nil {
+		mon.Event("sorted_listing_close_failed")
+	}
+}
+
+// expire closes listing if it's still kept under key.
+func (listings *sortedListings[T]) expire(key string, listing *sortedListing[T]) {
+	listings.mu.Lock()
+	defer listings.mu.Unlock()
+
+	if listings.listings[key] == listing {
+		listings.evict(key, listing)
+	}
+}
+
+// Close closes all kept listings. Listings passed to keep afterwards are
+// closed right away.
+func (listings *sortedListings[T]) Close() error {
+	if listings == nil {
+		return nil
+	}
+
+	listings.mu.Lock()
+	defer listings.mu.Unlock()
+
+	listings.closed = true
+
+	var group errs.Group
+	for key, listing := range listings.listings {
+		delete(listings.listings, key)
+		listing.timer.Stop()
+		group.Add(listing.Close())
+	}
+
+	return group.Err()
+}
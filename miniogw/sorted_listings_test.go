@@ -0,0 +1,143 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/uplink"
+)
+
+// newTestListing returns a listing of keys whose items are spilled to dir.
+func newTestListing(t *testing.T, dir string, keys ...string) *sortedListing[uplink.Object] {
+	sorter := newObjectSorter(dir, 10)
+	for _, key := range keys {
+		require.NoError(t, sorter.Add(&uplink.Object{Key: key}))
+	}
+	require.NoError(t, sorter.Flush())
+
+	items, err := sorter.Iterate()
+	require.NoError(t, err)
+
+	return &sortedListing[uplink.Object]{sorter: sorter, items: items}
+}
+
+func countFiles(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	return len(entries)
+}
+
+func TestSortedListings(t *testing.T) {
+	dir := t.TempDir()
+
+	listings := newSortedListings[uplink.Object](time.Hour, 2)
+
+	a := newTestListing(t, dir, "a")
+	require.NoError(t, listings.keep("a", a))
+	require.NoError(t, listings.keep("b", newTestListing(t, dir, "b")))
+	assert.Equal(t, 2, countFiles(t, dir))
+
+	// The least recently kept listing makes room for new ones.
+	require.NoError(t, listings.keep("c", newTestListing(t, dir, "c")))
+	assert.Nil(t, listings.take("a"))
+	assert.Equal(t, 2, countFiles(t, dir))
+
+	b := listings.take("b")
+	require.NotNil(t, b)
+	// Kept listings don't hold their run files open.
+	for _, run := range b.sorter.runs {
+		assert.Nil(t, run.file)
+	}
+	require.True(t, b.items.Next())
+	assert.Equal(t, "b", b.items.Item().Key)
+	// Listings are only taken once.
+	assert.Nil(t, listings.take("b"))
+	require.NoError(t, b.Close())
+
+	require.NoError(t, listings.Close())
+	assert.Zero(t, countFiles(t, dir))
+
+	// Nothing is kept once closed.
+	require.NoError(t, listings.keep("d", newTestListing(t, dir, "d")))
+	assert.Nil(t, listings.take("d"))
+	assert.Zero(t, countFiles(t, dir))
+}
+
+func TestSortedListingsExpire(t *testing.T) {
+	dir := t.TempDir()
+
+	listings := newSortedListings[uplink.Object](time.Millisecond, 10)
+	defer func() { require.NoError(t, listings.Close()) }()
+
+	require.NoError(t, listings.keep("a", newTestListing(t, dir, "a")))
+
+	require.Eventually(t, func() bool {
+		return countFiles(t, dir) == 0
+	}, 5*time.Second, time.Millisecond)
+	assert.Nil(t, listings.take("a"))
+}
+
+func TestSortedListingsDisabled(t *testing.T) {
+	dir := t.TempDir()
+
+	listings := newSortedListings[uplink.Object](0, 10)
+	require.Nil(t, listings)
+
+	require.NoError(t, listings.keep("a", newTestListing(t, dir, "a")))
+	assert.Nil(t, listings.take("a"))
+	assert.Zero(t, countFiles(t, dir))
+	require.NoError(t, listings.Close())
+}
+
+func TestSortedListingPages(t *testing.T) {
+	layer := &gatewayLayer{compatibilityConfig: S3CompatibilityConfig{MaxKeysLimit: 1000}}
+
+	var keys []string
+	for i := 0; i < 10; i++ {
+		keys = append(keys, fmt.Sprintf("p/%d", i), fmt.Sprintf("p-%d/a", i), fmt.Sprintf("p-%d/b", i))
+	}
+
+	// Listing page by page from a kept listing gives the same results as
+	// listing everything at once.
+	listing := newTestListing(t, t.TempDir(), keys...)
+	defer func() { require.NoError(t, listing.Close()) }()
+
+	var pages []string
+	for {
+		prefixes, objects, token, err := layer.itemsToPrefixesAndObjects(listing.items, "bucket", "p", "/", 3)
+		require.NoError(t, err)
+
+		pages = append(pages, prefixes...)
+		for _, object := range objects {
+			pages = append(pages, object.Name)
+		}
+
+		if token == "" {
+			break
+		}
+		listing.items.Unread()
+	}
+
+	all := newTestListing(t, t.TempDir(), keys...)
+	defer func() { require.NoError(t, all.Close()) }()
+
+	prefixes, objects, token, err := layer.itemsToPrefixesAndObjects(all.items, "bucket", "p", "/", 100)
+	require.NoError(t, err)
+	require.Empty(t, token)
+
+	expected := prefixes
+	for _, object := range objects {
+		expected = append(expected, object.Name)
+	}
+
+	assert.Equal(t, expected, pages)
+	assert.Len(t, pages, 11)
+}
@@ -0,0 +1,585 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bufio"
+	"container/heap"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/uplink"
+)
+
+// sorterError is the error class for sorting items using temporary files.
+var sorterError = errs.Class("sorter")
+
+// objectIterator iterates over objects. It's satisfied by
+// *uplink.ObjectIterator and *sortedItems[uplink.Object].
+type objectIterator interface {
+	Next() bool
+	Item() *uplink.Object
+	Err() error
+}
+
+// sorterFanIn is the maximum number of spilled runs merged at once.
+const sorterFanIn = 64
+
+// sorter sorts items using a bounded amount of memory. Once it holds runSize
+// items, it sorts them and spills them to a temporary file as a sorted run.
+// Iterate merges the spilled runs and the items that are still in memory.
+// At most fanIn runs are merged at once, so runs in excess are first merged
+// into longer ones. Run files are only open while they're read, and Release
+// closes them, e.g., between pages of a listing.
+//
+// Items are spilled using encoding/gob, so only their exported fields survive.
+// Each run is encrypted with its own random key that is only held in memory,
+// so the temporary files don't reveal the listed keys and metadata. Close must
+// always be called to remove temporary files.
+type sorter[T any] struct {
+	dir     string
+	runSize int
+	fanIn   int
+	less    func(a, b *T) bool
+
+	count  int
+	buffer []*T
+	runs   []*sortedRun
+}
+
+// sortedRun is a temporary file holding a sorted run of items encrypted using
+// aead. It reads the file from offset, opening it if it isn't open.
+type sortedRun struct {
+	name   string
+	aead   cipher.AEAD
+	file   *os.File
+	offset int64
+}
+
+// Read implements io.Reader.
+func (run *sortedRun) Read(p []byte) (int, error) {
+	if run.file == nil {
+		file, err := os.Open(run.name)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := file.Seek(run.offset, io.SeekStart); err != nil {
+			return 0, errs.Combine(err, file.Close())
+		}
+		run.file = file
+	}
+
+	n, err := run.file.Read(p)
+	run.offset += int64(n)
+	return n, err
+}
+
+// release closes the file of run until it's read again.
+func (run *sortedRun) release() error {
+	if run.file == nil {
+		return nil
+	}
+	err := run.file.Close()
+	run.file = nil
+	return err
+}
+
+// remove closes and removes the file of run.
+func (run *sortedRun) remove() error {
+	return errs.Combine(run.release(), os.Remove(run.name))
+}
+
+// runSource returns a source of the items of run, read from its start.
+func runSource[T any](run *sortedRun) (*itemSource[T], error) {
+	if err := run.release(); err != nil {
+		return nil, err
+	}
+	run.offset = 0
+
+	dec := gob.NewDecoder(bufio.NewReader(&sealedReader{r: run, aead: run.aead}))
+
+	return &itemSource[T]{
+		next: func() (*T, error) {
+			item := new(T)
+			if err := dec.Decode(item); err != nil {
+				return nil, err
+			}
+			return item, nil
+		},
+	}, nil
+}
+
+// newSorter returns a sorter that orders items using less and spills runs of
+// runSize items to temporary files in dir. If dir is empty, the default
+// directory for temporary files is used. If runSize is not positive, nothing
+// is spilled.
+func newSorter[T any](dir string, runSize int, less func(a, b *T) bool) *sorter[T] {
+	return &sorter[T]{
+		dir:     dir,
+		runSize: runSize,
+		fanIn:   sorterFanIn,
+		less:    less,
+	}
+}
+
+// newObjectSorter returns a sorter that orders objects by key.
+func newObjectSorter(dir string, runSize int) *sorter[uplink.Object] {
+	return newSorter(dir, runSize, func(a, b *uplink.Object) bool {
+		return a.Key < b.Key
+	})
+}
+
+// Add adds item to the sorter.
+func (sorter *sorter[T]) Add(item *T) error {
+	sorter.count++
+	sorter.buffer = append(sorter.buffer, item)
+
+	if sorter.runSize > 0 && len(sorter.buffer) >= sorter.runSize {
+		return sorter.spill()
+	}
+
+	return nil
+}
+
+// Len returns the number of added items.
+func (sorter *sorter[T]) Len() int { return sorter.count }
+
+// Flush spills items that are still in memory, so that the sorter holds none
+// while its items are iterated over. Nothing is spilled if runSize is not
+// positive.
+func (sorter *sorter[T]) Flush() error {
+	if sorter.runSize <= 0 || len(sorter.buffer) == 0 {
+		return nil
+	}
+	return sorter.spill()
+}
+
+// spill writes the sorted buffer to a temporary file and empties the buffer.
+func (sorter *sorter[T]) spill() error {
+	sorter.sortBuffer()
+
+	run, err := sorter.writeRun(func(enc *gob.Encoder) error {
+		for _, item := range sorter.buffer {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return sorterError.Wrap(err)
+	}
+	sorter.runs = append(sorter.runs, run)
+
+	sorter.buffer = sorter.buffer[:0]
+
+	return nil
+}
+
+// writeRun writes a new run using write, which encodes its items in order.
+// The file of the run is removed if writing it fails.
+func (sorter *sorter[T]) writeRun(write func(enc *gob.Encoder) error) (_ *sortedRun, err error) {
+	aead, err := newRunAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(sorter.dir, "gateway-listing-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, f.Close())
+		if err != nil {
+			err = errs.Combine(err, os.Remove(f.Name()))
+		}
+	}()
+
+	w := &sealedWriter{w: f, aead: aead}
+	if err := write(gob.NewEncoder(w)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return &sortedRun{name: f.Name(), aead: aead}, nil
+}
+
+// mergeRuns merges runs in groups of fanIn into longer runs until there are
+// at most fanIn left.
+func (sorter *sorter[T]) mergeRuns() error {
+	for len(sorter.runs) > sorter.fanIn {
+		group := sorter.runs[:sorter.fanIn]
+
+		merged, err := sorter.mergeRun(group)
+		if err != nil {
+			return err
+		}
+
+		var removeErrs errs.Group
+		for _, run := range group {
+			removeErrs.Add(run.remove())
+		}
+
+		sorter.runs = append(append([]*sortedRun(nil), sorter.runs[sorter.fanIn:]...), merged)
+
+		if err := removeErrs.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeRun writes the items of runs to a new run in order.
+func (sorter *sorter[T]) mergeRun(runs []*sortedRun) (_ *sortedRun, err error) {
+	defer func() {
+		for _, run := range runs {
+			err = errs.Combine(err, run.release())
+		}
+	}()
+
+	sources := make([]*itemSource[T], 0, len(runs))
+	for _, run := range runs {
+		source, err := runSource[T](run)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	it, err := newSortedItems(sources, sorter.less)
+	if err != nil {
+		return nil, err
+	}
+
+	return sorter.writeRun(func(enc *gob.Encoder) error {
+		for it.Next() {
+			if err := enc.Encode(it.Item()); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	})
+}
+
+// Iterate returns an iterator over all added items in order. Items must not
+// be added after calling Iterate.
+func (sorter *sorter[T]) Iterate() (*sortedItems[T], error) {
+	sorter.sortBuffer()
+
+	if err := sorter.mergeRuns(); err != nil {
+		return nil, sorterError.Wrap(err)
+	}
+
+	var sources []*itemSource[T]
+
+	if len(sorter.buffer) > 0 {
+		sources = append(sources, &itemSource[T]{
+			next: func() (*T, error) {
+				if len(sorter.buffer) == 0 {
+					return nil, io.EOF
+				}
+				item := sorter.buffer[0]
+				sorter.buffer = sorter.buffer[1:]
+				return item, nil
+			},
+		})
+	}
+
+	for _, run := range sorter.runs {
+		source, err := runSource[T](run)
+		if err != nil {
+			return nil, sorterError.Wrap(err)
+		}
+		sources = append(sources, source)
+	}
+
+	it, err := newSortedItems(sources, sorter.less)
+	return it, sorterError.Wrap(err)
+}
+
+// Release closes the run files that are open. They're opened again when
+// iterating continues.
+func (sorter *sorter[T]) Release() error {
+	var group errs.Group
+	for _, run := range sorter.runs {
+		group.Add(run.release())
+	}
+	return sorterError.Wrap(group.Err())
+}
+
+// Close removes all temporary files created by the sorter.
+func (sorter *sorter[T]) Close() error {
+	var group errs.Group
+	for _, run := range sorter.runs {
+		group.Add(run.remove())
+	}
+	sorter.runs = nil
+	sorter.buffer = nil
+	return sorterError.Wrap(group.Err())
+}
+
+func (sorter *sorter[T]) sortBuffer() {
+	sort.Slice(sorter.buffer, func(i, j int) bool {
+		return sorter.less(sorter.buffer[i], sorter.buffer[j])
+	})
+}
+
+// itemSource is a single sorted run of items.
+type itemSource[T any] struct {
+	next    func() (*T, error)
+	current *T
+}
+
+// advance moves source to its next item. current is nil once source is
+// exhausted.
+func (source *itemSource[T]) advance() error {
+	item, err := source.next()
+	if errors.Is(err, io.EOF) {
+		source.current = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	source.current = item
+	return nil
+}
+
+func removeExhausted[T any](sources []*itemSource[T]) []*itemSource[T] {
+	n := 0
+	for _, source := range sources {
+		if source.current != nil {
+			sources[n] = source
+			n++
+		}
+	}
+	return sources[:n]
+}
+
+// sortedItems merges sorted runs of items. It implements heap.Interface over
+// its sources, ordered by their current item.
+type sortedItems[T any] struct {
+	less    func(a, b *T) bool
+	sources []*itemSource[T]
+	item    *T
+	repeat  bool
+	err     error
+}
+
+// newSortedItems returns sortedItems merging sources ordered by less.
+func newSortedItems[T any](sources []*itemSource[T], less func(a, b *T) bool) (*sortedItems[T], error) {
+	for _, source := range sources {
+		if err := source.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	it := &sortedItems[T]{less: less, sources: removeExhausted(sources)}
+	heap.Init(it)
+
+	return it, nil
+}
+
+// Next prepares the next item for reading with the Item method. It returns
+// false when there are no more items or an error occurred.
+func (it *sortedItems[T]) Next() bool {
+	if it.repeat {
+		it.repeat = false
+		return true
+	}
+
+	if it.err != nil || len(it.sources) == 0 {
+		it.item = nil
+		return false
+	}
+
+	source := it.sources[0]
+	it.item = source.current
+
+	if err := source.advance(); err != nil {
+		it.err = sorterError.Wrap(err)
+		it.item = nil
+		return false
+	}
+
+	if source.current == nil {
+		heap.Pop(it)
+	} else {
+		heap.Fix(it, 0)
+	}
+
+	return true
+}
+
+// Item returns the current item.
+func (it *sortedItems[T]) Item() *T { return it.item }
+
+// Unread makes the next call to Next prepare the current item again, e.g.,
+// when it didn't fit into a page and has to start the next one.
+func (it *sortedItems[T]) Unread() { it.repeat = it.item != nil }
+
+// Err returns the error, if any, that occurred during merging.
+func (it *sortedItems[T]) Err() error { return it.err }
+
+func (it *sortedItems[T]) Len() int { return len(it.sources) }
+
+func (it *sortedItems[T]) Less(i, j int) bool {
+	return it.less(it.sources[i].current, it.sources[j].current)
+}
+
+func (it *sortedItems[T]) Swap(i, j int) { it.sources[i], it.sources[j] = it.sources[j], it.sources[i] }
+
+func (it *sortedItems[T]) Push(x any) { it.sources = append(it.sources, x.(*itemSource[T])) }
+
+func (it *sortedItems[T]) Pop() any {
+	last := it.sources[len(it.sources)-1]
+	it.sources = it.sources[:len(it.sources)-1]
+	return last
+}
+
+// sealedChunkSize is the size of the chunks spilled runs are encrypted in.
+const sealedChunkSize = 64 * 1024
+
+// sealedHeaderSize is the size of the header preceding each encrypted chunk:
+// whether it's the last chunk and its length.
+const sealedHeaderSize = 5
+
+// newRunAEAD returns AES-GCM with a new random key.
+func newRunAEAD() (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealedNonce returns the nonce of the chunk with the given number. Every run
+// has its own key, so chunk numbers never repeat for the same key.
+func sealedNonce(aead cipher.AEAD, chunk uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], chunk)
+	return nonce
+}
+
+// sealedWriter encrypts data written to it in chunks using aead. The header of
+// each chunk is authenticated along with it, and its number is its nonce, so
+// chunks can't be modified, reordered or cut off unnoticed.
+type sealedWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	buffer []byte
+	chunk  uint64
+}
+
+// Write implements io.Writer.
+func (w *sealedWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		free := min(sealedChunkSize-len(w.buffer), len(p))
+		w.buffer = append(w.buffer, p[:free]...)
+		p = p[free:]
+
+		if len(w.buffer) == sealedChunkSize {
+			if err := w.seal(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close writes the remaining data as the last chunk. It doesn't close the
+// underlying writer.
+func (w *sealedWriter) Close() error {
+	return w.seal(true)
+}
+
+func (w *sealedWriter) seal(last bool) error {
+	header := make([]byte, sealedHeaderSize)
+	if last {
+		header[0] = 1
+	}
+	binary.BigEndian.PutUint32(header[1:], uint32(len(w.buffer)+w.aead.Overhead()))
+
+	sealed := w.aead.Seal(nil, sealedNonce(w.aead, w.chunk), w.buffer, header)
+	w.chunk++
+	w.buffer = w.buffer[:0]
+
+	if _, err := w.w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+// sealedReader decrypts data written by sealedWriter.
+type sealedReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	chunk uint64
+	plain []byte
+	last  bool
+}
+
+// Read implements io.Reader.
+func (r *sealedReader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.last {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *sealedReader) open() error {
+	header := make([]byte, sealedHeaderSize)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if errors.Is(err, io.EOF) {
+			// The last chunk is missing.
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > sealedChunkSize+uint32(r.aead.Overhead()) {
+		return sorterError.New("corrupted run")
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.r, sealed); err != nil {
+		return err
+	}
+
+	plain, err := r.aead.Open(sealed[:0], sealedNonce(r.aead, r.chunk), sealed, header)
+	if err != nil {
+		return sorterError.New("corrupted run")
+	}
+
+	r.chunk++
+	r.plain = plain
+	r.last = header[0] == 1
+	return nil
+}
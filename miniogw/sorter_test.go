@@ -0,0 +1,175 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/uplink"
+)
+
+func TestObjectSorter(t *testing.T) {
+	for _, runSize := range []int{0, 1, 3, 10, 1000} {
+		t.Run(fmt.Sprint(runSize), func(t *testing.T) {
+			dir := t.TempDir()
+
+			sorter := newObjectSorter(dir, runSize)
+
+			created := time.Now().Truncate(time.Second)
+
+			var expected []string
+			for i := 0; i < 100; i++ {
+				expected = append(expected, fmt.Sprintf("key%03d", i))
+			}
+
+			for _, i := range rand.Perm(len(expected)) {
+				require.NoError(t, sorter.Add(&uplink.Object{
+					Key: expected[i],
+					System: uplink.SystemMetadata{
+						Created:       created,
+						ContentLength: int64(i),
+					},
+					Custom: uplink.CustomMetadata{"s3:etag": expected[i]},
+				}))
+			}
+
+			it, err := sorter.Iterate()
+			require.NoError(t, err)
+
+			var actual []string
+			for it.Next() {
+				item := it.Item()
+				actual = append(actual, item.Key)
+				assert.Equal(t, item.Key, item.Custom["s3:etag"])
+				assert.True(t, created.Equal(item.System.Created))
+			}
+			require.NoError(t, it.Err())
+			assert.Equal(t, expected, actual)
+
+			require.NoError(t, sorter.Close())
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestObjectSorterEmpty(t *testing.T) {
+	sorter := newObjectSorter(t.TempDir(), 10)
+
+	it, err := sorter.Iterate()
+	require.NoError(t, err)
+	assert.False(t, it.Next())
+	require.NoError(t, it.Err())
+
+	require.NoError(t, sorter.Close())
+}
+
+func TestObjectSorterEncryptsRuns(t *testing.T) {
+	dir := t.TempDir()
+
+	sorter := newObjectSorter(dir, 10)
+	defer func() { require.NoError(t, sorter.Close()) }()
+
+	for i := 0; i < 25; i++ {
+		require.NoError(t, sorter.Add(&uplink.Object{Key: fmt.Sprintf("secret%03d", i)}))
+	}
+	require.NoError(t, sorter.Flush())
+	assert.Equal(t, 25, sorter.Len())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+	}
+
+	it, err := sorter.Iterate()
+	require.NoError(t, err)
+	require.True(t, it.Next())
+	assert.Equal(t, "secret000", it.Item().Key)
+
+	// The current item can be read again.
+	it.Unread()
+	require.True(t, it.Next())
+	assert.Equal(t, "secret000", it.Item().Key)
+	require.True(t, it.Next())
+	assert.Equal(t, "secret001", it.Item().Key)
+
+	// Runs that have been tampered with aren't read.
+	path := filepath.Join(dir, entries[0].Name())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 1
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	it, err = sorter.Iterate()
+	if err == nil {
+		for it.Next() {
+		}
+		err = it.Err()
+	}
+	require.Error(t, err)
+}
+
+func TestObjectSorterMergesRuns(t *testing.T) {
+	dir := t.TempDir()
+
+	sorter := newObjectSorter(dir, 2)
+	sorter.fanIn = 3
+	defer func() { require.NoError(t, sorter.Close()) }()
+
+	var expected []string
+	for i := 0; i < 41; i++ {
+		expected = append(expected, fmt.Sprintf("key%03d", i))
+	}
+	for _, i := range rand.Perm(len(expected)) {
+		require.NoError(t, sorter.Add(&uplink.Object{Key: expected[i]}))
+	}
+	assert.Equal(t, 20, countFiles(t, dir))
+
+	it, err := sorter.Iterate()
+	require.NoError(t, err)
+
+	// Runs in excess are merged, and the merged ones removed.
+	assert.LessOrEqual(t, len(sorter.runs), 3)
+	assert.Equal(t, len(sorter.runs), countFiles(t, dir))
+
+	openFiles := func() int {
+		var open int
+		for _, run := range sorter.runs {
+			if run.file != nil {
+				open++
+			}
+		}
+		return open
+	}
+
+	var actual []string
+	for it.Next() {
+		actual = append(actual, it.Item().Key)
+
+		if len(actual) == 10 {
+			assert.Equal(t, len(sorter.runs), openFiles())
+
+			// Released runs are opened again where they were left.
+			require.NoError(t, sorter.Release())
+			assert.Zero(t, openFiles())
+		}
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,508 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	miniogo "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+	"github.com/minio/minio-go/v7/pkg/signer"
+
+	minio "storj.io/minio/cmd"
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/minio/pkg/auth"
+)
+
+// Payload hashes of aws-chunked uploads with trailing checksums.
+const (
+	unsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	signedPayloadTrailer   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+)
+
+const (
+	trailerHeader              = "x-amz-trailer"
+	decodedContentLengthHeader = "x-amz-decoded-content-length"
+	trailerSignatureHeader     = "x-amz-trailer-signature"
+	awsChunkedEncoding         = "aws-chunked"
+
+	signV4Algorithm   = "AWS4-HMAC-SHA256"
+	signV4DateFormat  = "20060102T150405Z"
+	emptySHA256       = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	maxSignV4TimeSkew = 15 * time.Minute
+
+	// maxChunkSize is the largest chunk of aws-chunked uploads, as chunks
+	// are buffered to verify their signatures.
+	maxChunkSize = 16 << 20
+)
+
+// hopHeaders are the headers that aren't passed on to minio, so they can't
+// be signed.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// TrailerServer is implemented by gateways that accept uploads with trailing
+// checksums, which minio can't verify.
+type TrailerServer interface {
+	TrailerHandler(next http.Handler) http.Handler
+}
+
+var _ TrailerServer = (*singleTenantGateway)(nil)
+
+// trailerError is an error of reading an aws-chunked upload reported to the
+// client as code.
+type trailerError struct {
+	code minio.APIErrorCode
+}
+
+func (err trailerError) Error() string {
+	return minio.GetAPIError(err.code).Description
+}
+
+// trailerAPIError returns the error reported to the client when reading an
+// aws-chunked upload failed with err and whether there's one.
+func trailerAPIError(err error) (minio.APIError, bool) {
+	var chunkErr trailerError
+	if errors.As(err, &chunkErr) {
+		return minio.GetAPIError(chunkErr.code), true
+	}
+	var errResponse miniogo.ErrorResponse
+	if errors.As(err, &errResponse) {
+		return minio.APIError{
+			Code:           errResponse.Code,
+			Description:    errResponse.Message,
+			HTTPStatusCode: errResponse.StatusCode,
+		}, true
+	}
+	return minio.APIError{}, false
+}
+
+// TrailerHandler is a middleware that accepts aws-chunked uploads with
+// trailing checksums signed with the credentials of the gateway. The
+// signature, chunks and trailing checksum are verified here, and minio gets
+// the decoded upload signed again as an unsigned payload asking for the
+// checksum to be computed. Other requests are passed on.
+func (g *singleTenantGateway) TrailerHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := r.Header.Get(xhttp.AmzContentSha256)
+		if payload != unsignedPayloadTrailer && payload != signedPayloadTrailer {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		creds := g.creds.Load()
+		if creds == nil {
+			minio.WriteErrorResponse(r.Context(), w, minio.GetAPIError(minio.ErrServerNotInitialized), r.URL, false)
+			return
+		}
+
+		out, err := decodeTrailerRequest(r, *creds, time.Now())
+		if err == nil && out.ContentLength == 0 {
+			// Empty bodies aren't read by the proxy, but the trailer still
+			// has to be verified.
+			_, err = io.Copy(io.Discard, out.Body)
+			out.Body = http.NoBody
+		}
+		if err != nil {
+			apiErr, ok := trailerAPIError(err)
+			if !ok {
+				apiErr = minio.GetAPIError(minio.ErrInternalError)
+			}
+			minio.WriteErrorResponse(r.Context(), w, apiErr, r.URL, false)
+			return
+		}
+
+		next.ServeHTTP(w, out)
+	})
+}
+
+// decodeTrailerRequest verifies the signature of r, an aws-chunked upload
+// with a trailing checksum signed with creds, and returns the request with
+// the decoded upload signed with creds again.
+func decodeTrailerRequest(r *http.Request, creds auth.Credentials, now time.Time) (*http.Request, error) {
+	seed, err := verifySignV4(r, creds, now)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, ok := trailerChecksumAlgorithm(r.Header.Get(trailerHeader))
+	if !ok {
+		return nil, ErrInvalidChecksum
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get(decodedContentLengthHeader), 10, 64)
+	if err != nil || length < 0 {
+		return nil, trailerError{minio.ErrMissingContentLength}
+	}
+
+	reader := &chunkedReader{
+		closer:    r.Body,
+		body:      bufio.NewReader(r.Body),
+		trailer:   checksumHeaderPrefix + strings.ToLower(algorithm),
+		checksum:  &uploadChecksum{algorithm: algorithm, hash: newChecksumHash(algorithm)},
+		remaining: length,
+	}
+	if r.Header.Get(xhttp.AmzContentSha256) == signedPayloadTrailer {
+		reader.signing = seed
+	}
+
+	out := r.Clone(r.Context())
+	out.Body = reader
+	out.ContentLength = length
+
+	var encodings []string
+	for _, encoding := range strings.Split(out.Header.Get("Content-Encoding"), ",") {
+		if encoding = strings.TrimSpace(encoding); encoding != "" && encoding != awsChunkedEncoding {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) > 0 {
+		out.Header.Set("Content-Encoding", strings.Join(encodings, ","))
+	} else {
+		out.Header.Del("Content-Encoding")
+	}
+
+	for _, header := range append([]string{xhttp.Authorization, xhttp.ContentLength, trailerHeader, decodedContentLengthHeader}, hopHeaders...) {
+		out.Header.Del(header)
+	}
+	out.Header.Set(xhttp.AmzContentSha256, "UNSIGNED-PAYLOAD")
+	// The checksum of the data minio gets is computed the same way the
+	// trailing one is verified.
+	out.Header.Set(checksumAlgorithmHeader, algorithm)
+
+	return signer.SignV4(*out, creds.AccessKey, creds.SecretKey, "", seed.region), nil
+}
+
+// trailerChecksumAlgorithm returns the checksum algorithm of the trailing
+// checksum header named by trailer.
+func trailerChecksumAlgorithm(trailer string) (string, bool) {
+	for _, algorithm := range checksumAlgorithms {
+		if strings.EqualFold(strings.TrimSpace(trailer), checksumHeaderPrefix+strings.ToLower(algorithm)) {
+			return algorithm, true
+		}
+	}
+	return "", false
+}
+
+// chunkSigning is the state of verifying signatures of chunks of an
+// aws-chunked upload.
+type chunkSigning struct {
+	key    []byte
+	date   string
+	scope  string
+	region string
+	// signature is the signature of the previous chunk, or of the request
+	// for the first one.
+	signature string
+}
+
+// sign returns the signature of the string to sign made of lines.
+func (signing *chunkSigning) sign(lines ...string) string {
+	return hex.EncodeToString(sumHMAC(signing.key, []byte(strings.Join(lines, "\n"))))
+}
+
+// verify checks that signature is the one of the next chunk, which has
+// algorithm and hash in its string to sign.
+func (signing *chunkSigning) verify(algorithm, hash, signature string) error {
+	expected := signing.sign(algorithm, signing.date, signing.scope, signing.signature, hash)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return trailerError{minio.ErrSignatureDoesNotMatch}
+	}
+	signing.signature = signature
+	return nil
+}
+
+// verifySignV4 checks the signature version 4 of r in its Authorization
+// header against creds and returns the state of verifying its chunks.
+func verifySignV4(r *http.Request, creds auth.Credentials, now time.Time) (*chunkSigning, error) {
+	authorization := strings.TrimSpace(r.Header.Get(xhttp.Authorization))
+	if !strings.HasPrefix(authorization, signV4Algorithm+" ") {
+		return nil, trailerError{minio.ErrSignatureVersionNotSupported}
+	}
+
+	var credential, signedHeaders, signature string
+	for _, field := range strings.Split(strings.TrimPrefix(authorization, signV4Algorithm), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	if credential == "" || signedHeaders == "" || signature == "" {
+		return nil, trailerError{minio.ErrMissingFields}
+	}
+
+	scope := strings.Split(credential, "/")
+	if len(scope) != 5 || scope[3] != "s3" || scope[4] != "aws4_request" {
+		return nil, trailerError{minio.ErrCredMalformed}
+	}
+	if !hmac.Equal([]byte(scope[0]), []byte(creds.AccessKey)) {
+		return nil, trailerError{minio.ErrInvalidAccessKeyID}
+	}
+
+	date := r.Header.Get(xhttp.AmzDate)
+	t, err := time.Parse(signV4DateFormat, date)
+	if err != nil {
+		return nil, trailerError{minio.ErrMalformedDate}
+	}
+	if skew := now.Sub(t); skew > maxSignV4TimeSkew || skew < -maxSignV4TimeSkew {
+		return nil, trailerError{minio.ErrRequestTimeTooSkewed}
+	}
+
+	headers := strings.Split(signedHeaders, ";")
+	sort.Strings(headers)
+
+	var canonicalHeaders strings.Builder
+	for _, header := range headers {
+		var values []string
+		switch header {
+		case "host":
+			values = []string{r.Host}
+		case "expect":
+			// The HTTP server removes the Expect header.
+			values = []string{"100-continue"}
+		case "content-length":
+			values = []string{strconv.FormatInt(r.ContentLength, 10)}
+		case "transfer-encoding":
+			values = r.TransferEncoding
+		default:
+			var ok bool
+			if values, ok = r.Header[http.CanonicalHeaderKey(header)]; !ok {
+				return nil, trailerError{minio.ErrUnsignedHeaders}
+			}
+		}
+		trimmed := make([]string, 0, len(values))
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		s3utils.EncodePath(r.URL.Path),
+		strings.ReplaceAll(r.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		strings.Join(headers, ";"),
+		r.Header.Get(xhttp.AmzContentSha256),
+	}, "\n")
+
+	signing := &chunkSigning{
+		key:    sumHMAC(sumHMAC(sumHMAC(sumHMAC([]byte("AWS4"+creds.SecretKey), []byte(scope[1])), []byte(scope[2])), []byte("s3")), []byte("aws4_request")),
+		date:   date,
+		scope:  strings.Join(scope[1:], "/"),
+		region: scope[2],
+	}
+	expected := signing.sign(signV4Algorithm, date, signing.scope, sha256Hex([]byte(canonicalRequest)))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, trailerError{minio.ErrSignatureDoesNotMatch}
+	}
+	signing.signature = signature
+
+	return signing, nil
+}
+
+func sumHMAC(key, data []byte) []byte {
+	hash := hmac.New(sha256.New, key)
+	_, _ = hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkedReader decodes an aws-chunked upload, verifying the signatures of
+// its chunks if it's signed and its trailing checksum.
+type chunkedReader struct {
+	closer io.Closer
+	body   *bufio.Reader
+	// signing is nil if the chunks aren't signed.
+	signing *chunkSigning
+	// trailer is the name of the trailing checksum header.
+	trailer  string
+	checksum *uploadChecksum
+	// remaining is the number of decoded bytes still to be read.
+	remaining int64
+
+	buffer []byte
+	chunk  []byte
+	err    error
+}
+
+// Read reads decoded data of the upload. The chunk is kept until it's read
+// even if nextChunk already returned io.EOF after it.
+func (reader *chunkedReader) Read(p []byte) (int, error) {
+	for len(reader.chunk) == 0 && reader.err == nil {
+		reader.err = reader.nextChunk()
+	}
+	if len(reader.chunk) == 0 {
+		return 0, reader.err
+	}
+
+	n := copy(p, reader.chunk)
+	reader.chunk = reader.chunk[n:]
+	return n, nil
+}
+
+// readLine returns the next line of the upload without its line ending.
+func (reader *chunkedReader) readLine() (string, error) {
+	line, err := reader.body.ReadSlice('\n')
+	switch {
+	case errors.Is(err, io.EOF) && len(line) == 0:
+		return "", io.EOF
+	case errors.Is(err, io.EOF):
+		return "", trailerError{minio.ErrIncompleteBody}
+	case err != nil:
+		return "", trailerError{minio.ErrInvalidRequest}
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"), nil
+}
+
+// nextChunk reads the next chunk of the upload or its trailer, returning
+// io.EOF once the upload is verified.
+func (reader *chunkedReader) nextChunk() error {
+	line, err := reader.readLine()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return trailerError{minio.ErrIncompleteBody}
+		}
+		return err
+	}
+
+	sizeField, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(sizeField, 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize {
+		return trailerError{minio.ErrInvalidRequest}
+	}
+	if size > reader.remaining {
+		return trailerError{minio.ErrIncompleteBody}
+	}
+
+	signature, signed := strings.CutPrefix(extension, "chunk-signature=")
+	if signed != (reader.signing != nil) {
+		return trailerError{minio.ErrSignatureDoesNotMatch}
+	}
+
+	if size == 0 {
+		if signed {
+			if err := reader.signing.verify(signV4Algorithm+"-PAYLOAD", emptySHA256+"\n"+emptySHA256, signature); err != nil {
+				return err
+			}
+		}
+		return reader.readTrailer()
+	}
+
+	if int64(cap(reader.buffer)) < size {
+		reader.buffer = make([]byte, size)
+	}
+	data := reader.buffer[:size]
+	if _, err := io.ReadFull(reader.body, data); err != nil {
+		return trailerError{minio.ErrIncompleteBody}
+	}
+	if line, err := reader.readLine(); err != nil || line != "" {
+		return trailerError{minio.ErrIncompleteBody}
+	}
+
+	if signed {
+		if err := reader.signing.verify(signV4Algorithm+"-PAYLOAD", emptySHA256+"\n"+sha256Hex(data), signature); err != nil {
+			return err
+		}
+	}
+
+	_, _ = reader.checksum.Write(data)
+	reader.remaining -= size
+
+	// The last chunk is only passed on once the upload is verified, so that
+	// uploads failing verification are never complete and aren't committed.
+	var last error
+	if reader.remaining == 0 {
+		if last = reader.nextChunk(); !errors.Is(last, io.EOF) {
+			return last
+		}
+	}
+
+	reader.chunk = data
+	return last
+}
+
+// readTrailer reads the trailer of the upload and verifies the checksum of
+// the upload against the trailing one.
+func (reader *chunkedReader) readTrailer() error {
+	if reader.remaining != 0 {
+		return trailerError{minio.ErrIncompleteBody}
+	}
+
+	var value, signature string
+	for {
+		line, err := reader.readLine()
+		if errors.Is(err, io.EOF) || (err == nil && line == "") {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name, field, ok := strings.Cut(line, ":")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case reader.trailer:
+			value = strings.TrimSpace(field)
+		case trailerSignatureHeader:
+			signature = strings.TrimSpace(field)
+		default:
+			ok = false
+		}
+		if !ok {
+			return trailerError{minio.ErrInvalidRequest}
+		}
+	}
+
+	if reader.signing != nil {
+		hash := sha256Hex([]byte(reader.trailer + ":" + value + "\n"))
+		if err := reader.signing.verify(signV4Algorithm+"-TRAILER", hash, signature); err != nil {
+			return err
+		}
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(expected) != reader.checksum.hash.Size() {
+		return ErrInvalidChecksum
+	}
+	reader.checksum.expected = expected
+	if _, err := reader.checksum.sum(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+// Close closes the body of the upload.
+func (reader *chunkedReader) Close() error {
+	return reader.closer.Close()
+}
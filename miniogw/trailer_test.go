@@ -0,0 +1,159 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	xhttp "storj.io/minio/cmd/http"
+	"storj.io/minio/pkg/auth"
+)
+
+func TestTrailerHandler(t *testing.T) {
+	creds := auth.Credentials{AccessKey: testAccessKey, SecretKey: "secret"}
+
+	gateway := &singleTenantGateway{}
+	gateway.creds.Store(&creds)
+
+	url, m := startTestServer(t, gateway)
+
+	// upload sends an aws-chunked upload of chunks with checksum in its
+	// trailer, signing its chunks if signed. corrupt changes the request
+	// after it's signed.
+	upload := func(secret string, chunks []string, checksum string, signed bool, corrupt func(body string) string) *http.Response {
+		var length int
+		for _, chunk := range chunks {
+			length += len(chunk)
+		}
+
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodPut, url+"/bucket/key?partNumber=1&uploadId=upload", nil)
+		require.NoError(t, err)
+		r.Header.Set("Content-Encoding", awsChunkedEncoding)
+		r.Header.Set(trailerHeader, "x-amz-checksum-crc32")
+		r.Header.Set(decodedContentLengthHeader, strconv.Itoa(length))
+		r.Header.Set(xhttp.AmzContentSha256, unsignedPayloadTrailer)
+		if signed {
+			r.Header.Set(xhttp.AmzContentSha256, signedPayloadTrailer)
+		}
+		r = signer.SignV4(*r, testAccessKey, secret, "", "us-east-1")
+
+		var signing *chunkSigning
+		if signed {
+			signing, err = verifySignV4(r, auth.Credentials{AccessKey: testAccessKey, SecretKey: secret}, time.Now())
+			require.NoError(t, err)
+		}
+		extension := func(data string) string {
+			if signing == nil {
+				return ""
+			}
+			signing.signature = signing.sign(signV4Algorithm+"-PAYLOAD", signing.date, signing.scope, signing.signature, emptySHA256+"\n"+sha256Hex([]byte(data)))
+			return ";chunk-signature=" + signing.signature
+		}
+
+		var body strings.Builder
+		for _, chunk := range append(chunks, "") {
+			_, _ = fmt.Fprintf(&body, "%x%s\r\n", len(chunk), extension(chunk))
+			if chunk != "" {
+				body.WriteString(chunk + "\r\n")
+			}
+		}
+		body.WriteString("x-amz-checksum-crc32:" + checksum + "\r\n")
+		if signing != nil {
+			trailer := sha256Hex([]byte("x-amz-checksum-crc32:" + checksum + "\n"))
+			body.WriteString(trailerSignatureHeader + ":" + signing.sign(signV4Algorithm+"-TRAILER", signing.date, signing.scope, signing.signature, trailer) + "\r\n")
+		}
+		body.WriteString("\r\n")
+
+		data := body.String()
+		if corrupt != nil {
+			data = corrupt(data)
+		}
+		r.Body = io.NopCloser(strings.NewReader(data))
+		r.ContentLength = int64(len(data))
+
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	errorCode := func(resp *http.Response) string {
+		var errResp struct {
+			Code string `xml:"Code"`
+		}
+		require.NoError(t, xml.NewDecoder(resp.Body).Decode(&errResp))
+		return errResp.Code
+	}
+
+	for _, signed := range []bool{false, true} {
+		resp := upload("secret", []string{"hello", " world"}, "DUoRhQ==", signed, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// minio gets the decoded upload signed again, asking for the
+		// checksum to be computed.
+		r := m.last()
+		require.NotNil(t, r)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "hello world", string(body))
+		assert.EqualValues(t, 11, r.ContentLength)
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+		assert.Empty(t, r.Header.Get(trailerHeader))
+		assert.Equal(t, "UNSIGNED-PAYLOAD", r.Header.Get(xhttp.AmzContentSha256))
+		_, err = verifySignV4(r, creds, time.Now())
+		require.NoError(t, err)
+
+		checksums, ok := getChecksumRequest(r.Context())
+		require.True(t, ok)
+		assert.Equal(t, checksumRequest{algorithm: checksumCRC32}, checksums)
+
+		resp = upload("secret", []string{"hello", " world!"}, "DUoRhQ==", signed, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "BadDigest", errorCode(resp))
+		// minio never gets all of it, so it isn't committed.
+		assert.Same(t, r, m.last())
+	}
+
+	before := m.last()
+
+	resp := upload("other", []string{"hello world"}, "DUoRhQ==", false, nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "SignatureDoesNotMatch", errorCode(resp))
+
+	// Chunks of signed uploads can't be changed.
+	resp = upload("secret", []string{"hello world"}, "DUoRhQ==", true, func(body string) string {
+		return strings.Replace(body, "hello", "HELLO", 1)
+	})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "SignatureDoesNotMatch", errorCode(resp))
+
+	resp = upload("secret", []string{"hello world"}, "DUoRhQ==", false, func(body string) string {
+		return strings.Replace(body, "hello world", "hello", 1)
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "IncompleteBody", errorCode(resp))
+
+	// Trailers of empty uploads are verified too.
+	resp = upload("secret", nil, "DUoRhQ==", false, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "BadDigest", errorCode(resp))
+
+	// Uploads failing verification never reach the object layer.
+	assert.Same(t, before, m.last())
+
+	resp = upload("secret", nil, "AAAAAA==", false, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
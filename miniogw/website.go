@@ -0,0 +1,327 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	miniogo "github.com/minio/minio-go/v7"
+
+	minio "storj.io/minio/cmd"
+)
+
+// BucketWebsiteLayer is implemented by object layers that support bucket
+// website configurations.
+type BucketWebsiteLayer interface {
+	GetBucketWebsite(ctx context.Context, bucket string) (*BucketWebsite, error)
+	SetBucketWebsite(ctx context.Context, bucket string, config *BucketWebsite) error
+	DeleteBucketWebsite(ctx context.Context, bucket string) error
+}
+
+var (
+	_ BucketWebsiteLayer = (*gatewayLayer)(nil)
+	_ BucketWebsiteLayer = (*singleTenancyLayer)(nil)
+)
+
+// ErrMalformedWebsite is a custom error for website configurations that are
+// invalid.
+var ErrMalformedWebsite = miniogo.ErrorResponse{
+	Code:       "MalformedXML",
+	StatusCode: http.StatusBadRequest,
+	Message:    "The website configuration you provided is not well-formed or did not validate.",
+}
+
+// ErrNoSuchWebsiteConfiguration is a custom error for buckets without a
+// website configuration.
+var ErrNoSuchWebsiteConfiguration = miniogo.ErrorResponse{
+	Code:       "NoSuchWebsiteConfiguration",
+	StatusCode: http.StatusNotFound,
+	Message:    "The specified bucket does not have a website configuration",
+}
+
+// ErrInvalidRedirectLocation is a custom error for website redirect
+// locations of objects that are neither absolute URLs nor absolute paths.
+var ErrInvalidRedirectLocation = miniogo.ErrorResponse{
+	Code:       "InvalidRedirectLocation",
+	StatusCode: http.StatusBadRequest,
+	Message:    "The website redirect location must have a prefix of 'http://' or 'https://' or '/'.",
+}
+
+// storeWebsiteKey is the metadata store key of the website configuration of
+// a bucket.
+var storeWebsiteKey = []byte("website")
+
+// websiteRedirectLocationHeader is the header, and the key of the object
+// metadata, with the location requests for an object on the website
+// endpoint are redirected to.
+const websiteRedirectLocationHeader = "X-Amz-Website-Redirect-Location"
+
+// maxWebsiteRoutingRules is the maximum number of routing rules of a website
+// configuration.
+const maxWebsiteRoutingRules = 50
+
+// BucketWebsite is the website configuration of a bucket.
+type BucketWebsite struct {
+	XMLName               xml.Name                      `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration"`
+	IndexDocument         *WebsiteIndexDocument         `xml:"IndexDocument,omitempty"`
+	ErrorDocument         *WebsiteErrorDocument         `xml:"ErrorDocument,omitempty"`
+	RedirectAllRequestsTo *WebsiteRedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	RoutingRules          []WebsiteRoutingRule          `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// WebsiteIndexDocument is the object served for requests to a directory,
+// i.e., a key that's empty or ends with "/", with Suffix appended to it.
+type WebsiteIndexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+// WebsiteErrorDocument is the object served for requests that fail with a
+// 4xx error.
+type WebsiteErrorDocument struct {
+	Key string `xml:"Key"`
+}
+
+// WebsiteRedirectAllRequestsTo is the host all requests to a website are
+// redirected to.
+type WebsiteRedirectAllRequestsTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// WebsiteRoutingRule redirects requests meeting Condition.
+type WebsiteRoutingRule struct {
+	Condition *WebsiteRoutingCondition `xml:"Condition,omitempty"`
+	Redirect  WebsiteRedirect          `xml:"Redirect"`
+}
+
+// WebsiteRoutingCondition is the condition of a routing rule. Requests meet
+// it if their key starts with KeyPrefixEquals and, if set, they fail with
+// HTTPErrorCodeReturnedEquals.
+type WebsiteRoutingCondition struct {
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+	HTTPErrorCodeReturnedEquals int    `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+// WebsiteRedirect is where a routing rule redirects requests to. Empty
+// fields keep the corresponding part of the request.
+type WebsiteRedirect struct {
+	HostName             string `xml:"HostName,omitempty"`
+	HTTPRedirectCode     int    `xml:"HttpRedirectCode,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+}
+
+// ParseBucketWebsite parses and validates a website configuration.
+func ParseBucketWebsite(r io.Reader) (*BucketWebsite, error) {
+	var config struct {
+		XMLName               xml.Name
+		IndexDocument         *WebsiteIndexDocument         `xml:"IndexDocument"`
+		ErrorDocument         *WebsiteErrorDocument         `xml:"ErrorDocument"`
+		RedirectAllRequestsTo *WebsiteRedirectAllRequestsTo `xml:"RedirectAllRequestsTo"`
+		RoutingRules          []WebsiteRoutingRule          `xml:"RoutingRules>RoutingRule"`
+	}
+	if err := xml.NewDecoder(r).Decode(&config); err != nil || config.XMLName.Local != "WebsiteConfiguration" {
+		return nil, ErrMalformedWebsite
+	}
+
+	website := &BucketWebsite{
+		IndexDocument:         config.IndexDocument,
+		ErrorDocument:         config.ErrorDocument,
+		RedirectAllRequestsTo: config.RedirectAllRequestsTo,
+		RoutingRules:          config.RoutingRules,
+	}
+	if err := website.Validate(); err != nil {
+		return nil, err
+	}
+
+	return website, nil
+}
+
+// Validate returns an error if config is invalid.
+func (config *BucketWebsite) Validate() error {
+	if redirect := config.RedirectAllRequestsTo; redirect != nil {
+		if redirect.HostName == "" || !validWebsiteProtocol(redirect.Protocol) {
+			return ErrMalformedWebsite
+		}
+		// Redirecting all requests excludes serving any.
+		if config.IndexDocument != nil || config.ErrorDocument != nil || len(config.RoutingRules) > 0 {
+			return ErrMalformedWebsite
+		}
+		return nil
+	}
+
+	if config.IndexDocument == nil || config.IndexDocument.Suffix == "" || strings.Contains(config.IndexDocument.Suffix, "/") {
+		return ErrMalformedWebsite
+	}
+	if config.ErrorDocument != nil && config.ErrorDocument.Key == "" {
+		return ErrMalformedWebsite
+	}
+
+	if len(config.RoutingRules) > maxWebsiteRoutingRules {
+		return ErrMalformedWebsite
+	}
+	for _, rule := range config.RoutingRules {
+		if condition := rule.Condition; condition != nil {
+			if condition.KeyPrefixEquals == "" && condition.HTTPErrorCodeReturnedEquals == 0 {
+				return ErrMalformedWebsite
+			}
+			if code := condition.HTTPErrorCodeReturnedEquals; code != 0 && (code < 400 || code > 599) {
+				return ErrMalformedWebsite
+			}
+		}
+
+		redirect := rule.Redirect
+		if redirect == (WebsiteRedirect{}) || !validWebsiteProtocol(redirect.Protocol) {
+			return ErrMalformedWebsite
+		}
+		if redirect.ReplaceKeyPrefixWith != "" && redirect.ReplaceKeyWith != "" {
+			return ErrMalformedWebsite
+		}
+		if code := redirect.HTTPRedirectCode; code != 0 && (code < 300 || code > 399) {
+			return ErrMalformedWebsite
+		}
+	}
+
+	return nil
+}
+
+func validWebsiteProtocol(protocol string) bool {
+	return protocol == "" || protocol == "http" || protocol == "https"
+}
+
+// routingRule returns the first routing rule of config that redirects a
+// request for key, which failed with statusCode unless it's 0.
+func (config *BucketWebsite) routingRule(key string, statusCode int) (WebsiteRoutingRule, bool) {
+	for _, rule := range config.RoutingRules {
+		condition := rule.Condition
+		if condition == nil {
+			// Rules without a condition redirect all requests before
+			// they're served.
+			if statusCode == 0 {
+				return rule, true
+			}
+			continue
+		}
+		if condition.HTTPErrorCodeReturnedEquals != statusCode {
+			continue
+		}
+		if strings.HasPrefix(key, condition.KeyPrefixEquals) {
+			return rule, true
+		}
+	}
+	return WebsiteRoutingRule{}, false
+}
+
+type websiteRedirectLocationKey struct{}
+
+// WithWebsiteRedirectLocation injects the website redirect location of the
+// object uploaded or copied by the request with header into ctx.
+func WithWebsiteRedirectLocation(ctx context.Context, header http.Header) context.Context {
+	location := header.Get(websiteRedirectLocationHeader)
+	if location == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, websiteRedirectLocationKey{}, location)
+}
+
+// WebsiteRedirectLocationHandler is a middleware that passes the website
+// redirect location of uploads and copies down to the object layer. minio
+// drops the header.
+func WebsiteRedirectLocationHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut || r.Method == http.MethodPost {
+			r = r.WithContext(WithWebsiteRedirectLocation(r.Context(), r.Header))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setWebsiteRedirectLocation adds the website redirect location in ctx, if
+// any, to metadata of an object.
+func setWebsiteRedirectLocation(ctx context.Context, metadata map[string]string) error {
+	location, ok := ctx.Value(websiteRedirectLocationKey{}).(string)
+	if !ok {
+		return nil
+	}
+
+	if !strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return ErrInvalidRedirectLocation
+	}
+
+	metadata[websiteRedirectLocationHeader] = location
+	return nil
+}
+
+// GetBucketWebsite returns the website configuration of bucket.
+func (layer *gatewayLayer) GetBucketWebsite(ctx context.Context, bucketName string) (_ *BucketWebsite, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return nil, err
+	}
+
+	if layer.metadataStore == nil {
+		return nil, ErrNoSuchWebsiteConfiguration
+	}
+
+	value, found, err := layer.metadataStore.get(storeBucketConfig, storeBucket(ctx, bucketName), storeWebsiteKey)
+	if err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+	if !found {
+		return nil, ErrNoSuchWebsiteConfiguration
+	}
+
+	var config BucketWebsite
+	if err := xml.Unmarshal(value, &config); err != nil {
+		return nil, ConvertError(err, bucketName, "")
+	}
+
+	return &config, nil
+}
+
+// SetBucketWebsite sets the website configuration of bucket. The
+// configuration is kept in the metadata store.
+func (layer *gatewayLayer) SetBucketWebsite(ctx context.Context, bucketName string, config *BucketWebsite) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if layer.metadataStore == nil {
+		return minio.NotImplemented{Message: "PutBucketWebsite"}
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	value, err := xml.Marshal(config)
+	if err != nil {
+		return ConvertError(err, bucketName, "")
+	}
+
+	return ConvertError(layer.metadataStore.put(storeBucketConfig, storeBucket(ctx, bucketName), storeWebsiteKey, value), bucketName, "")
+}
+
+// DeleteBucketWebsite removes the website configuration of bucket.
+func (layer *gatewayLayer) DeleteBucketWebsite(ctx context.Context, bucketName string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := layer.checkBucketConfigRequest(ctx, bucketName); err != nil {
+		return err
+	}
+
+	if layer.metadataStore == nil {
+		return nil
+	}
+
+	return ConvertError(layer.metadataStore.delete(storeBucketConfig, storeBucket(ctx, bucketName), storeWebsiteKey), bucketName, "")
+}
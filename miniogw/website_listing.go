@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"storj.io/common/memory"
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+// websiteListingPageSize is the maximum number of entries of a directory
+// listing page.
+const websiteListingPageSize = 1000
+
+// websiteListingPageParam is the query parameter with the continuation
+// token of the listing page to serve.
+const websiteListingPageParam = "page"
+
+var websiteListingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Path}}</title>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Last modified</th></tr>
+{{- if .Parent}}
+<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{- end}}
+{{- range .Entries}}
+<tr><td><a href="{{.Href}}">{{.Name}}</a></td><td>{{.Size}}</td><td>{{.Modified}}</td></tr>
+{{- end}}
+</table>
+{{- if .Next}}
+<p><a href="{{.Next}}">Next page</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// websiteListing is the data of a directory listing page.
+type websiteListing struct {
+	Path    string
+	Parent  bool
+	Entries []websiteListingEntry
+	// Next is the URL of the next page, if any.
+	Next template.URL
+}
+
+// websiteListingEntry is an object or a subdirectory of a directory listing.
+type websiteListingEntry struct {
+	Name     string
+	Href     string
+	Size     string
+	Modified string
+}
+
+// publiclyListable returns whether the policy of bucket allows anyone to
+// list the objects with prefix.
+func (l *singleTenancyLayer) publiclyListable(ctx context.Context, bucket, prefix string) bool {
+	bucketPolicy, err := l.GetBucketPolicy(ctx, bucket)
+	if err != nil {
+		return false
+	}
+
+	return bucketPolicy.IsAllowed(policy.Args{
+		Action:          policy.ListBucketAction,
+		BucketName:      bucket,
+		ConditionValues: map[string][]string{"prefix": {prefix}},
+	})
+}
+
+// serveWebsiteListing serves the directory listing of the objects of the
+// website with prefix. If the listing can't be served or the directory is
+// empty, it returns an error without writing a response.
+func (l *singleTenancyLayer) serveWebsiteListing(w http.ResponseWriter, r *http.Request, site websiteRequest, prefix string) error {
+	ctx := r.Context()
+
+	if !l.publiclyListable(ctx, site.bucket, prefix) {
+		return minio.PrefixAccessDenied{Bucket: site.bucket, Object: prefix}
+	}
+
+	token := r.URL.Query().Get(websiteListingPageParam)
+
+	// ListObjectsV2 lists with listObjectsGeneral and hands out tokens bound
+	// to the bucket, prefix and delimiter, so pages can't list other
+	// directories.
+	result, err := l.ListObjectsV2(ctx, site.bucket, prefix, token, "/", websiteListingPageSize, false, "")
+	if err != nil {
+		return err
+	}
+
+	root := prefix == site.prefix
+	if token == "" && !root && len(result.Objects) == 0 && len(result.Prefixes) == 0 {
+		return minio.ObjectNotFound{Bucket: site.bucket, Object: prefix}
+	}
+
+	listing := websiteListing{
+		Path:   site.path(prefix),
+		Parent: !root,
+	}
+	for _, dir := range result.Prefixes {
+		name := strings.TrimPrefix(dir, prefix)
+		listing.Entries = append(listing.Entries, websiteListingEntry{
+			Name: name,
+			Href: (&url.URL{Path: name}).String(),
+			Size: "-",
+		})
+	}
+	for _, object := range result.Objects {
+		name := strings.TrimPrefix(object.Name, prefix)
+		// The directory itself may exist as an empty object.
+		if name == "" {
+			continue
+		}
+		entry := websiteListingEntry{
+			Name: name,
+			Href: (&url.URL{Path: name}).String(),
+			Size: memory.Size(object.Size).String(),
+		}
+		if !object.ModTime.IsZero() {
+			entry.Modified = object.ModTime.UTC().Format(time.RFC3339)
+		}
+		listing.Entries = append(listing.Entries, entry)
+	}
+	if result.IsTruncated && result.NextContinuationToken != "" {
+		listing.Next = template.URL("?" + url.Values{websiteListingPageParam: {result.NextContinuationToken}}.Encode())
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	// The response has started, so errors can only be logged.
+	if err := websiteListingTemplate.Execute(w, listing); err != nil {
+		_ = l.log(err)
+	}
+
+	return nil
+}
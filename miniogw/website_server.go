@@ -0,0 +1,416 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+)
+
+// WebsiteServer is implemented by gateways that serve static websites.
+type WebsiteServer interface {
+	WebsiteHandler(next http.Handler) http.Handler
+}
+
+var _ WebsiteServer = (*singleTenantGateway)(nil)
+
+// errWebsiteMethodNotAllowed is the error of requests to websites using
+// methods other than GET and HEAD.
+var errWebsiteMethodNotAllowed = minio.APIError{
+	Code:           "MethodNotAllowed",
+	Description:    "The specified method is not allowed against this resource.",
+	HTTPStatusCode: http.StatusMethodNotAllowed,
+}
+
+// websiteRequest is a request for key of the website of bucket.
+type websiteRequest struct {
+	bucket string
+	key    string
+	// base is the path the website is served at, ending with "/", and
+	// prefix is the prefix of the keys it's served from.
+	base   string
+	prefix string
+	// listing is whether directories without an index document are listed.
+	listing bool
+}
+
+// path returns the path key is served at.
+func (site websiteRequest) path(key string) string {
+	return site.base + strings.TrimPrefix(key, site.prefix)
+}
+
+// websiteDomain maps a domain to the website of a bucket served from its
+// prefix.
+type websiteDomain struct {
+	// pattern is a domain or, if it starts with "*.", a wildcard matching
+	// its subdomains.
+	pattern string
+	bucket  string
+	prefix  string
+}
+
+// parseWebsiteDomain parses a domain mapping of WebsiteConfig.Domains.
+func parseWebsiteDomain(entry string) (websiteDomain, error) {
+	pattern, target, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	if !ok {
+		return websiteDomain{}, errs.New("missing bucket")
+	}
+
+	pattern = strings.ToLower(pattern)
+	if pattern == "" || strings.Contains(strings.TrimPrefix(pattern, "*."), "*") {
+		return websiteDomain{}, errs.New("invalid domain %q", pattern)
+	}
+
+	bucket, prefix, _ := strings.Cut(target, "/")
+	if bucket == "" {
+		return websiteDomain{}, errs.New("missing bucket")
+	}
+
+	return websiteDomain{pattern: pattern, bucket: bucket, prefix: prefix}, nil
+}
+
+// sortWebsiteDomains sorts domains so that the first one matching a host
+// is the most specific one: domains before wildcards and longer wildcards
+// before shorter ones.
+func sortWebsiteDomains(domains []websiteDomain) []websiteDomain {
+	sort.SliceStable(domains, func(i, k int) bool {
+		wildcardI, wildcardK := strings.HasPrefix(domains[i].pattern, "*."), strings.HasPrefix(domains[k].pattern, "*.")
+		if wildcardI != wildcardK {
+			return wildcardK
+		}
+		return len(domains[i].pattern) > len(domains[k].pattern)
+	})
+	return domains
+}
+
+// matches returns whether host is domain or matches its wildcard.
+func (domain websiteDomain) matches(host string) bool {
+	host = strings.ToLower(host)
+	if suffix, ok := strings.CutPrefix(domain.pattern, "*"); ok {
+		return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+	}
+	return host == domain.pattern
+}
+
+// websiteRequest returns the website request r is, if it's made to the
+// website endpoint or a website domain.
+func (g *singleTenantGateway) websiteRequest(r *http.Request) (websiteRequest, bool) {
+	host := requestHost(r)
+
+	if g.website.Endpoint != "" && strings.EqualFold(host, g.website.Endpoint) {
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		return websiteRequest{
+			bucket:  bucket,
+			key:     key,
+			base:    "/" + bucket + "/",
+			listing: g.listsWebsite(bucket),
+		}, true
+	}
+
+	for _, domain := range g.domains {
+		if domain.matches(host) {
+			return websiteRequest{
+				bucket:  domain.bucket,
+				key:     domain.prefix + strings.TrimPrefix(r.URL.Path, "/"),
+				base:    "/",
+				prefix:  domain.prefix,
+				listing: g.listsWebsite(domain.bucket),
+			}, true
+		}
+	}
+
+	return websiteRequest{}, false
+}
+
+// listsWebsite returns whether the website of bucket lists directories
+// without an index document.
+func (g *singleTenantGateway) listsWebsite(bucket string) bool {
+	for _, b := range g.website.Listings {
+		if b == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// WebsiteHandler is a middleware that serves GET and HEAD requests to the
+// website endpoint and website domains from buckets with a website
+// configuration. Requests to other hosts are passed on. Websites are served
+// to anyone, so objects are only served if the policy of their bucket allows
+// anyone to read them.
+func (g *singleTenantGateway) WebsiteHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		site, ok := g.websiteRequest(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		layer := g.layer.Load()
+		if layer == nil {
+			writeWebsiteError(w, r, minio.ToAPIError(r.Context(), minio.BackendDown{}))
+			return
+		}
+
+		// Relative links of the root of a website only work if its path
+		// ends with a slash, e.g., /<bucket>/ on the website endpoint.
+		if site.bucket != "" && r.URL.Path+"/" == site.base {
+			http.Redirect(w, r, site.base, http.StatusFound)
+			return
+		}
+
+		layer.serveWebsite(w, r, site)
+	})
+}
+
+// requestHost returns the host r is made to without the port.
+func requestHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return r.Host
+	}
+	return host
+}
+
+// serveWebsite serves site according to the website configuration of its
+// bucket.
+func (l *singleTenancyLayer) serveWebsite(w http.ResponseWriter, r *http.Request, site websiteRequest) {
+	ctx := r.Context()
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeWebsiteError(w, r, errWebsiteMethodNotAllowed)
+		return
+	}
+
+	if site.bucket == "" {
+		writeWebsiteError(w, r, minio.ToAPIError(ctx, minio.BucketNotFound{}))
+		return
+	}
+
+	config, err := l.GetBucketWebsite(ctx, site.bucket)
+	if err != nil {
+		writeWebsiteError(w, r, minio.ToAPIError(ctx, err))
+		return
+	}
+
+	if redirect := config.RedirectAllRequestsTo; redirect != nil {
+		location := url.URL{Scheme: websiteScheme(r, redirect.Protocol), Host: redirect.HostName, Path: "/" + strings.TrimPrefix(site.key, site.prefix)}
+		http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	if rule, ok := config.routingRule(site.key, 0); ok {
+		redirectWebsite(w, r, site, rule)
+		return
+	}
+
+	key := site.key
+	if key == "" || strings.HasSuffix(key, "/") {
+		key += config.IndexDocument.Suffix
+	}
+
+	err = l.serveWebsiteObject(w, r, site, key, http.StatusOK)
+	if err == nil {
+		return
+	}
+
+	if errors.As(err, &minio.ObjectNotFound{}) {
+		if key != site.key && site.listing {
+			if err = l.serveWebsiteListing(w, r, site, site.key); err == nil {
+				return
+			}
+		}
+
+		// Directories requested without the trailing slash are redirected
+		// to, so relative links of their pages work.
+		if key == site.key && l.websiteDirectoryExists(ctx, site, key+"/", config.IndexDocument.Suffix) {
+			http.Redirect(w, r, site.path(key+"/"), http.StatusFound)
+			return
+		}
+	}
+
+	apiErr := minio.ToAPIError(ctx, err)
+
+	if rule, ok := config.routingRule(site.key, apiErr.HTTPStatusCode); ok {
+		redirectWebsite(w, r, site, rule)
+		return
+	}
+
+	if config.ErrorDocument != nil && apiErr.HTTPStatusCode >= 400 && apiErr.HTTPStatusCode < 500 {
+		if l.serveWebsiteObject(w, r, site, config.ErrorDocument.Key, apiErr.HTTPStatusCode) == nil {
+			return
+		}
+	}
+
+	writeWebsiteError(w, r, apiErr)
+}
+
+// websiteDirectoryExists returns whether dir of the website has an index
+// document or, if directories are listed, any objects.
+func (l *singleTenancyLayer) websiteDirectoryExists(ctx context.Context, site websiteRequest, dir, suffix string) bool {
+	if _, err := l.GetObjectInfo(ctx, site.bucket, dir+suffix, minio.ObjectOptions{}); err == nil {
+		return true
+	}
+
+	if !site.listing {
+		return false
+	}
+
+	result, err := l.ListObjectsV2(ctx, site.bucket, dir, "", "/", 1, false, "")
+	return err == nil && (len(result.Objects) > 0 || len(result.Prefixes) > 0)
+}
+
+// serveWebsiteObject serves the object at key of the website with
+// statusCode. If the object can't be served, it returns an error without
+// writing a response.
+func (l *singleTenancyLayer) serveWebsiteObject(w http.ResponseWriter, r *http.Request, site websiteRequest, key string, statusCode int) error {
+	ctx := r.Context()
+
+	if !l.publiclyReadable(ctx, site.bucket, key) {
+		return minio.PrefixAccessDenied{Bucket: site.bucket, Object: key}
+	}
+
+	// The gateway doesn't lock objects, so no lock type is needed.
+	reader, err := l.GetObjectNInfo(ctx, site.bucket, key, nil, r.Header, 0, minio.ObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	info := reader.ObjInfo
+
+	if location := info.UserDefined[websiteRedirectLocationHeader]; location != "" && statusCode == http.StatusOK {
+		if strings.HasPrefix(location, "/") {
+			location = site.base + strings.TrimPrefix(location, "/")
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return nil
+	}
+
+	header := w.Header()
+	for _, name := range []string{"Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language"} {
+		if value := info.UserDefined[strings.ToLower(name)]; value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	if info.ETag != "" {
+		header.Set("ETag", `"`+info.ETag+`"`)
+	}
+	if !info.ModTime.IsZero() {
+		header.Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	w.WriteHeader(statusCode)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	// The response has started, so errors can only be logged.
+	if _, err := io.Copy(w, reader); err != nil {
+		_ = l.log(err)
+	}
+
+	return nil
+}
+
+// publiclyReadable returns whether the policy of bucket allows anyone to
+// read the object at key.
+func (l *singleTenancyLayer) publiclyReadable(ctx context.Context, bucket, key string) bool {
+	bucketPolicy, err := l.GetBucketPolicy(ctx, bucket)
+	if err != nil {
+		return false
+	}
+
+	return bucketPolicy.IsAllowed(policy.Args{
+		Action:          policy.GetObjectAction,
+		BucketName:      bucket,
+		ObjectName:      key,
+		ConditionValues: map[string][]string{},
+	})
+}
+
+// redirectWebsite redirects a request for site as rule says.
+func redirectWebsite(w http.ResponseWriter, r *http.Request, site websiteRequest, rule WebsiteRoutingRule) {
+	redirect := rule.Redirect
+
+	key := site.key
+	switch {
+	case redirect.ReplaceKeyWith != "":
+		key = redirect.ReplaceKeyWith
+	case redirect.ReplaceKeyPrefixWith != "":
+		var prefix string
+		if rule.Condition != nil {
+			prefix = rule.Condition.KeyPrefixEquals
+		}
+		key = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+	}
+
+	location := url.URL{Path: site.path(key)}
+	if redirect.HostName != "" {
+		location = url.URL{Scheme: websiteScheme(r, redirect.Protocol), Host: redirect.HostName, Path: "/" + key}
+	} else if redirect.Protocol != "" {
+		location.Scheme, location.Host = redirect.Protocol, r.Host
+	}
+
+	code := redirect.HTTPRedirectCode
+	if code == 0 {
+		code = http.StatusMovedPermanently
+	}
+
+	http.Redirect(w, r, location.String(), code)
+}
+
+// websiteScheme returns protocol or, if it's empty, the scheme of r.
+func websiteScheme(r *http.Request, protocol string) string {
+	if protocol != "" {
+		return protocol
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// writeWebsiteError writes apiErr as an HTML page, as browsers are the
+// clients of websites.
+func writeWebsiteError(w http.ResponseWriter, r *http.Request, apiErr minio.APIError) {
+	status := fmt.Sprintf("%d %s", apiErr.HTTPStatusCode, http.StatusText(apiErr.HTTPStatusCode))
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(apiErr.HTTPStatusCode)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "<html>\n<head><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<ul>\n<li>Code: %s</li>\n<li>Message: %s</li>\n</ul>\n</body>\n</html>\n",
+		status, status, html.EscapeString(apiErr.Code), html.EscapeString(apiErr.Description))
+}
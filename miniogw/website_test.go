@@ -0,0 +1,435 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package miniogw
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	minio "storj.io/minio/cmd"
+	"storj.io/minio/pkg/bucket/policy"
+	"storj.io/uplink"
+)
+
+func TestParseBucketWebsite(t *testing.T) {
+	config, err := ParseBucketWebsite(strings.NewReader(`<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+		<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+		<ErrorDocument><Key>error.html</Key></ErrorDocument>
+		<RoutingRules>
+			<RoutingRule>
+				<Condition><KeyPrefixEquals>docs/</KeyPrefixEquals></Condition>
+				<Redirect><ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith></Redirect>
+			</RoutingRule>
+			<RoutingRule>
+				<Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition>
+				<Redirect><HostName>example.com</HostName><HttpRedirectCode>302</HttpRedirectCode></Redirect>
+			</RoutingRule>
+		</RoutingRules>
+	</WebsiteConfiguration>`))
+	require.NoError(t, err)
+	assert.Equal(t, "index.html", config.IndexDocument.Suffix)
+	assert.Equal(t, "error.html", config.ErrorDocument.Key)
+	require.Len(t, config.RoutingRules, 2)
+	assert.Equal(t, 404, config.RoutingRules[1].Condition.HTTPErrorCodeReturnedEquals)
+
+	rule, ok := config.routingRule("docs/a.html", 0)
+	require.True(t, ok)
+	assert.Equal(t, "documents/", rule.Redirect.ReplaceKeyPrefixWith)
+	_, ok = config.routingRule("a.html", 0)
+	assert.False(t, ok)
+	rule, ok = config.routingRule("a.html", 404)
+	require.True(t, ok)
+	assert.Equal(t, "example.com", rule.Redirect.HostName)
+
+	_, err = ParseBucketWebsite(strings.NewReader(`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo></WebsiteConfiguration>`))
+	require.NoError(t, err)
+
+	for _, body := range []string{
+		`not xml`,
+		`<WebsiteConfiguration></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><IndexDocument><Suffix>a/index.html</Suffix></IndexDocument></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><RedirectAllRequestsTo><HostName>example.com</HostName><Protocol>ftp</Protocol></RedirectAllRequestsTo></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RedirectAllRequestsTo><HostName>example.com</HostName></RedirectAllRequestsTo></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Redirect></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`,
+		`<WebsiteConfiguration><IndexDocument><Suffix>index.html</Suffix></IndexDocument><RoutingRules><RoutingRule><Redirect><HttpRedirectCode>200</HttpRedirectCode></Redirect></RoutingRule></RoutingRules></WebsiteConfiguration>`,
+	} {
+		_, err := ParseBucketWebsite(strings.NewReader(body))
+		assert.ErrorIs(t, err, ErrMalformedWebsite, body)
+	}
+}
+
+func TestSetWebsiteRedirectLocation(t *testing.T) {
+	metadata := map[string]string{}
+	require.NoError(t, setWebsiteRedirectLocation(context.Background(), metadata))
+	assert.Empty(t, metadata)
+
+	ctx := WithWebsiteRedirectLocation(context.Background(), http.Header{websiteRedirectLocationHeader: {"/new.html"}})
+	require.NoError(t, setWebsiteRedirectLocation(ctx, metadata))
+	assert.Equal(t, "/new.html", metadata[websiteRedirectLocationHeader])
+
+	ctx = WithWebsiteRedirectLocation(context.Background(), http.Header{websiteRedirectLocationHeader: {"new.html"}})
+	require.ErrorIs(t, setWebsiteRedirectLocation(ctx, metadata), ErrInvalidRedirectLocation)
+}
+
+// websiteTestLayer is an object layer serving objects and website
+// configurations from memory.
+type websiteTestLayer struct {
+	minio.ObjectLayer
+
+	websites map[string]*BucketWebsite
+	objects  map[string]minio.ObjectInfo
+	data     map[string]string
+}
+
+func newWebsiteTestLayer(websites map[string]*BucketWebsite, data map[string]string) *websiteTestLayer {
+	layer := &websiteTestLayer{
+		websites: websites,
+		objects:  make(map[string]minio.ObjectInfo),
+		data:     data,
+	}
+	for key, data := range data {
+		bucket, object, _ := strings.Cut(key, "/")
+		layer.objects[key] = minio.ObjectInfo{Bucket: bucket, Name: object, Size: int64(len(data)), ETag: "etag", UserDefined: map[string]string{}}
+	}
+	return layer
+}
+
+func (layer *websiteTestLayer) GetBucketWebsite(ctx context.Context, bucket string) (*BucketWebsite, error) {
+	config, ok := layer.websites[bucket]
+	if !ok {
+		return nil, ErrNoSuchWebsiteConfiguration
+	}
+	return config, nil
+}
+
+func (layer *websiteTestLayer) SetBucketWebsite(ctx context.Context, bucket string, config *BucketWebsite) error {
+	layer.websites[bucket] = config
+	return nil
+}
+
+func (layer *websiteTestLayer) DeleteBucketWebsite(ctx context.Context, bucket string) error {
+	delete(layer.websites, bucket)
+	return nil
+}
+
+func (layer *websiteTestLayer) GetBucketPolicy(ctx context.Context, bucket string) (*policy.Policy, error) {
+	return nil, minio.BucketPolicyNotFound{Bucket: bucket}
+}
+
+func (layer *websiteTestLayer) GetObjectInfo(ctx context.Context, bucket, object string, opts minio.ObjectOptions) (minio.ObjectInfo, error) {
+	info, ok := layer.objects[bucket+"/"+object]
+	if !ok {
+		return minio.ObjectInfo{}, minio.ObjectNotFound{Bucket: bucket, Object: object}
+	}
+	return info, nil
+}
+
+func (layer *websiteTestLayer) GetObjectNInfo(ctx context.Context, bucket, object string, rs *minio.HTTPRangeSpec, h http.Header, lockType minio.LockType, opts minio.ObjectOptions) (*minio.GetObjectReader, error) {
+	info, err := layer.GetObjectInfo(ctx, bucket, object, opts)
+	if err != nil {
+		return nil, err
+	}
+	return minio.NewGetObjectReaderFromReader(bytes.NewReader([]byte(layer.data[bucket+"/"+object])), info, opts)
+}
+
+func (layer *websiteTestLayer) ListObjectsV2(ctx context.Context, bucket, prefix, continuationToken, delimiter string, maxKeys int, fetchOwner bool, startAfter string) (result minio.ListObjectsV2Info, err error) {
+	var keys []string
+	for key := range layer.objects {
+		if object, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(object, prefix) {
+			keys = append(keys, object)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key <= continuationToken {
+			continue
+		}
+		if len(result.Objects)+len(result.Prefixes) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
+		if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+			dir := key[:len(prefix)+i+1]
+			if len(result.Prefixes) == 0 || result.Prefixes[len(result.Prefixes)-1] != dir {
+				result.Prefixes = append(result.Prefixes, dir)
+			}
+			continue
+		}
+		result.Objects = append(result.Objects, layer.objects[bucket+"/"+key])
+		result.NextContinuationToken = key
+	}
+
+	return result, nil
+}
+
+func TestWebsiteHandler(t *testing.T) {
+	layer := newWebsiteTestLayer(map[string]*BucketWebsite{
+		"site": {
+			IndexDocument: &WebsiteIndexDocument{Suffix: "index.html"},
+			ErrorDocument: &WebsiteErrorDocument{Key: "error.html"},
+			RoutingRules: []WebsiteRoutingRule{{
+				Condition: &WebsiteRoutingCondition{KeyPrefixEquals: "blog/"},
+				Redirect:  WebsiteRedirect{ReplaceKeyPrefixWith: "posts/"},
+			}},
+		},
+		"private": {IndexDocument: &WebsiteIndexDocument{Suffix: "index.html"}},
+		"moved":   {RedirectAllRequestsTo: &WebsiteRedirectAllRequestsTo{HostName: "example.com", Protocol: "https"}},
+	}, map[string]string{
+		"site/index.html":      "home",
+		"site/docs/index.html": "docs",
+		"site/error.html":      "oops",
+		"site/old.html":        "",
+		"private/index.html":   "secret",
+	})
+	layer.objects["site/old.html"].UserDefined[websiteRedirectLocationHeader] = "/new.html"
+
+	gateway := &singleTenantGateway{
+		website: WebsiteConfig{Endpoint: "website.test"},
+		domains: []websiteDomain{
+			{pattern: "docs.example.com", bucket: "site", prefix: "docs/"},
+			{pattern: "*.example.com", bucket: "site"},
+		},
+	}
+	gateway.layer.Store(&singleTenancyLayer{
+		logger: zap.NewNop(),
+		layer:  layer,
+		public: newPublicAccess(PublicAccessConfig{Buckets: []string{"site", "moved"}}),
+	})
+
+	var called bool
+	handler := gateway.WebsiteHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	request := func(method, target string) *httptest.ResponseRecorder {
+		called = false
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	w := request(http.MethodGet, "http://website.test/site/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "home", w.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `"etag"`, w.Header().Get("ETag"))
+
+	w = request(http.MethodHead, "http://website.test:7777/site/docs/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "4", w.Header().Get("Content-Length"))
+	assert.Empty(t, w.Body.String())
+
+	for target, location := range map[string]string{
+		"http://website.test/site":          "/site/",
+		"http://website.test/site/docs":     "/site/docs/",
+		"http://website.test/site/old.html": "/site/new.html",
+		"http://website.test/site/blog/a":   "/site/posts/a",
+		"http://website.test/moved/a/b":     "https://example.com/a/b",
+	} {
+		w = request(http.MethodGet, target)
+		assert.Contains(t, []int{http.StatusFound, http.StatusMovedPermanently}, w.Code, target)
+		assert.Equal(t, location, w.Header().Get("Location"), target)
+	}
+
+	w = request(http.MethodGet, "http://website.test/site/missing.html")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "oops", w.Body.String())
+
+	// Buckets that aren't public aren't served.
+	w = request(http.MethodGet, "http://website.test/private/")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = request(http.MethodGet, "http://website.test/other/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "NoSuchWebsiteConfiguration")
+
+	w = request(http.MethodPut, "http://website.test/site/index.html")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	// Website domains serve buckets, or prefixes of them, from their root.
+	w = request(http.MethodGet, "http://www.example.com/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "home", w.Body.String())
+
+	w = request(http.MethodGet, "http://docs.example.com/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "docs", w.Body.String())
+
+	w = request(http.MethodGet, "http://www.example.com/docs")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/docs/", w.Header().Get("Location"))
+
+	w = request(http.MethodGet, "http://www.example.com/blog/a")
+	assert.Equal(t, "/posts/a", w.Header().Get("Location"))
+
+	// Requests to other hosts are passed on.
+	request(http.MethodGet, "http://s3.test/site/index.html")
+	assert.True(t, called)
+	request(http.MethodGet, "http://example.com/index.html")
+	assert.True(t, called)
+}
+
+func TestWebsiteDomains(t *testing.T) {
+	var domains []websiteDomain
+	for _, entry := range []string{"*.example.com=www-prod", "docs.example.com=docs-site", "*.docs.example.com=docs-site/preview/"} {
+		domain, err := parseWebsiteDomain(entry)
+		require.NoError(t, err)
+		domains = append(domains, domain)
+	}
+	domains = sortWebsiteDomains(domains)
+
+	match := func(host string) websiteDomain {
+		for _, domain := range domains {
+			if domain.matches(host) {
+				return domain
+			}
+		}
+		return websiteDomain{}
+	}
+
+	assert.Equal(t, websiteDomain{pattern: "docs.example.com", bucket: "docs-site"}, match("Docs.Example.com"))
+	assert.Equal(t, websiteDomain{pattern: "*.docs.example.com", bucket: "docs-site", prefix: "preview/"}, match("v2.docs.example.com"))
+	assert.Equal(t, "www-prod", match("www.example.com").bucket)
+	assert.Equal(t, websiteDomain{}, match("example.com"))
+
+	for _, entry := range []string{"docs.example.com", "=docs-site", "docs.example.com=", "a.*.example.com=docs-site"} {
+		_, err := parseWebsiteDomain(entry)
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestWebsiteListing(t *testing.T) {
+	data := map[string]string{
+		"files/a.txt":     "a",
+		"files/dir/b.txt": "bb",
+		"closed/c.txt":    "c",
+	}
+	for i := 0; i <= websiteListingPageSize; i++ {
+		data[fmt.Sprintf("files/many/%04d", i)] = ""
+	}
+	layer := newWebsiteTestLayer(map[string]*BucketWebsite{
+		"files":  {IndexDocument: &WebsiteIndexDocument{Suffix: "index.html"}},
+		"closed": {IndexDocument: &WebsiteIndexDocument{Suffix: "index.html"}},
+	}, data)
+
+	gateway := &singleTenantGateway{website: WebsiteConfig{Endpoint: "website.test", Listings: []string{"files", "closed"}}}
+	gateway.layer.Store(&singleTenancyLayer{
+		logger: zap.NewNop(),
+		layer:  layer,
+		public: newPublicAccess(PublicAccessConfig{Buckets: []string{"files", "closed/c.txt"}, Listing: true}),
+	})
+	handler := gateway.WebsiteHandler(http.NotFoundHandler())
+
+	request := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := request("http://website.test/files/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Index of /files/")
+	assert.Contains(t, w.Body.String(), `<a href="a.txt">a.txt</a></td><td>1 B</td>`)
+	assert.Contains(t, w.Body.String(), `<a href="dir/">dir/</a>`)
+	assert.NotContains(t, w.Body.String(), `href="../"`)
+
+	w = request("http://website.test/files/dir")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/files/dir/", w.Header().Get("Location"))
+
+	w = request("http://website.test/files/dir/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<a href="b.txt">b.txt</a>`)
+	assert.Contains(t, w.Body.String(), `href="../"`)
+
+	w = request("http://website.test/files/missing/")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Large directories are listed in pages.
+	w = request("http://website.test/files/many/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), fmt.Sprintf("%04d", websiteListingPageSize))
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`<a href="?page=many%%2F%04d">Next page</a>`, websiteListingPageSize-1))
+
+	w = request(fmt.Sprintf("http://website.test/files/many/?page=many%%2F%04d", websiteListingPageSize-1))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf("%04d", websiteListingPageSize))
+	assert.NotContains(t, w.Body.String(), "Next page")
+
+	// Listings need the bucket policy to allow them.
+	w = request("http://website.test/closed/")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "c.txt")
+}
+
+func TestBucketWebsiteAPI(t *testing.T) {
+	layer := newWebsiteTestLayer(map[string]*BucketWebsite{}, map[string]string{"site/index.html": "home"})
+
+	public := PublicAccessConfig{Buckets: []string{"site"}}
+	gateway := NewSingleTenantGateway(zap.NewNop(), nil, uplink.Config{}, nil, public, WebsiteConfig{Endpoint: "website.test"}).(*singleTenantGateway)
+	gateway.layer.Store(&singleTenancyLayer{logger: zap.NewNop(), layer: layer, public: newPublicAccess(public)})
+	// minio-go has no website API, so requests are signed directly.
+	_, url := startGatewayAPITestServer(t, gateway)
+
+	website := func() *http.Response {
+		r, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url+"/site/", nil)
+		require.NoError(t, err)
+		r.Host = "website.test"
+		resp, err := http.DefaultClient.Do(r)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	resp := signedRequest(t, http.MethodGet, url+"/site?website", nil, "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, website().StatusCode)
+
+	resp = signedRequest(t, http.MethodPut, url+"/site?website", nil, `<WebsiteConfiguration>
+		<IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+	</WebsiteConfiguration>`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = signedRequest(t, http.MethodGet, url+"/site?website", nil, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	config, err := ParseBucketWebsite(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "index.html", config.IndexDocument.Suffix)
+
+	// The configuration set through the API is served by the website
+	// endpoint.
+	resp = website()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "home", string(body))
+
+	resp = signedRequest(t, http.MethodPut, url+"/site?website", nil, `<WebsiteConfiguration></WebsiteConfiguration>`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = signedRequest(t, http.MethodDelete, url+"/site?website", nil, "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, website().StatusCode)
+
+	// The API needs a valid signature.
+	r, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, url+"/site?website", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(r)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}